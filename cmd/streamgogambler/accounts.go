@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"streamgogambler/internal/adapters/config"
+	"streamgogambler/internal/adapters/dialect"
+	"streamgogambler/internal/adapters/locale"
+	"streamgogambler/internal/adapters/logging"
+	"streamgogambler/internal/adapters/storage"
+	"streamgogambler/internal/adapters/twitch"
+	"streamgogambler/internal/application"
+	"streamgogambler/internal/domain/autoresponse"
+	"streamgogambler/internal/domain/i18n"
+	"streamgogambler/internal/domain/parsing"
+)
+
+// newAccountFactory resolves the dialects, reply catalogs and auto-responses
+// of an account's channels up front and returns the factory building its chat
+// connection and one bot per channel. Files of additional accounts get the
+// account name as suffix.
+func newAccountFactory(ctx context.Context, store *config.EnvStore, envPath string, primary bool, logger *logging.Logger, transcript *storage.TranscriptStore) (application.AccountFactory, error) {
+	cfg := store.GetConfig()
+
+	trustedUsersPath := storage.ScopedFilePath(storage.ResolveTrustedUsersPath(envPath), cfg.Username, primary)
+	ledgerPath := storage.ScopedFilePath(storage.ResolveLedgerPath(envPath), cfg.Username, primary)
+	autoResponsesPath := storage.ScopedFilePath(storage.ResolveAutoResponsesPath(envPath), cfg.Username, primary)
+
+	dialects := make([]*parsing.Dialect, 0, len(cfg.Channels))
+	catalogs := make([]*i18n.Catalog, 0, len(cfg.Channels))
+	autoResponseStores := make([]*storage.AutoResponsesStore, 0, len(cfg.Channels))
+	autoResponses := make([][]autoresponse.Rule, 0, len(cfg.Channels))
+	for i, channel := range cfg.Channels {
+		channelCfg := store.ForChannel(channel).GetConfig()
+		bossDialect, err := dialect.Resolve(channelCfg.BossBotDialect, filepath.Dir(envPath))
+		if err != nil {
+			return nil, fmt.Errorf("boss bot dialect for %s in #%s: %w", cfg.Username, channel, err)
+		}
+		logger.Infof(ctx, "Using boss bot dialect %s for %s in #%s", bossDialect.Name, cfg.Username, channel)
+		dialects = append(dialects, bossDialect)
+
+		catalog, err := locale.Resolve(channelCfg.Language, channelCfg.MessagesFile, filepath.Dir(envPath))
+		if err != nil {
+			return nil, fmt.Errorf("bot language for %s in #%s: %w", cfg.Username, channel, err)
+		}
+		catalogs = append(catalogs, catalog)
+
+		autoResponseStore := newAutoResponsesStore(storage.ScopedFilePath(autoResponsesPath, channel, i == 0), store)
+		rules, err := autoResponseStore.Load()
+		if err != nil {
+			return nil, fmt.Errorf("auto-responses for %s in #%s: %w", cfg.Username, channel, err)
+		}
+		autoResponseStores = append(autoResponseStores, autoResponseStore)
+		autoResponses = append(autoResponses, rules)
+	}
+
+	return func() *application.Supervisor {
+		chatClient := twitch.NewClient(
+			cfg.Username,
+			store.GetOAuth(),
+			twitch.WithBucketSize(cfg.SayBucketSize),
+			twitch.WithRefillMs(cfg.SayRefillMs),
+			twitch.WithLogger(logger),
+			twitch.WithTokenValidator(twitch.NewTokenValidator(cfg.AuthURL)),
+		)
+		chatMux := twitch.NewMux(chatClient)
+
+		bots := make([]*application.BotService, 0, len(cfg.Channels))
+		for i, channel := range cfg.Channels {
+			trustedStore := storage.NewTrustedUsersStore(storage.ScopedFilePath(trustedUsersPath, channel, i == 0))
+			ledgerStore := storage.NewLedgerStore(storage.ScopedFilePath(ledgerPath, channel, i == 0))
+
+			botOptions := []application.BotOption{
+				application.WithDialect(dialects[i]),
+				application.WithMessages(catalogs[i]),
+				application.WithAutoResponses(autoResponses[i], autoResponseStores[i]),
+				application.WithLedger(ledgerStore),
+			}
+			if transcript != nil {
+				botOptions = append(botOptions, application.WithTranscript(transcript))
+			}
+
+			bots = append(bots, application.NewBotService(store.ForChannel(channel), chatMux.Channel(channel), logger, trustedStore, botOptions...))
+		}
+		sup := application.NewSupervisor(bots...)
+		sup.ReportToken(chatClient.TokenStatus)
+		sup.ReportSay(chatClient.SayStats)
+		return sup
+	}, nil
+}
+
+// newAutoResponsesStore opens an auto-responses file, created with the
+// auto_responses of the configuration file when it has some.
+func newAutoResponsesStore(path string, store *config.EnvStore) *storage.AutoResponsesStore {
+	autoResponseStore := storage.NewAutoResponsesStore(path)
+	if file := store.File(); file != nil && file.AutoResponses != nil {
+		autoResponseStore.SetDefaults(file.AutoResponses)
+	}
+	return autoResponseStore
+}
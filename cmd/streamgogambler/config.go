@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/joho/godotenv"
+
+	"streamgogambler/internal/adapters/config"
+	"streamgogambler/internal/adapters/dialect"
+	"streamgogambler/internal/adapters/locale"
+)
+
+const configUsage = "Usage: streamgogambler config validate [config.yaml]"
+
+func runConfig(args []string) int {
+	if len(args) == 0 || args[0] != "validate" || len(args) > 2 {
+		fmt.Fprintln(os.Stderr, configUsage)
+		return 2
+	}
+
+	envPath := config.ResolveEnvPath()
+	_ = godotenv.Load(envPath)
+	if len(args) == 2 {
+		path, err := filepath.Abs(args[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Configuration file %s: %v\n", args[1], err)
+			return 1
+		}
+		_ = os.Setenv("CONFIG_FILE", path)
+	}
+
+	problems := validateConfig(envPath)
+	if len(problems) > 0 {
+		fmt.Fprintln(os.Stderr, (&config.ValidationError{Problems: problems}).Error())
+		return 1
+	}
+
+	source := envPath
+	if file := config.ResolveConfigFile(envPath); file != "" {
+		source = file + " and " + envPath
+	}
+	fmt.Printf("Configuration in %s is valid\n", source)
+	return 0
+}
+
+// validateConfig returns every problem in the configuration, including boss
+// bot dialects and reply catalogs that cannot be loaded.
+func validateConfig(envPath string) []error {
+	store, err := config.NewEnvStore(envPath)
+	var invalid *config.ValidationError
+	switch {
+	case errors.As(err, &invalid):
+		return invalid.Problems
+	case err != nil:
+		return []error{err}
+	}
+
+	var problems []error
+	for _, account := range append([]*config.EnvStore{store}, store.Accounts()...) {
+		cfg := account.GetConfig()
+		for _, channel := range cfg.Channels {
+			channelCfg := account.ForChannel(channel).GetConfig()
+			if _, err := dialect.Resolve(channelCfg.BossBotDialect, filepath.Dir(envPath)); err != nil {
+				problems = append(problems, fmt.Errorf("boss bot dialect for %s in #%s: %w", cfg.Username, channel, err))
+			}
+			if _, err := locale.Resolve(channelCfg.Language, channelCfg.MessagesFile, filepath.Dir(envPath)); err != nil {
+				problems = append(problems, fmt.Errorf("bot language for %s in #%s: %w", cfg.Username, channel, err))
+			}
+		}
+	}
+	return problems
+}
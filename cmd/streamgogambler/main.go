@@ -14,8 +14,8 @@ import (
 	"streamgogambler/internal/adapters/healthcheck"
 	"streamgogambler/internal/adapters/logging"
 	"streamgogambler/internal/adapters/storage"
-	"streamgogambler/internal/adapters/twitch"
 	"streamgogambler/internal/application"
+	"streamgogambler/internal/ports"
 )
 
 var (
@@ -27,6 +27,19 @@ var (
 func main() {
 	log.SetFlags(log.Ldate | log.Ltime)
 
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "simulate":
+			os.Exit(runSimulate(os.Args[2:]))
+		case "replay":
+			os.Exit(runReplay(os.Args[2:]))
+		case "config":
+			os.Exit(runConfig(os.Args[2:]))
+		case "secrets":
+			os.Exit(runSecrets(os.Args[2:]))
+		}
+	}
+
 	if !AcquireSingleInstanceLock() {
 		log.Printf("[ERROR] Another instance of StreamGoGambler is already running.")
 		gui.ShowErrorDialog("StreamGoGambler is already running", "Another instance of the application is already running. Only one instance can run at a time.")
@@ -39,7 +52,7 @@ func main() {
 		log.Printf("[WARN] Could not load .env from %s: %v", envPath, err)
 	}
 
-	missingVars := config.GetMissingVariables()
+	missingVars := config.GetMissingVariables(envPath)
 	if len(missingVars) > 0 {
 		log.Printf("[INFO] Missing configuration variables: %v", missingVars)
 		log.Printf("[INFO] Launching setup dialog...")
@@ -53,6 +66,14 @@ func main() {
 			os.Exit(0) //nolint:gocritic
 		}
 
+		if result.EncryptOAuth {
+			if err := config.SetSecret(envPath, "TWITCH_OAUTH", result.Values["TWITCH_OAUTH"]); err != nil {
+				log.Fatalf("[FATAL] Failed to save the OAuth token: %v", err)
+			}
+			delete(result.Values, "TWITCH_OAUTH")
+			log.Printf("[INFO] OAuth token saved to %s", config.ResolveSecretsFile(envPath))
+		}
+
 		if err := config.SaveConfigToEnv(envPath, result.Values); err != nil {
 			log.Fatalf("[FATAL] Failed to save configuration: %v", err)
 		}
@@ -73,18 +94,30 @@ func main() {
 	logger := logging.NewFromString(cfg.LogLevel)
 	logger.Infof(ctx, "StreamGoGambler %s (commit: %s, built: %s)", version, commit, buildDate)
 
-	chatClient := twitch.NewClient(
-		cfg.Username,
-		cfgStore.GetOAuth(),
-		twitch.WithBucketSize(cfg.SayBucketSize),
-		twitch.WithRefillMs(cfg.SayRefillMs),
-		twitch.WithLogger(logger),
-	)
+	var transcriptStore *storage.TranscriptStore
+	if transcriptPath := storage.ResolveTranscriptPath(envPath, cfg.TranscriptFile); transcriptPath != "" {
+		logger.Infof(ctx, "Recording chat transcript to %s", transcriptPath)
+		transcriptStore = storage.NewTranscriptStore(transcriptPath)
+	}
 
-	trustedUsersPath := storage.ResolveTrustedUsersPath(envPath)
-	trustedStore := storage.NewTrustedUsersStore(trustedUsersPath)
+	botService := application.NewAccountManager(logger)
+	accountStores := make(map[string]*config.EnvStore)
+	for i, store := range append([]*config.EnvStore{cfgStore}, cfgStore.Accounts()...) {
+		factory, err := newAccountFactory(ctx, store, envPath, i == 0, logger, transcriptStore)
+		if err != nil {
+			log.Fatalf("[FATAL] %v", err)
+		}
+		botService.Add(store.GetConfig().Username, factory)
+		accountStores[store.GetConfig().Username] = store
+	}
 
-	botService := application.NewBotService(cfgStore, chatClient, logger, trustedStore)
+	cfgStore.Subscribe(func(old, updated ports.BotConfig) {
+		if updated.LogLevel != old.LogLevel {
+			logger.SetLevel(logging.ParseLevel(updated.LogLevel))
+			logger.Infof(ctx, "Log level changed to %s", updated.LogLevel)
+		}
+	})
+	go cfgStore.Watch(ctx, config.ReloadInterval, logger)
 
 	if cfg.HealthPort > 0 {
 		healthServer := healthcheck.NewHealthServer(cfg.HealthPort, botService, logger)
@@ -105,6 +138,12 @@ func main() {
 		HideConsole()
 
 		botGUI := gui.New(botService, cfg.MaxLogsLines)
+		botGUI.OnTokenEntered(func(account, token string) error {
+			if err := accountStores[account].SaveOAuth(token); err != nil {
+				return err
+			}
+			return botService.StartAccount(account)
+		})
 
 		logger.SetCallback(func(message string) {
 			botGUI.AppendLog(message)
@@ -133,6 +172,7 @@ func main() {
 		logger.Infof(ctx, "Application terminated")
 	} else {
 		logger.Infof(ctx, "Running in headless mode (GUI disabled)")
+		failed := false
 		select {
 		case sig := <-sigChan:
 			logger.Infof(ctx, "Received signal %v, shutting down...", sig)
@@ -140,10 +180,17 @@ func main() {
 			if err != nil {
 				logger.Errorf(ctx, "Bot error: %v", err)
 			}
+		case err := <-botService.AllStopped():
+			logger.Errorf(ctx, "No account is running (%v), shutting down", err)
+			failed = true
 		}
 
 		cancel()
 		botService.Stop()
 		logger.Infof(ctx, "Application terminated")
+		if failed {
+			ReleaseSingleInstanceLock()
+			os.Exit(1)
+		}
 	}
 }
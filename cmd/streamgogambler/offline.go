@@ -0,0 +1,36 @@
+package main
+
+import (
+	"os"
+
+	"github.com/joho/godotenv"
+
+	"streamgogambler/internal/adapters/config"
+)
+
+// offlineDefaults fill in the settings only needed to talk to Twitch, so the
+// simulate and replay subcommands run without a complete .env.
+var offlineDefaults = map[string]string{
+	"TWITCH_USERNAME": "simbot",
+	"TWITCH_OAUTH":    "oauth:offline",
+	"TWITCH_CHANNEL":  "simulation",
+}
+
+func loadOfflineConfig() (*config.EnvStore, string, error) {
+	envPath := config.ResolveEnvPath()
+	_ = godotenv.Load(envPath)
+
+	for key, value := range offlineDefaults {
+		if os.Getenv(key) == "" {
+			_ = os.Setenv(key, value)
+		}
+	}
+	for key, value := range config.GetDefaultValues() {
+		if os.Getenv(key) == "" {
+			_ = os.Setenv(key, value)
+		}
+	}
+
+	store, err := config.NewEnvStore(envPath)
+	return store, envPath, err
+}
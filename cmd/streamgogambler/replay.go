@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"streamgogambler/internal/adapters/dialect"
+	"streamgogambler/internal/adapters/locale"
+	"streamgogambler/internal/adapters/storage"
+	"streamgogambler/internal/replay"
+)
+
+func runReplay(args []string) int {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	balance := fs.Int("balance", 0, "balance before the first message")
+	user := fs.String("user", "", "bot username in the transcript, overrides TWITCH_USERNAME")
+	boss := fs.String("boss", "", "boss bot name in the transcript, overrides BOSS_BOT_NAME")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: streamgogambler replay [flags] <transcript.jsonl>")
+		return 2
+	}
+
+	cfgStore, envPath, err := loadOfflineConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return 1
+	}
+	cfg := cfgStore.GetConfig()
+	if *user != "" {
+		cfg.Username = *user
+	}
+	if *boss != "" {
+		cfg.BossBotName = *boss
+		cfg.BossBotID = ""
+	}
+
+	bossDialect, err := dialect.Resolve(cfg.BossBotDialect, filepath.Dir(envPath))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Boss bot dialect error: %v\n", err)
+		return 1
+	}
+
+	messages, err := locale.Resolve(cfg.Language, cfg.MessagesFile, filepath.Dir(envPath))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Bot language error: %v\n", err)
+		return 1
+	}
+
+	autoResponses, err := newAutoResponsesStore(storage.ResolveAutoResponsesPath(envPath), cfgStore).Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Auto-responses error: %v\n", err)
+		return 1
+	}
+
+	entries, err := storage.NewTranscriptStore(fs.Arg(0)).Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Reading transcript: %v\n", err)
+		return 1
+	}
+
+	result := replay.Run(context.Background(), entries, replay.Options{
+		Bot:           cfg,
+		Dialect:       bossDialect,
+		Messages:      messages,
+		AutoResponses: autoResponses,
+		StartBalance:  *balance,
+	})
+	if err := result.Write(os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Writing replay: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/joho/godotenv"
+
+	"streamgogambler/internal/adapters/config"
+)
+
+const secretsUsage = "Usage: streamgogambler secrets migrate | rotate [account]"
+
+func runSecrets(args []string) int {
+	envPath := config.ResolveEnvPath()
+	_ = godotenv.Load(envPath)
+	path := config.ResolveSecretsFile(envPath)
+
+	switch {
+	case len(args) == 1 && args[0] == "migrate":
+		moved, err := config.MigrateSecrets(envPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Migration failed: %v\n", err)
+			return 1
+		}
+		if len(moved) == 0 {
+			fmt.Printf("No OAuth tokens in %s\n", envPath)
+			return 0
+		}
+		fmt.Printf("Moved %s from %s to %s\n", strings.Join(moved, ", "), envPath, path)
+		return 0
+	case len(args) >= 1 && len(args) <= 2 && args[0] == "rotate":
+		key := "TWITCH_OAUTH"
+		if len(args) == 2 {
+			key = config.AccountEnvPrefix(args[1]) + key
+		}
+		token, err := readToken(key)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if err := config.SetSecret(envPath, key, token); err != nil {
+			fmt.Fprintf(os.Stderr, "Saving %s failed: %v\n", key, err)
+			return 1
+		}
+		fmt.Printf("Saved %s to %s; restart the bot to use it\n", key, path)
+		return 0
+	}
+
+	fmt.Fprintln(os.Stderr, secretsUsage)
+	return 2
+}
+
+// readToken reads the new token from standard input rather than the command
+// line, which would keep it in the shell history.
+func readToken(key string) (string, error) {
+	fmt.Fprintf(os.Stderr, "New %s (without the oauth: prefix): ", key)
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	token := strings.TrimPrefix(strings.TrimSpace(line), "oauth:")
+	if token == "" {
+		if err != nil {
+			return "", fmt.Errorf("reading the token: %w", err)
+		}
+		return "", fmt.Errorf("no token given")
+	}
+	return token, nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"streamgogambler/internal/adapters/storage"
+	"streamgogambler/internal/domain/gambling"
+	"streamgogambler/internal/simulation"
+)
+
+func runSimulate(args []string) int {
+	fs := flag.NewFlagSet("simulate", flag.ContinueOnError)
+	runs := fs.Int("runs", simulation.DefaultRuns, "number of Monte Carlo runs")
+	duration := fs.Duration("duration", simulation.DefaultDuration, "simulated time per run")
+	balance := fs.Int("balance", simulation.DefaultStartBalance, "starting balance in bombs")
+	seed := fs.Uint64("seed", uint64(time.Now().UnixNano()), "random seed")
+	oddsPath := fs.String("odds", "", "YAML/JSON file with the boss bot probability table")
+	strategy := fs.String("strategy", "", "betting strategy, overrides BETTING_STRATEGY")
+	heist := fs.Int("heist", 0, "heist amount, overrides HEIST_AMOUNT")
+	autoSlots := fs.Bool("autoslots", true, "play auto slots during the simulation")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cfgStore, envPath, err := loadOfflineConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return 1
+	}
+	cfg := cfgStore.GetConfig()
+	cfg.AutoSlotsEnabled = *autoSlots
+	if *strategy != "" {
+		if _, err := gambling.ParseStrategy(*strategy); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid strategy: %v\n", err)
+			return 2
+		}
+		cfg.BettingStrategy = *strategy
+	}
+	if *heist > 0 {
+		cfg.DefaultHeist = gambling.ClampHeistAmount(*heist)
+	}
+
+	autoResponses, err := newAutoResponsesStore(storage.ResolveAutoResponsesPath(envPath), cfgStore).Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Auto-responses error: %v\n", err)
+		return 1
+	}
+
+	odds := simulation.DefaultOdds()
+	if *oddsPath != "" {
+		if odds, err = simulation.LoadOdds(*oddsPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Odds error: %v\n", err)
+			return 1
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := simulation.Run(ctx, simulation.Config{
+		Bot:           cfg,
+		AutoResponses: autoResponses,
+		Odds:          odds,
+		Runs:          *runs,
+		Duration:      *duration,
+		StartBalance:  *balance,
+		Seed:          *seed,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Simulation failed: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Strategy: %s | Heist: %d | Slots: %d | FFA: %d | Seed: %d\n\n",
+		cfg.BettingStrategy, cfg.DefaultHeist, cfg.SlotsCost, cfg.ArenaCost, *seed)
+	if err := report.Write(os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Writing report: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"streamgogambler/internal/domain/gambling"
+	"streamgogambler/internal/domain/i18n"
+	"streamgogambler/internal/ports"
+)
+
+// ChannelSettings lists the settings that can be overridden for a single
+// channel with CHANNEL_<NAME>_<SETTING>, e.g. CHANNEL_FOO_SLOTS_COST=3000.
+var ChannelSettings = []string{
+	"BOSS_BOT_NAME",
+	"BOSS_BOT_ID",
+	"BOSS_BOT_DIALECT",
+	"BOT_LANGUAGE",
+	"BOT_MESSAGES",
+	"HEIST_AMOUNT",
+	"SLOTS_COST",
+	"ARENA_COST",
+	"BOSS_COST",
+	"AUTO_SLOTS_ENABLED",
+	"BETTING_STRATEGY",
+	"RESERVE_FLOOR",
+	"STOP_LOSS",
+	"TAKE_PROFIT",
+}
+
+// ParseChannels splits a comma-separated TWITCH_CHANNEL value into lowercase
+// channel names without the # prefix, dropping empty entries and duplicates.
+func ParseChannels(value string) []string {
+	var channels []string
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(value, ",") {
+		channel := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(part), "#"))
+		if channel == "" || seen[channel] {
+			continue
+		}
+		seen[channel] = true
+		channels = append(channels, channel)
+	}
+	return channels
+}
+
+// ChannelEnvKey returns the environment variable overriding setting for channel.
+func ChannelEnvKey(channel, setting string) string {
+	return "CHANNEL_" + strings.ToUpper(channel) + "_" + setting
+}
+
+func (s *EnvStore) channelConfig(p *problems, base ports.BotConfig, channel string) ports.BotConfig {
+	cfg := base
+	cfg.Channel = channel
+
+	for _, setting := range ChannelSettings {
+		key := ChannelEnvKey(channel, setting)
+		value := s.lookup(key)
+		if value == "" {
+			continue
+		}
+
+		switch setting {
+		case "BOSS_BOT_NAME":
+			cfg.BossBotName = value
+		case "BOSS_BOT_ID":
+			cfg.BossBotID = value
+		case "BOSS_BOT_DIALECT":
+			cfg.BossBotDialect = value
+		case "BOT_LANGUAGE":
+			cfg.Language = strings.ToLower(value)
+			if err := i18n.CheckLanguage(cfg.Language); err != nil {
+				p.add(key, err)
+			}
+		case "BOT_MESSAGES":
+			cfg.MessagesFile = value
+		case "HEIST_AMOUNT":
+			cfg.DefaultHeist = p.limitedInt(key, setting, cfg.DefaultHeist)
+		case "SLOTS_COST":
+			cfg.SlotsCost = p.limitedInt(key, setting, cfg.SlotsCost)
+		case "ARENA_COST":
+			cfg.ArenaCost = p.limitedInt(key, setting, cfg.ArenaCost)
+		case "BOSS_COST":
+			cfg.BossCost = p.limitedInt(key, setting, cfg.BossCost)
+		case "AUTO_SLOTS_ENABLED":
+			cfg.AutoSlotsEnabled = p.bool(key, cfg.AutoSlotsEnabled)
+		case "BETTING_STRATEGY":
+			if _, err := gambling.ParseStrategy(value); err != nil {
+				p.add(key, err)
+			}
+			cfg.BettingStrategy = value
+		case "RESERVE_FLOOR":
+			cfg.ReserveFloor = p.limitedInt(key, setting, cfg.ReserveFloor)
+		case "STOP_LOSS":
+			cfg.StopLoss = p.limitedInt(key, setting, cfg.StopLoss)
+		case "TAKE_PROFIT":
+			cfg.TakeProfit = p.limitedInt(key, setting, cfg.TakeProfit)
+		}
+	}
+
+	return cfg
+}
+
+// ChannelStore is the ports.ConfigStore view of a single channel of an
+// EnvStore.
+type ChannelStore struct {
+	parent  *EnvStore
+	channel string
+}
+
+// ForChannel returns the configuration of one of the joined channels, with
+// its CHANNEL_<NAME>_* overrides applied.
+func (s *EnvStore) ForChannel(channel string) *ChannelStore {
+	return &ChannelStore{parent: s, channel: strings.ToLower(channel)}
+}
+
+func (c *ChannelStore) GetConfig() ports.BotConfig {
+	c.parent.mu.RLock()
+	defer c.parent.mu.RUnlock()
+
+	if cfg, ok := c.parent.channels[c.channel]; ok {
+		return cfg
+	}
+	cfg := c.parent.config
+	cfg.Channel = c.channel
+	return cfg
+}
+
+// Subscribe calls handler after every reload that changes the channel's
+// configuration.
+func (c *ChannelStore) Subscribe(handler func(old, updated ports.BotConfig)) func() {
+	return c.parent.subscribe(c.channel, handler)
+}
+
+func (c *ChannelStore) GetOAuth() string {
+	return c.parent.GetOAuth()
+}
+
+func (c *ChannelStore) UpdateHeist(amount int) error {
+	return c.Set(ports.SettingHeistAmount, strconv.Itoa(amount))
+}
+
+// Set saves a setting for the whole account when only one channel is joined
+// or the setting cannot differ per channel, and as the channel's own
+// CHANNEL_<NAME>_<SETTING> otherwise.
+func (c *ChannelStore) Set(key ports.Setting, value string) error {
+	if len(c.parent.GetConfig().Channels) <= 1 || !slices.Contains(ChannelSettings, string(key)) {
+		return c.parent.Set(key, value)
+	}
+	return c.parent.save(key, c.parent.prefix+ChannelEnvKey(c.channel, string(key)), value)
+}
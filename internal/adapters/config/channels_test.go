@@ -0,0 +1,167 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"streamgogambler/internal/domain/i18n"
+	"streamgogambler/internal/ports"
+)
+
+func TestParseChannels(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{"single", "foo", []string{"foo"}},
+		{"list", "foo, bar,baz", []string{"foo", "bar", "baz"}},
+		{"hash and case", "#Foo,#BAR", []string{"foo", "bar"}},
+		{"empty entries and duplicates", "foo,,FOO, ,bar", []string{"foo", "bar"}},
+		{"empty", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, ParseChannels(tt.value))
+		})
+	}
+}
+
+func setRequiredEnv(t *testing.T, channels string) {
+	t.Helper()
+	t.Setenv("TWITCH_USERNAME", "testuser")
+	t.Setenv("TWITCH_OAUTH", "token")
+	t.Setenv("TWITCH_CHANNEL", channels)
+	t.Setenv("COMMAND_PREFIX", "!")
+	t.Setenv("STATUS_COMMAND", "status")
+	t.Setenv("CONNECT_MESSAGE", "!pyk")
+	t.Setenv("BOSS_BOT_NAME", "demonzzbot")
+}
+
+// The tests below use t.Setenv and cannot run in parallel.
+
+func TestForChannelAppliesOverrides(t *testing.T) {
+	setRequiredEnv(t, "foo,bar")
+	t.Setenv("SLOTS_COST", "2000")
+	t.Setenv("CHANNEL_BAR_SLOTS_COST", "3000")
+	t.Setenv("CHANNEL_BAR_BOSS_BOT_NAME", "pointsbot")
+	t.Setenv("CHANNEL_BAR_AUTO_SLOTS_ENABLED", "true")
+
+	store, err := NewEnvStore(filepath.Join(t.TempDir(), ".env"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"foo", "bar"}, store.GetConfig().Channels)
+
+	foo := store.ForChannel("foo").GetConfig()
+	assert.Equal(t, "foo", foo.Channel)
+	assert.Equal(t, 2000, foo.SlotsCost)
+	assert.Equal(t, "demonzzbot", foo.BossBotName)
+	assert.False(t, foo.AutoSlotsEnabled)
+
+	bar := store.ForChannel("bar").GetConfig()
+	assert.Equal(t, "bar", bar.Channel)
+	assert.Equal(t, 3000, bar.SlotsCost)
+	assert.Equal(t, "pointsbot", bar.BossBotName)
+	assert.True(t, bar.AutoSlotsEnabled)
+}
+
+func TestForChannelRejectsInvalidOverride(t *testing.T) {
+	setRequiredEnv(t, "foo")
+	t.Setenv("CHANNEL_FOO_HEIST_AMOUNT", "lots")
+
+	_, err := NewEnvStore(filepath.Join(t.TempDir(), ".env"))
+	assert.ErrorContains(t, err, "CHANNEL_FOO_HEIST_AMOUNT")
+}
+
+func TestChannelUpdateHeist(t *testing.T) {
+	setRequiredEnv(t, "foo,bar")
+	t.Setenv("HEIST_AMOUNT", "1000")
+	t.Setenv("CHANNEL_BAR_HEIST_AMOUNT", "") // restored after UpdateHeist sets it
+
+	store, err := NewEnvStore(filepath.Join(t.TempDir(), ".env"))
+	require.NoError(t, err)
+
+	require.NoError(t, store.ForChannel("bar").UpdateHeist(2500))
+
+	assert.Equal(t, 2500, store.ForChannel("bar").GetConfig().DefaultHeist)
+	assert.Equal(t, 1000, store.ForChannel("foo").GetConfig().DefaultHeist, "other channels keep theirs")
+	assert.Equal(t, 1000, store.GetConfig().DefaultHeist)
+}
+
+func TestBotLanguagePerChannel(t *testing.T) {
+	setRequiredEnv(t, "foo,bar")
+	t.Setenv("CHANNEL_BAR_BOT_LANGUAGE", "EN")
+	t.Setenv("CHANNEL_BAR_BOT_MESSAGES", "messages_en.yaml")
+
+	store, err := NewEnvStore(filepath.Join(t.TempDir(), ".env"))
+	require.NoError(t, err)
+
+	foo := store.ForChannel("foo").GetConfig()
+	assert.Equal(t, "pl", foo.Language, "default language")
+	assert.Empty(t, foo.MessagesFile)
+
+	bar := store.ForChannel("bar").GetConfig()
+	assert.Equal(t, "en", bar.Language)
+	assert.Equal(t, "messages_en.yaml", bar.MessagesFile)
+}
+
+func TestBotLanguageRejectsUnknown(t *testing.T) {
+	setRequiredEnv(t, "foo")
+	t.Setenv("BOT_LANGUAGE", "xx")
+
+	_, err := NewEnvStore(filepath.Join(t.TempDir(), ".env"))
+	require.ErrorIs(t, err, i18n.ErrUnknownLanguage)
+	assert.ErrorContains(t, err, "BOT_LANGUAGE")
+
+	t.Setenv("BOT_LANGUAGE", "en")
+	t.Setenv("CHANNEL_FOO_BOT_LANGUAGE", "xx")
+	_, err = NewEnvStore(filepath.Join(t.TempDir(), ".env"))
+	assert.ErrorContains(t, err, "CHANNEL_FOO_BOT_LANGUAGE")
+}
+
+func TestSetSavesAndAppliesSetting(t *testing.T) {
+	setRequiredEnv(t, "foo,bar")
+	t.Setenv("SLOTS_COST", "")
+	t.Setenv("CHANNEL_BAR_SLOTS_COST", "")
+	t.Setenv("BAND_MESSAGE", "")
+	t.Setenv("GREET_ON_RECONNECT", "")
+	envPath := filepath.Join(t.TempDir(), ".env")
+
+	store, err := NewEnvStore(envPath)
+	require.NoError(t, err)
+	var changes []ports.BotConfig
+	store.ForChannel("foo").Subscribe(func(_, updated ports.BotConfig) {
+		changes = append(changes, updated)
+	})
+
+	require.NoError(t, store.ForChannel("bar").Set(ports.SettingSlotsCost, " 4000 "))
+	require.NoError(t, store.ForChannel("bar").Set(ports.SettingGreetOnReconnect, "TRUE"))
+	require.NoError(t, store.ForChannel("foo").Set(ports.SettingBandMessage, "Do widzenia!"))
+
+	bar, foo := store.ForChannel("bar").GetConfig(), store.ForChannel("foo").GetConfig()
+	assert.Equal(t, 4000, bar.SlotsCost, "channel setting saved for the channel")
+	assert.Equal(t, 2000, foo.SlotsCost, "other channels keep theirs")
+	assert.True(t, foo.GreetOnReconnect, "account setting saved for every channel")
+	assert.Equal(t, "Do widzenia!", foo.BandMessage)
+	assert.Len(t, changes, 2, "subscriber notified of the changes to its channel")
+
+	data, err := os.ReadFile(envPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "CHANNEL_BAR_SLOTS_COST=4000\n")
+	assert.Contains(t, string(data), "GREET_ON_RECONNECT=true\n")
+	assert.Contains(t, string(data), "BAND_MESSAGE=Do widzenia!\n")
+
+	require.ErrorIs(t, store.Set(ports.SettingSlotsCost, "-1"), ports.ErrInvalidSetting)
+	require.ErrorIs(t, store.Set(ports.SettingPointsAsDelta, "maybe"), ports.ErrInvalidSetting)
+	require.ErrorIs(t, store.Set(ports.SettingBandMessage, " "), ports.ErrInvalidSetting)
+	require.ErrorIs(t, store.Set("TWITCH_OAUTH", "x"), ports.ErrUnknownSetting)
+	assert.Equal(t, 2000, store.GetConfig().SlotsCost, "invalid values are not applied")
+}
@@ -2,13 +2,20 @@ package config
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 
 	"streamgogambler/internal/domain/gambling"
+	"streamgogambler/internal/domain/i18n"
+	"streamgogambler/internal/domain/parsing"
+	"streamgogambler/internal/domain/permissions"
 	"streamgogambler/internal/ports"
 )
 
@@ -19,115 +26,369 @@ const (
 )
 
 type EnvStore struct {
-	envPath string
-	config  ports.BotConfig
-	oauth   string
+	envPath  string
+	file     *File
+	secrets  map[string]string
+	mu       sync.RWMutex
+	config   ports.BotConfig
+	channels map[string]ports.BotConfig
+	oauth    string
+
+	// account and prefix are set for the additional accounts listed in
+	// TWITCH_ACCOUNTS, whose settings are read from ACCOUNT_<NAME>_*.
+	account  string
+	prefix   string
+	accounts []*EnvStore
+
+	// dotenv holds the variables loaded from .env, updated on Reload.
+	dotenv           map[string]string
+	subscriptions    map[int]subscription
+	nextSubscription int
 }
 
+// NewEnvStore reads the configuration from the environment and the optional
+// configuration file (see ResolveConfigFile); environment variables override
+// file values. Every invalid setting is reported in one ValidationError.
 func NewEnvStore(envPath string) (*EnvStore, error) {
-	store := &EnvStore{envPath: envPath}
-	if err := store.load(); err != nil {
+	var file *File
+	var problems []error
+	if path := ResolveConfigFile(envPath); path != "" {
+		var err error
+		file, err = LoadFile(path)
+		var invalid *ValidationError
+		switch {
+		case errors.As(err, &invalid):
+			problems = append(problems, invalid.Problems...)
+		case err != nil:
+			return nil, err
+		}
+	}
+
+	secrets, err := LoadSecrets(ResolveSecretsFile(envPath))
+	if err != nil {
 		return nil, err
 	}
+
+	store := &EnvStore{envPath: envPath, file: file, secrets: secrets, dotenv: readEnvFile(envPath)}
+	problems = append(problems, store.load()...)
+
+	for _, name := range ParseChannels(os.Getenv("TWITCH_ACCOUNTS")) {
+		if strings.EqualFold(name, store.config.Username) {
+			continue
+		}
+		account := &EnvStore{envPath: envPath, file: file, secrets: secrets, account: name, prefix: AccountEnvPrefix(name)}
+		for _, err := range account.load() {
+			problems = append(problems, fmt.Errorf("account %s: %w", name, err))
+		}
+		store.accounts = append(store.accounts, account)
+	}
+
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
 	return store, nil
 }
 
-func (s *EnvStore) load() error {
-	required := []string{
-		"TWITCH_USERNAME",
-		"TWITCH_OAUTH",
-		"TWITCH_CHANNEL",
-		"COMMAND_PREFIX",
-		"STATUS_COMMAND",
-		"CONNECT_MESSAGE",
-		"BOSS_BOT_NAME",
-	}
+// AccountEnvPrefix returns the prefix of the settings of an additional
+// account, e.g. ACCOUNT_ALT1_ for alt1.
+func AccountEnvPrefix(account string) string {
+	return "ACCOUNT_" + strings.ToUpper(account) + "_"
+}
+
+// Accounts returns the additional accounts listed in TWITCH_ACCOUNTS.
+func (s *EnvStore) Accounts() []*EnvStore {
+	return s.accounts
+}
+
+// File returns the configuration file, nil when there is none.
+func (s *EnvStore) File() *File {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.file
+}
+
+// load reads the settings and returns every problem found.
+func (s *EnvStore) load() []error {
+	p := &problems{store: s}
 
 	var missing []string
-	for _, k := range required {
-		if os.Getenv(k) == "" {
-			missing = append(missing, k)
+	for _, k := range RequiredVariables() {
+		if s.lookup(k) == "" {
+			missing = append(missing, s.prefix+k)
 		}
 	}
 	if len(missing) > 0 {
-		return fmt.Errorf("missing required environment variables: %s", strings.Join(missing, ", "))
-	}
-
-	heist, _ := strconv.Atoi(getEnv("HEIST_AMOUNT", strconv.Itoa(gambling.DefaultHeistAmount)))
-	slotsCost, _ := strconv.Atoi(getEnv("SLOTS_COST", strconv.Itoa(gambling.DefaultSlotsCost)))
-	arenaCost, _ := strconv.Atoi(getEnv("ARENA_COST", strconv.Itoa(gambling.DefaultArenaCost)))
-	autoSlotsEnabled := strings.ToLower(getEnv("AUTO_SLOTS_ENABLED", "false")) == trueString
-	autoSlotsInterval, _ := strconv.Atoi(getEnv("AUTO_SLOTS_INTERVAL", "15"))
-	bandOnPerma := strings.ToLower(getEnv("BAND_ON_PERMA", "false")) == trueString
-	pointsAsDelta := strings.ToLower(getEnv("POINTS_AS_DELTA", trueString)) == trueString
-	bucketSize, _ := strconv.Atoi(getEnv("SAY_BUCKET_SIZE", strconv.Itoa(DefaultSayBucketSize)))
-	refillMs, _ := strconv.Atoi(getEnv("SAY_REFILL_MS", strconv.Itoa(DefaultSayRefillMs)))
-	greetOnReconnect := strings.ToLower(getEnv("GREET_ON_RECONNECT", "false")) == trueString
-	healthPort, _ := strconv.Atoi(getEnv("HEALTH_PORT", "0"))
-	guiEnabled := strings.ToLower(getEnv("GUI_ENABLED", trueString)) == trueString
-	maxLogsLines, _ := strconv.Atoi(getEnv("MAX_LOGS_LINES", "500"))
-
-	autoResponses := map[string]string{
-		"Type !boss to start!": "!boss",
-		"Type !boss to join!":  "!boss",
-		"Type !ffa to start!":  "!ffa",
-		"!los":                 "!los",
-		"The cops have given up! If you want to get a team together type !heist": "!heist",
+		p.errs = append(p.errs, fmt.Errorf("missing required settings: %s", strings.Join(missing, ", ")))
+	}
+
+	channels := ParseChannels(s.lookup("TWITCH_CHANNEL"))
+	if len(channels) == 0 && len(missing) == 0 {
+		p.add("TWITCH_CHANNEL", errors.New("no channel given"))
+	}
+
+	heist := p.int("HEIST_AMOUNT", gambling.DefaultHeistAmount)
+	slotsCost := p.int("SLOTS_COST", gambling.DefaultSlotsCost)
+	arenaCost := p.int("ARENA_COST", gambling.DefaultArenaCost)
+	bossCost := p.int("BOSS_COST", gambling.DefaultBossCost)
+	payouts, err := parsing.ParsePayoutTable(s.lookup("SLOTS_PAYOUTS"))
+	if err != nil {
+		p.add("SLOTS_PAYOUTS", err)
+	}
+	slotsPayouts := make(map[string]float64, len(payouts))
+	for outcome, mult := range payouts {
+		slotsPayouts[string(outcome)] = mult
+	}
+	bettingStrategy := s.getEnv("BETTING_STRATEGY", gambling.StrategyFixed)
+	if _, err := gambling.ParseStrategy(bettingStrategy); err != nil {
+		p.add("BETTING_STRATEGY", err)
+	}
+	commandPermissions := s.lookup("COMMAND_PERMISSIONS")
+	if _, err := permissions.ParsePolicy(commandPermissions); err != nil {
+		p.add("COMMAND_PERMISSIONS", err)
+	}
+	language := strings.ToLower(s.getEnv("BOT_LANGUAGE", i18n.DefaultLanguage))
+	if err := i18n.CheckLanguage(language); err != nil {
+		p.add("BOT_LANGUAGE", err)
+	}
+	authURL := s.lookup("TWITCH_AUTH_URL")
+	if u, err := url.Parse(authURL); authURL != "" && (err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "") {
+		p.add("TWITCH_AUTH_URL", fmt.Errorf("%q is not an http(s) URL", authURL))
+	}
+	logLevel := strings.ToLower(s.getEnv("LOG_LEVEL", "info"))
+	if !slices.Contains(logLevels, logLevel) {
+		p.add("LOG_LEVEL", fmt.Errorf("%q is not one of %s", logLevel, strings.Join(logLevels, ", ")))
 	}
 
 	s.config = ports.BotConfig{
-		Username:          os.Getenv("TWITCH_USERNAME"),
-		Channel:           os.Getenv("TWITCH_CHANNEL"),
-		Prefix:            os.Getenv("COMMAND_PREFIX"),
-		StatusCommand:     os.Getenv("STATUS_COMMAND"),
-		ConnectMessage:    os.Getenv("CONNECT_MESSAGE"),
-		BossBotName:       os.Getenv("BOSS_BOT_NAME"),
-		DefaultHeist:      heist,
-		SlotsCost:         slotsCost,
-		ArenaCost:         arenaCost,
-		AutoSlotsEnabled:  autoSlotsEnabled,
-		AutoSlotsInterval: autoSlotsInterval,
-		BandOnPerma:       bandOnPerma,
-		BandMessage:       getEnv("BAND_MESSAGE", "BAND"),
-		PointsAsDelta:     pointsAsDelta,
-		SayBucketSize:     bucketSize,
-		SayRefillMs:       refillMs,
-		GreetOnReconnect:  greetOnReconnect,
-		LogLevel:          getEnv("LOG_LEVEL", "info"),
-		HealthPort:        healthPort,
-		AutoResponses:     autoResponses,
-		GUIEnabled:        guiEnabled,
-		MaxLogsLines:      maxLogsLines,
-	}
-
-	s.oauth = os.Getenv("TWITCH_OAUTH")
-	return nil
+		Username:            s.lookup("TWITCH_USERNAME"),
+		AuthURL:             authURL,
+		Channels:            channels,
+		Prefix:              s.lookup("COMMAND_PREFIX"),
+		StatusCommand:       s.lookup("STATUS_COMMAND"),
+		ConnectMessage:      s.lookup("CONNECT_MESSAGE"),
+		BossBotName:         s.lookup("BOSS_BOT_NAME"),
+		BossBotID:           s.lookup("BOSS_BOT_ID"),
+		BossBotDialect:      s.getEnv("BOSS_BOT_DIALECT", "default"),
+		DefaultHeist:        heist,
+		SlotsCost:           slotsCost,
+		ArenaCost:           arenaCost,
+		BossCost:            bossCost,
+		SlotsPayouts:        slotsPayouts,
+		BettingStrategy:     bettingStrategy,
+		CommandPermissions:  commandPermissions,
+		Language:            language,
+		MessagesFile:        s.lookup("BOT_MESSAGES"),
+		ReserveFloor:        p.int("RESERVE_FLOOR", 0),
+		StopLoss:            p.int("STOP_LOSS", 0),
+		TakeProfit:          p.int("TAKE_PROFIT", 0),
+		AutoSlotsEnabled:    p.bool("AUTO_SLOTS_ENABLED", false),
+		AutoSlotsInterval:   p.int("AUTO_SLOTS_INTERVAL", 15),
+		BandOnPerma:         p.bool("BAND_ON_PERMA", false),
+		BandMessage:         s.getEnv("BAND_MESSAGE", "BAND"),
+		PointsAsDelta:       p.bool("POINTS_AS_DELTA", true),
+		BalanceSyncInterval: p.int("BALANCE_SYNC_INTERVAL", 30),
+		SayBucketSize:       p.int("SAY_BUCKET_SIZE", DefaultSayBucketSize),
+		SayRefillMs:         p.int("SAY_REFILL_MS", DefaultSayRefillMs),
+		GreetOnReconnect:    p.bool("GREET_ON_RECONNECT", false),
+		LogLevel:            logLevel,
+		HealthPort:          p.int("HEALTH_PORT", 0),
+		TranscriptFile:      s.lookup("TRANSCRIPT_FILE"),
+		GUIEnabled:          p.bool("GUI_ENABLED", true),
+		MaxLogsLines:        p.int("MAX_LOGS_LINES", 500),
+	}
+	if len(channels) > 0 {
+		s.config.Channel = channels[0]
+	}
+
+	s.channels = make(map[string]ports.BotConfig, len(channels))
+	for _, channel := range channels {
+		s.channels[channel] = s.channelConfig(p, s.config, channel)
+	}
+
+	s.oauth = s.lookup("TWITCH_OAUTH")
+	return p.errs
 }
 
 func (s *EnvStore) GetConfig() ports.BotConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.config
 }
 
 func (s *EnvStore) GetOAuth() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.oauth
 }
 
+// SaveOAuth saves a new OAuth token for the next connection: in the secrets
+// file when the current token is kept there, otherwise in .env.
+func (s *EnvStore) SaveOAuth(token string) error {
+	token = strings.TrimPrefix(strings.TrimSpace(token), "oauth:")
+	if token == "" {
+		return fmt.Errorf("TWITCH_OAUTH: %w: empty token", ports.ErrInvalidSetting)
+	}
+
+	key := s.prefix + "TWITCH_OAUTH"
+	s.mu.RLock()
+	_, secret := s.secrets[key]
+	s.mu.RUnlock()
+
+	var err error
+	if secret {
+		err = SetSecret(s.envPath, key, token)
+	} else {
+		err = updateEnvFile(s.envPath, key, token)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.oauth = token
+	s.mu.Unlock()
+	return nil
+}
+
 func (s *EnvStore) UpdateHeist(amount int) error {
-	if err := updateEnvFile(s.envPath, "HEIST_AMOUNT", strconv.Itoa(amount)); err != nil {
+	return s.Set(ports.SettingHeistAmount, strconv.Itoa(amount))
+}
+
+// Set validates a mutable setting, saves it to .env and applies it like a
+// reload, notifying the subscribers.
+func (s *EnvStore) Set(key ports.Setting, value string) error {
+	envKey := s.prefix + string(key)
+	if processSettings[string(key)] {
+		envKey = string(key)
+	}
+	return s.save(key, envKey, value)
+}
+
+func (s *EnvStore) save(key ports.Setting, envKey, value string) error {
+	value, err := normalizeSetting(key, value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	if err := updateEnvFile(s.envPath, envKey, value); err != nil {
 		return err
 	}
-	s.config.DefaultHeist = amount
+	return s.refresh()
+}
+
+// refresh reads the settings again after one was saved and applies them.
+func (s *EnvStore) refresh() error {
+	s.mu.RLock()
+	fresh := &EnvStore{envPath: s.envPath, file: s.file, secrets: s.secrets, account: s.account, prefix: s.prefix}
+	s.mu.RUnlock()
+	if errs := fresh.load(); len(errs) > 0 {
+		return &ValidationError{Problems: errs}
+	}
+	s.apply(fresh, make(map[string]bool))
 	return nil
 }
 
-func getEnv(key, def string) string {
+// processSettings apply to the whole process and are never read per account.
+var processSettings = map[string]bool{
+	"TWITCH_AUTH_URL":     true,
+	"AUTO_SLOTS_INTERVAL": true,
+	"LOG_LEVEL":           true,
+	"HEALTH_PORT":         true,
+	"TRANSCRIPT_FILE":     true,
+	"GUI_ENABLED":         true,
+	"MAX_LOGS_LINES":      true,
+}
+
+// lookup reads a setting. Additional accounts prefer ACCOUNT_<NAME>_<KEY> and
+// fall back to the primary account's value, except for the credentials.
+func (s *EnvStore) lookup(key string) string {
+	value, _ := s.setting(key)
+	return value
+}
+
+// setting reads a setting like lookup and also returns where it came from:
+// the environment variable or the configuration file entry.
+func (s *EnvStore) setting(key string) (value, origin string) {
+	if s.prefix == "" || processSettings[key] {
+		return s.getenv(key)
+	}
+	if v, origin := s.getenv(s.prefix + key); v != "" {
+		return v, origin
+	}
+	switch key {
+	case "TWITCH_USERNAME":
+		return s.account, "TWITCH_ACCOUNTS"
+	case "TWITCH_OAUTH":
+		return "", s.prefix + key
+	}
+	return s.getenv(key)
+}
+
+// getenv reads an environment variable, falling back to the secrets file and
+// the configuration file.
+func (s *EnvStore) getenv(key string) (value, origin string) {
 	if v := os.Getenv(key); v != "" {
+		return v, key
+	}
+	if v := s.secrets[key]; v != "" {
+		return v, fmt.Sprintf("%s in %s", key, filepath.Base(ResolveSecretsFile(s.envPath)))
+	}
+	if v, fileKey, ok := s.file.Lookup(key); ok {
+		return v, fmt.Sprintf("%s in %s", fileKey, filepath.Base(s.file.Path))
+	}
+	return "", key
+}
+
+func (s *EnvStore) getEnv(key, def string) string {
+	if v := s.lookup(key); v != "" {
 		return v
 	}
 	return def
 }
 
 func updateEnvFile(envPath, key, value string) error {
+	err := editEnvFile(envPath, func(lines []string) []string {
+		found := false
+		for i, line := range lines {
+			if strings.HasPrefix(line, key+"=") {
+				lines[i] = fmt.Sprintf("%s=%s", key, value)
+				found = true
+			}
+		}
+		if !found {
+			lines = append(lines, fmt.Sprintf("%s=%s", key, value))
+		}
+		return lines
+	})
+	if err != nil {
+		return err
+	}
+
+	_ = os.Setenv(key, value)
+	return nil
+}
+
+// removeFromEnvFile deletes the given variables from .env and the
+// environment.
+func removeFromEnvFile(envPath string, keys ...string) error {
+	err := editEnvFile(envPath, func(lines []string) []string {
+		return slices.DeleteFunc(lines, func(line string) bool {
+			key, _, ok := strings.Cut(line, "=")
+			return ok && slices.Contains(keys, key)
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		_ = os.Unsetenv(key)
+	}
+	return nil
+}
+
+// editEnvFile replaces .env with the lines returned by edit through a
+// temporary file, so readers never see a partial file.
+func editEnvFile(envPath string, edit func(lines []string) []string) error {
 	envPath = filepath.Clean(envPath)
 	dir := filepath.Dir(envPath)
 	if err := os.MkdirAll(dir, 0750); err != nil {
@@ -138,26 +399,15 @@ func updateEnvFile(envPath, key, value string) error {
 	// #nosec G304 -- envPath is intentionally user-configurable via ENV_PATH
 	if f, err := os.Open(envPath); err == nil {
 		scanner := bufio.NewScanner(f)
-		found := false
 		for scanner.Scan() {
-			line := scanner.Text()
-			if strings.HasPrefix(line, key+"=") {
-				lines = append(lines, fmt.Sprintf("%s=%s", key, value))
-				found = true
-			} else {
-				lines = append(lines, line)
-			}
+			lines = append(lines, scanner.Text())
 		}
 		_ = f.Close()
 		if err := scanner.Err(); err != nil {
 			return fmt.Errorf("reading %s: %w", envPath, err)
 		}
-		if !found {
-			lines = append(lines, fmt.Sprintf("%s=%s", key, value))
-		}
-	} else {
-		lines = []string{fmt.Sprintf("%s=%s", key, value)}
 	}
+	lines = edit(lines)
 
 	content := strings.Join(lines, "\n")
 	if !strings.HasSuffix(content, "\n") {
@@ -182,8 +432,6 @@ func updateEnvFile(envPath, key, value string) error {
 		_ = os.Remove(tmpPath)
 		return fmt.Errorf("renaming %s to %s: %w", tmpPath, envPath, err)
 	}
-
-	_ = os.Setenv(key, value)
 	return nil
 }
 
@@ -216,10 +464,24 @@ func RequiredVariables() []string {
 	}
 }
 
-func GetMissingVariables() []string {
+// GetMissingVariables returns the required settings set neither in the
+// environment nor in the configuration file.
+func GetMissingVariables(envPath string) []string {
+	var file *File
+	if path := ResolveConfigFile(envPath); path != "" {
+		file, _ = LoadFile(path)
+	}
+	// A secrets file that cannot be decrypted is reported by NewEnvStore
+	// rather than asking for the token again.
+	secrets, secretsErr := LoadSecrets(ResolveSecretsFile(envPath))
+	store := &EnvStore{envPath: envPath, file: file, secrets: secrets}
+
 	var missing []string
 	for _, k := range RequiredVariables() {
-		if os.Getenv(k) == "" {
+		if secretsErr != nil && IsSecretSetting(k) {
+			continue
+		}
+		if store.lookup(k) == "" {
 			missing = append(missing, k)
 		}
 	}
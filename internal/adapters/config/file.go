@@ -0,0 +1,331 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"streamgogambler/internal/domain/autoresponse"
+)
+
+// configFileNames are looked up next to .env when CONFIG_FILE is not set.
+var configFileNames = []string{"config.yaml", "config.yml", "config.json"}
+
+var ErrInvalidConfig = errors.New("invalid configuration")
+
+// ValidationError lists every problem found in the configuration.
+type ValidationError struct {
+	Problems []error
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	b.WriteString(ErrInvalidConfig.Error())
+	b.WriteString(":")
+	for _, p := range e.Problems {
+		b.WriteString("\n  - ")
+		b.WriteString(p.Error())
+	}
+	return b.String()
+}
+
+func (e *ValidationError) Unwrap() []error {
+	return append([]error{ErrInvalidConfig}, e.Problems...)
+}
+
+// fileSettings is the layout of the configuration file. Every setting with an
+// env tag stands for the environment variable of that name, which overrides
+// it.
+type fileSettings struct {
+	Twitch struct {
+		Username string   `yaml:"username" env:"TWITCH_USERNAME"`
+		OAuth    string   `yaml:"oauth" env:"TWITCH_OAUTH"`
+		Channels []string `yaml:"channels" env:"TWITCH_CHANNEL"`
+		AuthURL  string   `yaml:"auth_url" env:"TWITCH_AUTH_URL"`
+	} `yaml:"twitch"`
+	Commands struct {
+		Prefix      string              `yaml:"prefix" env:"COMMAND_PREFIX"`
+		Status      string              `yaml:"status" env:"STATUS_COMMAND"`
+		Permissions map[string][]string `yaml:"permissions" env:"COMMAND_PERMISSIONS"`
+	} `yaml:"commands"`
+	Chat struct {
+		ConnectMessage   string `yaml:"connect_message" env:"CONNECT_MESSAGE"`
+		GreetOnReconnect *bool  `yaml:"greet_on_reconnect" env:"GREET_ON_RECONNECT"`
+		BandMessage      string `yaml:"band_message" env:"BAND_MESSAGE"`
+		BandOnPerma      *bool  `yaml:"band_on_perma" env:"BAND_ON_PERMA"`
+		Language         string `yaml:"language" env:"BOT_LANGUAGE"`
+		MessagesFile     string `yaml:"messages_file" env:"BOT_MESSAGES"`
+		BucketSize       *int   `yaml:"bucket_size" env:"SAY_BUCKET_SIZE"`
+		RefillMs         *int   `yaml:"refill_ms" env:"SAY_REFILL_MS"`
+	} `yaml:"chat"`
+	BossBot struct {
+		Name    string `yaml:"name" env:"BOSS_BOT_NAME"`
+		ID      string `yaml:"id" env:"BOSS_BOT_ID"`
+		Dialect string `yaml:"dialect" env:"BOSS_BOT_DIALECT"`
+	} `yaml:"boss_bot"`
+	Games struct {
+		HeistAmount         *int   `yaml:"heist_amount" env:"HEIST_AMOUNT"`
+		Strategy            string `yaml:"strategy" env:"BETTING_STRATEGY"`
+		PointsAsDelta       *bool  `yaml:"points_as_delta" env:"POINTS_AS_DELTA"`
+		BalanceSyncInterval *int   `yaml:"balance_sync_interval" env:"BALANCE_SYNC_INTERVAL"`
+	} `yaml:"games"`
+	Costs struct {
+		Slots        *int               `yaml:"slots" env:"SLOTS_COST"`
+		Arena        *int               `yaml:"arena" env:"ARENA_COST"`
+		Boss         *int               `yaml:"boss" env:"BOSS_COST"`
+		SlotsPayouts map[string]float64 `yaml:"slots_payouts" env:"SLOTS_PAYOUTS"`
+	} `yaml:"costs"`
+	Guardrails struct {
+		ReserveFloor *int `yaml:"reserve_floor" env:"RESERVE_FLOOR"`
+		StopLoss     *int `yaml:"stop_loss" env:"STOP_LOSS"`
+		TakeProfit   *int `yaml:"take_profit" env:"TAKE_PROFIT"`
+	} `yaml:"guardrails"`
+	Scheduling struct {
+		AutoSlots         *bool `yaml:"auto_slots" env:"AUTO_SLOTS_ENABLED"`
+		AutoSlotsInterval *int  `yaml:"auto_slots_interval" env:"AUTO_SLOTS_INTERVAL"`
+	} `yaml:"scheduling"`
+	Logging struct {
+		Level          string `yaml:"level" env:"LOG_LEVEL"`
+		TranscriptFile string `yaml:"transcript_file" env:"TRANSCRIPT_FILE"`
+	} `yaml:"logging"`
+	Health struct {
+		Port *int `yaml:"port" env:"HEALTH_PORT"`
+	} `yaml:"health"`
+	GUI struct {
+		Enabled      *bool `yaml:"enabled" env:"GUI_ENABLED"`
+		MaxLogsLines *int  `yaml:"max_logs_lines" env:"MAX_LOGS_LINES"`
+	} `yaml:"gui"`
+
+	AutoResponses []autoresponse.Rule `yaml:"auto_responses"`
+
+	// Channels holds per-channel overrides keyed by channel name, using the
+	// lowercase names of ChannelSettings, e.g. slots_cost.
+	Channels map[string]map[string]any `yaml:"channels"`
+}
+
+// File is a loaded configuration file.
+type File struct {
+	Path string
+	// AutoResponses seed the auto-responses file when it does not exist yet.
+	AutoResponses []autoresponse.Rule
+
+	values map[string]string
+	keys   map[string]string
+}
+
+// ResolveConfigFile returns the configuration file: CONFIG_FILE, relative to
+// the directory of .env, or the first of config.yaml, config.yml and
+// config.json found there. It returns "" when there is none.
+func ResolveConfigFile(envPath string) string {
+	dir := filepath.Dir(envPath)
+	if p := os.Getenv("CONFIG_FILE"); p != "" {
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(dir, p)
+		}
+		return p
+	}
+	for _, name := range configFileNames {
+		p := filepath.Join(dir, name)
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+	}
+	return ""
+}
+
+// LoadFile reads a YAML or JSON configuration file. Unknown settings, values
+// of the wrong type and invalid auto-responses are reported together in a
+// ValidationError; the returned File holds the settings that could be read.
+func LoadFile(path string) (*File, error) {
+	path = filepath.Clean(path)
+	// #nosec G304 -- the configuration file is intentionally user-configurable
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	name := filepath.Base(path)
+	var problems []error
+
+	var settings fileSettings
+	// Settings whose value could not be decoded are left out, so they are
+	// reported once instead of again as a zero value.
+	badLines := make(map[int]bool)
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&settings); err != nil && !errors.Is(err, io.EOF) {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+		for _, msg := range typeErr.Errors {
+			if m := lineRe.FindStringSubmatch(msg); m != nil {
+				line, _ := strconv.Atoi(m[1])
+				badLines[line] = true
+			}
+			problems = append(problems, fmt.Errorf("%s %s", name, readableYAMLError(msg)))
+		}
+	}
+
+	f := &File{Path: path, values: make(map[string]string), keys: make(map[string]string)}
+	f.collect(reflect.ValueOf(settings), "")
+	if len(badLines) > 0 {
+		var root yaml.Node
+		if err := yaml.Unmarshal(data, &root); err == nil {
+			f.dropLines(&root, "", badLines)
+		}
+	}
+
+	for _, channel := range slices.Sorted(maps.Keys(settings.Channels)) {
+		for _, setting := range slices.Sorted(maps.Keys(settings.Channels[channel])) {
+			key := "channels." + channel + "." + setting
+			if !slices.Contains(ChannelSettings, strings.ToUpper(setting)) {
+				problems = append(problems, fmt.Errorf("%s in %s: unknown channel setting, expected one of %s",
+					key, name, strings.ToLower(strings.Join(ChannelSettings, ", "))))
+				continue
+			}
+			value := settings.Channels[channel][setting]
+			if value == nil {
+				continue
+			}
+			f.set(ChannelEnvKey(channel, strings.ToUpper(setting)), fmt.Sprint(value), key)
+		}
+	}
+
+	if settings.AutoResponses != nil {
+		set := autoresponse.NewSet(nil)
+		for i, r := range settings.AutoResponses {
+			if err := set.Add(r); err != nil {
+				problems = append(problems, fmt.Errorf("auto_responses[%d] in %s: %w", i, name, err))
+			}
+		}
+		f.AutoResponses = set.Rules()
+	}
+
+	if len(problems) > 0 {
+		return f, &ValidationError{Problems: problems}
+	}
+	return f, nil
+}
+
+// collect flattens the settings into environment variable values.
+func (f *File) collect(v reflect.Value, path string) {
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		yamlName, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		key := strings.TrimPrefix(path+"."+yamlName, ".")
+		value := v.Field(i)
+
+		env := field.Tag.Get("env")
+		if env == "" {
+			if value.Kind() == reflect.Struct {
+				f.collect(value, key)
+			}
+			continue
+		}
+		if s, ok := formatSetting(value); ok {
+			f.set(env, s, key)
+		}
+	}
+}
+
+// dropLines removes the settings whose value is on one of the given lines.
+func (f *File) dropLines(node *yaml.Node, path string, lines map[int]bool) {
+	if node.Kind == yaml.DocumentNode {
+		for _, child := range node.Content {
+			f.dropLines(child, path, lines)
+		}
+		return
+	}
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := strings.TrimPrefix(path+"."+node.Content[i].Value, ".")
+		value := node.Content[i+1]
+		if lines[value.Line] {
+			for env, k := range f.keys {
+				if k == key {
+					delete(f.values, env)
+					delete(f.keys, env)
+				}
+			}
+		}
+		f.dropLines(value, key, lines)
+	}
+}
+
+func (f *File) set(env, value, key string) {
+	f.values[env] = value
+	f.keys[env] = key
+}
+
+// formatSetting writes a setting the way its environment variable spells it.
+func formatSetting(v reflect.Value) (string, bool) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return "", false
+		}
+		return fmt.Sprint(v.Elem().Interface()), true
+	case reflect.String:
+		return v.String(), v.String() != ""
+	case reflect.Slice:
+		if v.Len() == 0 {
+			return "", false
+		}
+		items, _ := v.Interface().([]string)
+		return strings.Join(items, ","), true
+	case reflect.Map:
+		if v.Len() == 0 {
+			return "", false
+		}
+		var pairs []string
+		switch m := v.Interface().(type) {
+		case map[string]float64:
+			for _, k := range slices.Sorted(maps.Keys(m)) {
+				pairs = append(pairs, k+"="+strconv.FormatFloat(m[k], 'f', -1, 64))
+			}
+		case map[string][]string:
+			for _, k := range slices.Sorted(maps.Keys(m)) {
+				pairs = append(pairs, k+"="+strings.Join(m[k], "|"))
+			}
+		}
+		return strings.Join(pairs, ","), true
+	}
+	return "", false
+}
+
+// Lookup returns the value of an environment variable set in the file and the
+// file entry it came from, e.g. games.heist_amount for HEIST_AMOUNT.
+func (f *File) Lookup(env string) (value, key string, ok bool) {
+	if f == nil {
+		return "", "", false
+	}
+	value, ok = f.values[env]
+	return value, f.keys[env], ok
+}
+
+var (
+	lineRe         = regexp.MustCompile(`^line (\d+):`)
+	unknownFieldRe = regexp.MustCompile(`field (\S+) not found in type .*`)
+	wrongTypeRe    = regexp.MustCompile("cannot unmarshal !!\\w+ `(.*)` into \\*?(\\S+)")
+)
+
+// readableYAMLError rewords yaml.v3 decoding errors without Go type names.
+func readableYAMLError(msg string) string {
+	msg = unknownFieldRe.ReplaceAllString(msg, "unknown setting $1")
+	return wrongTypeRe.ReplaceAllString(msg, `"$1" is not a valid $2`)
+}
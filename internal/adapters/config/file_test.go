@@ -0,0 +1,162 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"streamgogambler/internal/domain/autoresponse"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600), "writing %s", name)
+	return filepath.Join(dir, ".env")
+}
+
+// clearRequiredEnv unsets the required variables so they are read from the
+// configuration file.
+func clearRequiredEnv(t *testing.T) {
+	t.Helper()
+	for _, key := range RequiredVariables() {
+		t.Setenv(key, "")
+	}
+	t.Setenv("CONFIG_FILE", "")
+}
+
+const testConfigFile = `
+twitch:
+  username: filebot
+  oauth: token
+  channels: [foo, bar]
+commands:
+  prefix: "!"
+  status: status
+  permissions:
+    autoslots: [moderator, trusted]
+chat:
+  connect_message: "!pyk"
+  language: en
+boss_bot:
+  name: demonzzbot
+games:
+  heist_amount: 2500
+  strategy: percent:5
+costs:
+  slots: 3000
+  slots_payouts:
+    jackpot: 8
+guardrails:
+  stop_loss: 10000
+scheduling:
+  auto_slots: true
+  auto_slots_interval: 20
+auto_responses:
+  - trigger: Raffle!
+    response: "!join"
+    cooldown_seconds: 60
+channels:
+  bar:
+    slots_cost: 5000
+    auto_slots_enabled: false
+`
+
+func TestConfigFileSettings(t *testing.T) {
+	clearRequiredEnv(t)
+	t.Setenv("SLOTS_COST", "")
+	t.Setenv("HEIST_AMOUNT", "1500")
+	envPath := writeConfigFile(t, "config.yaml", testConfigFile)
+
+	store, err := NewEnvStore(envPath)
+	require.NoError(t, err)
+
+	cfg := store.GetConfig()
+	assert.Equal(t, "filebot", cfg.Username)
+	assert.Equal(t, "token", store.GetOAuth())
+	assert.Equal(t, []string{"foo", "bar"}, cfg.Channels)
+	assert.Equal(t, "autoslots=moderator|trusted", cfg.CommandPermissions)
+	assert.Equal(t, "en", cfg.Language)
+	assert.Equal(t, 1500, cfg.DefaultHeist, "environment overrides the file")
+	assert.Equal(t, "percent:5", cfg.BettingStrategy)
+	assert.Equal(t, 3000, cfg.SlotsCost)
+	assert.InDelta(t, 8.0, cfg.SlotsPayouts["jackpot"], 0.001)
+	assert.Equal(t, 10000, cfg.StopLoss)
+	assert.True(t, cfg.AutoSlotsEnabled)
+	assert.Equal(t, 20, cfg.AutoSlotsInterval)
+
+	bar := store.ForChannel("bar").GetConfig()
+	assert.Equal(t, 5000, bar.SlotsCost, "channel override from the file")
+	assert.False(t, bar.AutoSlotsEnabled)
+
+	assert.Equal(t, []autoresponse.Rule{{Trigger: "Raffle!", Response: "!join", CooldownSeconds: 60}}, store.File().AutoResponses)
+	assert.Empty(t, GetMissingVariables(envPath), "required settings found in the file")
+}
+
+func TestConfigFileFromConfigFileVariable(t *testing.T) {
+	clearRequiredEnv(t)
+	envPath := writeConfigFile(t, "bot.json", `{"twitch": {"username": "jsonbot", "oauth": "token", "channels": ["foo"]},
+		"commands": {"prefix": "!", "status": "status"}, "chat": {"connect_message": "hi"}, "boss_bot": {"name": "boss"}}`)
+	t.Setenv("CONFIG_FILE", "bot.json")
+
+	store, err := NewEnvStore(envPath)
+	require.NoError(t, err)
+	assert.Equal(t, "jsonbot", store.GetConfig().Username)
+}
+
+func TestConfigReportsEveryProblem(t *testing.T) {
+	setRequiredEnv(t, "foo")
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("HEIST_AMOUNT", "1OOO")
+	t.Setenv("SLOTS_COST", "-5")
+	t.Setenv("AUTO_SLOTS_ENABLED", "yes")
+	t.Setenv("HEALTH_PORT", "70000")
+	t.Setenv("TWITCH_AUTH_URL", "id.twitch.tv")
+	envPath := writeConfigFile(t, "config.yaml", `
+games:
+  strategy: double
+  heist_amount: lots
+costs:
+  arena: 0
+scheduling:
+  auto_slot: true
+auto_responses:
+  - trigger: Raffle!
+channels:
+  foo:
+    slots: 1
+`)
+
+	_, err := NewEnvStore(envPath)
+	require.ErrorIs(t, err, ErrInvalidConfig)
+
+	var invalid *ValidationError
+	require.ErrorAs(t, err, &invalid)
+	for _, want := range []string{
+		`config.yaml line 4: "lots" is not a valid int`,
+		"config.yaml line 8: unknown setting auto_slot",
+		"auto_responses[0] in config.yaml: invalid auto-response: empty response",
+		"channels.foo.slots in config.yaml: unknown channel setting",
+		`HEIST_AMOUNT: "1OOO" is not a whole number`,
+		"SLOTS_COST: -5 is below the minimum of 0",
+		"games.strategy in config.yaml: ",
+		`AUTO_SLOTS_ENABLED: "yes" is not true or false`,
+		"HEALTH_PORT: 70000 is outside 0..65535",
+		`TWITCH_AUTH_URL: "id.twitch.tv" is not an http(s) URL`,
+	} {
+		assert.ErrorContains(t, err, want)
+	}
+	assert.Len(t, invalid.Problems, 10, "every problem is listed once")
+}
+
+func TestConfigFileRejectsMalformedYAML(t *testing.T) {
+	setRequiredEnv(t, "foo")
+	t.Setenv("CONFIG_FILE", "")
+	envPath := writeConfigFile(t, "config.yaml", "games: [")
+
+	_, err := NewEnvStore(envPath)
+	assert.ErrorContains(t, err, "parsing")
+}
@@ -0,0 +1,295 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+// SecretsFileName is the encrypted secrets file looked up next to .env when
+// SECRETS_FILE is not set.
+const SecretsFileName = "secrets.enc"
+
+const (
+	secretsVersion = 1
+	kdfIterations  = 600_000
+
+	keyFromPassphrase = "passphrase"
+	keyFromMachine    = "machine"
+)
+
+var ErrSecretsKey = errors.New("cannot decrypt secrets: wrong passphrase or machine key")
+
+// secretsFile is the layout of the secrets file. The secrets are a JSON object
+// of environment variables, encrypted with AES-256-GCM under a key derived
+// with PBKDF2-SHA256 from SECRETS_PASSPHRASE or the machine key.
+type secretsFile struct {
+	Version    int    `json:"version"`
+	Key        string `json:"key"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// ResolveSecretsFile returns the secrets file: SECRETS_FILE, relative to the
+// directory of .env, or secrets.enc next to .env.
+func ResolveSecretsFile(envPath string) string {
+	dir := filepath.Dir(envPath)
+	if p := os.Getenv("SECRETS_FILE"); p != "" {
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(dir, p)
+		}
+		return p
+	}
+	return filepath.Join(dir, SecretsFileName)
+}
+
+// IsSecretSetting reports whether a setting is kept in the secrets file: the
+// OAuth tokens of the primary and the additional accounts.
+func IsSecretSetting(key string) bool {
+	return key == "TWITCH_OAUTH" ||
+		strings.HasPrefix(key, "ACCOUNT_") && strings.HasSuffix(key, "_TWITCH_OAUTH")
+}
+
+// LoadSecrets decrypts the secrets file. A missing file holds no secrets.
+func LoadSecrets(path string) (map[string]string, error) {
+	path = filepath.Clean(path)
+	// #nosec G304 -- the secrets file is intentionally user-configurable
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	var f secretsFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if f.Version != secretsVersion {
+		return nil, fmt.Errorf("%s: unsupported version %d", path, f.Version)
+	}
+
+	secret, err := secretsKeyMaterial(f.Key, false)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	aead, err := secretsCipher(secret, f.Salt, f.Iterations)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(f.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("%s: invalid nonce", path)
+	}
+	plain, err := aead.Open(nil, f.Nonce, f.Ciphertext, []byte(f.Key))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, ErrSecretsKey)
+	}
+
+	secrets := make(map[string]string)
+	if err := json.Unmarshal(plain, &secrets); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return secrets, nil
+}
+
+// SaveSecrets encrypts the secrets into path with a new salt and nonce, using
+// SECRETS_PASSPHRASE when it is set and the machine key otherwise.
+func SaveSecrets(path string, secrets map[string]string) error {
+	key := keyFromMachine
+	if os.Getenv("SECRETS_PASSPHRASE") != "" {
+		key = keyFromPassphrase
+	}
+	secret, err := secretsKeyMaterial(key, true)
+	if err != nil {
+		return err
+	}
+
+	f := secretsFile{Version: secretsVersion, Key: key, Iterations: kdfIterations,
+		Salt: make([]byte, 16)}
+	if _, err := rand.Read(f.Salt); err != nil {
+		return fmt.Errorf("generating salt: %w", err)
+	}
+	aead, err := secretsCipher(secret, f.Salt, f.Iterations)
+	if err != nil {
+		return err
+	}
+	f.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(f.Nonce); err != nil {
+		return fmt.Errorf("generating nonce: %w", err)
+	}
+
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	f.Ciphertext = aead.Seal(nil, f.Nonce, plain, []byte(f.Key))
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeSecretsFile(path, data)
+}
+
+// SetSecret saves a secret setting to the secrets file and removes its plain
+// text value from .env.
+func SetSecret(envPath, key, value string) error {
+	path := ResolveSecretsFile(envPath)
+	secrets, err := LoadSecrets(path)
+	if err != nil {
+		return err
+	}
+	secrets[key] = value
+	if err := SaveSecrets(path, secrets); err != nil {
+		return err
+	}
+	return removePlainSecrets(envPath, key)
+}
+
+// MigrateSecrets moves the OAuth tokens written in .env into the secrets file
+// and returns the moved settings.
+func MigrateSecrets(envPath string) ([]string, error) {
+	values, err := godotenv.Read(envPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", envPath, err)
+	}
+
+	path := ResolveSecretsFile(envPath)
+	secrets, err := LoadSecrets(path)
+	if err != nil {
+		return nil, err
+	}
+	var moved []string
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		if IsSecretSetting(key) && values[key] != "" {
+			secrets[key] = values[key]
+			moved = append(moved, key)
+		}
+	}
+	if len(moved) == 0 {
+		return nil, nil
+	}
+
+	if err := SaveSecrets(path, secrets); err != nil {
+		return nil, err
+	}
+	return moved, removePlainSecrets(envPath, moved...)
+}
+
+// removePlainSecrets deletes secret settings from .env, if it has them.
+func removePlainSecrets(envPath string, keys ...string) error {
+	values, err := godotenv.Read(envPath)
+	if err != nil {
+		return nil
+	}
+	if !slices.ContainsFunc(keys, func(key string) bool { _, ok := values[key]; return ok }) {
+		return nil
+	}
+	return removeFromEnvFile(envPath, keys...)
+}
+
+func secretsCipher(secret, salt []byte, iterations int) (cipher.AEAD, error) {
+	if len(salt) == 0 || iterations <= 0 {
+		return nil, errors.New("invalid key derivation parameters")
+	}
+	key, err := pbkdf2.Key(sha256.New, string(secret), salt, iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// secretsKeyMaterial returns SECRETS_PASSPHRASE or the machine key, creating
+// the machine key when create is set.
+func secretsKeyMaterial(key string, create bool) ([]byte, error) {
+	switch key {
+	case keyFromPassphrase:
+		passphrase := os.Getenv("SECRETS_PASSPHRASE")
+		if passphrase == "" {
+			return nil, errors.New("encrypted with a passphrase, but SECRETS_PASSPHRASE is not set")
+		}
+		return []byte(passphrase), nil
+	case keyFromMachine:
+		return machineKey(create)
+	}
+	return nil, fmt.Errorf("unknown key %q", key)
+}
+
+// machineKey reads the random key kept in the user's configuration
+// directory, outside the bot's folder, so a copied folder cannot be
+// decrypted elsewhere.
+func machineKey(create bool) ([]byte, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("locating the machine key: %w", err)
+	}
+	path := filepath.Join(dir, "StreamGoGambler", "machine.key")
+
+	// #nosec G304 -- path is in the user's configuration directory
+	key, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		return key, nil
+	case !errors.Is(err, os.ErrNotExist) || !create:
+		return nil, fmt.Errorf("reading the machine key: %w", err)
+	}
+
+	key = make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("generating the machine key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("creating %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, key, 0600); err != nil {
+		return nil, fmt.Errorf("writing the machine key: %w", err)
+	}
+	return key, nil
+}
+
+// writeSecretsFile replaces path through a temporary file, which is created
+// readable by the owner only.
+func writeSecretsFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fmt.Errorf("creating directory %s: %w", dir, err)
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("creating temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("writing to temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("closing temp file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("renaming %s to %s: %w", tmpPath, path, err)
+	}
+	return nil
+}
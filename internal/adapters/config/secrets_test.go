@@ -0,0 +1,131 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"streamgogambler/internal/ports"
+)
+
+// useTestSecrets keeps the machine key and the secrets passphrase of the
+// tests away from the user's own.
+func useTestSecrets(t *testing.T, passphrase string) {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("APPDATA", t.TempDir())
+	t.Setenv("SECRETS_PASSPHRASE", passphrase)
+	t.Setenv("SECRETS_FILE", "")
+}
+
+func TestSecretsRoundTrip(t *testing.T) {
+	tests := []struct {
+		name       string
+		passphrase string
+	}{
+		{"machine key", ""},
+		{"passphrase", "correct horse"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestSecrets(t, tt.passphrase)
+			path := filepath.Join(t.TempDir(), SecretsFileName)
+			secrets := map[string]string{"TWITCH_OAUTH": "token", "ACCOUNT_ALT1_TWITCH_OAUTH": "alt"}
+
+			require.NoError(t, SaveSecrets(path, secrets))
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.NotContains(t, string(data), "token", "secrets are encrypted")
+
+			loaded, err := LoadSecrets(path)
+			require.NoError(t, err)
+			assert.Equal(t, secrets, loaded)
+		})
+	}
+}
+
+func TestLoadSecretsRejectsWrongKey(t *testing.T) {
+	useTestSecrets(t, "correct horse")
+	path := filepath.Join(t.TempDir(), SecretsFileName)
+	require.NoError(t, SaveSecrets(path, map[string]string{"TWITCH_OAUTH": "token"}))
+
+	t.Setenv("SECRETS_PASSPHRASE", "battery staple")
+	_, err := LoadSecrets(path)
+	require.ErrorIs(t, err, ErrSecretsKey)
+
+	t.Setenv("SECRETS_PASSPHRASE", "")
+	_, err = LoadSecrets(path)
+	require.ErrorContains(t, err, "SECRETS_PASSPHRASE is not set")
+
+	secrets, err := LoadSecrets(filepath.Join(t.TempDir(), SecretsFileName))
+	require.NoError(t, err, "missing file")
+	assert.Empty(t, secrets)
+}
+
+func TestMigrateSecretsMovesTokensOutOfEnvFile(t *testing.T) {
+	useTestSecrets(t, "")
+	setRequiredEnv(t, "foo")
+	t.Setenv("TWITCH_ACCOUNTS", "alt1")
+	envPath := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(envPath, []byte("TWITCH_OAUTH=token\nACCOUNT_ALT1_TWITCH_OAUTH=alt\nSLOTS_COST=3000\n"), 0600))
+	require.NoError(t, os.Unsetenv("TWITCH_OAUTH"))
+
+	moved, err := MigrateSecrets(envPath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ACCOUNT_ALT1_TWITCH_OAUTH", "TWITCH_OAUTH"}, moved)
+
+	data, err := os.ReadFile(envPath)
+	require.NoError(t, err)
+	assert.Equal(t, "SLOTS_COST=3000\n", string(data), "tokens removed from .env")
+
+	store, err := NewEnvStore(envPath)
+	require.NoError(t, err)
+	assert.Equal(t, "token", store.GetOAuth(), "token read from the secrets file")
+	require.Len(t, store.Accounts(), 1)
+	assert.Equal(t, "alt", store.Accounts()[0].GetOAuth())
+	assert.Empty(t, GetMissingVariables(envPath))
+
+	require.NoError(t, SetSecret(envPath, "TWITCH_OAUTH", "rotated"))
+	ignored, err := store.Reload()
+	require.NoError(t, err)
+	assert.Contains(t, ignored, "TWITCH_OAUTH", "a new token needs a restart")
+
+	again, err := NewEnvStore(envPath)
+	require.NoError(t, err)
+	assert.Equal(t, "rotated", again.GetOAuth())
+
+	moved, err = MigrateSecrets(envPath)
+	require.NoError(t, err)
+	assert.Empty(t, moved, "nothing left to migrate")
+}
+
+func TestSaveOAuthKeepsTokenWhereItWas(t *testing.T) {
+	useTestSecrets(t, "")
+	setRequiredEnv(t, "foo")
+	envPath := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(envPath, []byte("TWITCH_OAUTH=token\n"), 0600))
+
+	store, err := NewEnvStore(envPath)
+	require.NoError(t, err)
+	require.NoError(t, store.SaveOAuth(" oauth:fresh "))
+	assert.Equal(t, "fresh", store.GetOAuth(), "used for the next connection")
+	data, err := os.ReadFile(envPath)
+	require.NoError(t, err)
+	assert.Equal(t, "TWITCH_OAUTH=fresh\n", string(data), "plain text token stays in .env")
+
+	_, err = MigrateSecrets(envPath)
+	require.NoError(t, err)
+	store, err = NewEnvStore(envPath)
+	require.NoError(t, err)
+	require.NoError(t, store.SaveOAuth("newer"))
+	secrets, err := LoadSecrets(ResolveSecretsFile(envPath))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"TWITCH_OAUTH": "newer"}, secrets, "encrypted token stays encrypted")
+
+	require.ErrorIs(t, store.SaveOAuth(" "), ports.ErrInvalidSetting)
+}
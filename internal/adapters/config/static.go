@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	"streamgogambler/internal/ports"
+)
+
+// StaticStore is an in-memory ports.ConfigStore for offline runs such as
+// simulations and replays; updates are not persisted.
+type StaticStore struct {
+	mu            sync.Mutex
+	config        ports.BotConfig
+	subscriptions map[int]func(old, updated ports.BotConfig)
+	next          int
+}
+
+func NewStaticStore(config ports.BotConfig) *StaticStore {
+	return &StaticStore{config: config}
+}
+
+func (s *StaticStore) GetConfig() ports.BotConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.config
+}
+
+func (s *StaticStore) GetOAuth() string {
+	return ""
+}
+
+func (s *StaticStore) UpdateHeist(amount int) error {
+	return s.Set(ports.SettingHeistAmount, strconv.Itoa(amount))
+}
+
+// Set validates a mutable setting and applies it without saving it.
+func (s *StaticStore) Set(key ports.Setting, value string) error {
+	value, err := normalizeSetting(key, value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	config := s.GetConfig()
+	setField(&config, key, value)
+	s.Update(config)
+	return nil
+}
+
+// Update replaces the configuration and notifies the subscribers.
+func (s *StaticStore) Update(config ports.BotConfig) {
+	s.mu.Lock()
+	old := s.config
+	s.config = config
+	handlers := make([]func(old, updated ports.BotConfig), 0, len(s.subscriptions))
+	for _, handler := range s.subscriptions {
+		handlers = append(handlers, handler)
+	}
+	s.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(old, config)
+	}
+}
+
+func (s *StaticStore) Subscribe(handler func(old, updated ports.BotConfig)) func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.subscriptions == nil {
+		s.subscriptions = make(map[int]func(old, updated ports.BotConfig))
+	}
+	id := s.next
+	s.next++
+	s.subscriptions[id] = handler
+
+	return func() {
+		s.mu.Lock()
+		delete(s.subscriptions, id)
+		s.mu.Unlock()
+	}
+}
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"streamgogambler/internal/domain/gambling"
+	"streamgogambler/internal/ports"
+)
+
+var logLevels = []string{"debug", "info", "warn", "warning", "error"}
+
+// intLimits bounds the integer settings; per-channel overrides share the
+// limits of their setting.
+var intLimits = map[string]struct{ min, max int }{
+	"HEIST_AMOUNT":          {1, gambling.MaxHeistAmount},
+	"SLOTS_COST":            {0, math.MaxInt},
+	"ARENA_COST":            {0, math.MaxInt},
+	"BOSS_COST":             {0, math.MaxInt},
+	"RESERVE_FLOOR":         {0, math.MaxInt},
+	"STOP_LOSS":             {0, math.MaxInt},
+	"TAKE_PROFIT":           {0, math.MaxInt},
+	"AUTO_SLOTS_INTERVAL":   {1, math.MaxInt},
+	"BALANCE_SYNC_INTERVAL": {0, math.MaxInt},
+	"SAY_BUCKET_SIZE":       {1, math.MaxInt},
+	"SAY_REFILL_MS":         {1, math.MaxInt},
+	"HEALTH_PORT":           {0, 65535},
+	"MAX_LOGS_LINES":        {1, math.MaxInt},
+}
+
+// problems collects the configuration errors of one load so they are all
+// reported together.
+type problems struct {
+	store *EnvStore
+	errs  []error
+}
+
+// add records a problem with a setting, naming where its value came from.
+func (p *problems) add(key string, err error) {
+	_, origin := p.store.setting(key)
+	p.errs = append(p.errs, fmt.Errorf("%s: %w", origin, err))
+}
+
+func (p *problems) int(key string, def int) int {
+	return p.limitedInt(key, key, def)
+}
+
+// limitedInt reads an integer setting and checks it against the limits of
+// setting, e.g. SLOTS_COST for CHANNEL_FOO_SLOTS_COST. Invalid values keep
+// def.
+func (p *problems) limitedInt(key, setting string, def int) int {
+	value := strings.TrimSpace(p.store.lookup(key))
+	if value == "" {
+		return def
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		p.add(key, errNotWholeNumber(value))
+		return def
+	}
+	if err := checkLimits(setting, n); err != nil {
+		p.add(key, err)
+	}
+	return n
+}
+
+func errNotWholeNumber(value string) error {
+	return fmt.Errorf("%q is not a whole number", value)
+}
+
+// checkLimits checks n against the limits of setting.
+func checkLimits(setting string, n int) error {
+	limits := intLimits[setting]
+	switch {
+	case limits.max == math.MaxInt && n < limits.min:
+		return fmt.Errorf("%d is below the minimum of %d", n, limits.min)
+	case n < limits.min || n > limits.max:
+		return fmt.Errorf("%d is outside %d..%d", n, limits.min, limits.max)
+	}
+	return nil
+}
+
+func parseBool(value string) (bool, error) {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%q is not true or false", value)
+	}
+	return b, nil
+}
+
+// normalizeSetting validates a value for one of ports.MutableSettings and
+// returns it the way it is saved.
+func normalizeSetting(key ports.Setting, value string) (string, error) {
+	if !slices.Contains(ports.MutableSettings, key) {
+		return "", ports.ErrUnknownSetting
+	}
+	value = strings.TrimSpace(value)
+	var err error
+	switch key.Kind() {
+	case ports.KindInt:
+		n, convErr := strconv.Atoi(value)
+		if convErr != nil {
+			err = errNotWholeNumber(value)
+			break
+		}
+		err = checkLimits(string(key), n)
+		value = strconv.Itoa(n)
+	case ports.KindBool:
+		var b bool
+		b, err = parseBool(value)
+		value = strconv.FormatBool(b)
+	case ports.KindText:
+		if value == "" || strings.ContainsAny(value, "\r\n") {
+			err = errors.New("must be a single non-empty line")
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ports.ErrInvalidSetting, err)
+	}
+	return value, nil
+}
+
+// bool reads a boolean setting; invalid values keep def.
+func (p *problems) bool(key string, def bool) bool {
+	value := strings.TrimSpace(p.store.lookup(key))
+	if value == "" {
+		return def
+	}
+
+	b, err := parseBool(value)
+	if err != nil {
+		p.add(key, err)
+		return def
+	}
+	return b
+}
+
+// setField stores a value returned by normalizeSetting in cfg.
+func setField(cfg *ports.BotConfig, key ports.Setting, value string) {
+	n, _ := strconv.Atoi(value)
+	b, _ := strconv.ParseBool(value)
+	switch key {
+	case ports.SettingHeistAmount:
+		cfg.DefaultHeist = n
+	case ports.SettingSlotsCost:
+		cfg.SlotsCost = n
+	case ports.SettingArenaCost:
+		cfg.ArenaCost = n
+	case ports.SettingBossCost:
+		cfg.BossCost = n
+	case ports.SettingReserveFloor:
+		cfg.ReserveFloor = n
+	case ports.SettingStopLoss:
+		cfg.StopLoss = n
+	case ports.SettingTakeProfit:
+		cfg.TakeProfit = n
+	case ports.SettingAutoSlotsEnabled:
+		cfg.AutoSlotsEnabled = b
+	case ports.SettingAutoSlotsInterval:
+		cfg.AutoSlotsInterval = n
+	case ports.SettingBalanceSyncInterval:
+		cfg.BalanceSyncInterval = n
+	case ports.SettingConnectMessage:
+		cfg.ConnectMessage = value
+	case ports.SettingGreetOnReconnect:
+		cfg.GreetOnReconnect = b
+	case ports.SettingBandMessage:
+		cfg.BandMessage = value
+	case ports.SettingBandOnPerma:
+		cfg.BandOnPerma = b
+	case ports.SettingPointsAsDelta:
+		cfg.PointsAsDelta = b
+	}
+}
@@ -0,0 +1,247 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"streamgogambler/internal/adapters/logging"
+	"streamgogambler/internal/ports"
+)
+
+// ReloadInterval is how often Watch checks .env and the configuration file
+// for changes.
+const ReloadInterval = 2 * time.Second
+
+// restartSettings need a reconnect or restart to take effect: the chat
+// connection, the joined channels and what is resolved on startup. Reloads
+// keep their old value.
+var restartSettings = []struct {
+	key   string
+	field string
+}{
+	{"TWITCH_USERNAME", "Username"},
+	{"TWITCH_AUTH_URL", "AuthURL"},
+	{"TWITCH_CHANNEL", "Channels"},
+	{"SAY_BUCKET_SIZE", "SayBucketSize"},
+	{"SAY_REFILL_MS", "SayRefillMs"},
+	{"BOSS_BOT_DIALECT", "BossBotDialect"},
+	{"BOT_LANGUAGE", "Language"},
+	{"BOT_MESSAGES", "MessagesFile"},
+	{"HEALTH_PORT", "HealthPort"},
+	{"TRANSCRIPT_FILE", "TranscriptFile"},
+	{"GUI_ENABLED", "GUIEnabled"},
+	{"MAX_LOGS_LINES", "MaxLogsLines"},
+}
+
+type subscription struct {
+	// channel is empty for subscribers of the account's configuration.
+	channel string
+	handler func(old, updated ports.BotConfig)
+}
+
+// Subscribe calls handler after every reload that changes the configuration.
+func (s *EnvStore) Subscribe(handler func(old, updated ports.BotConfig)) func() {
+	return s.subscribe("", handler)
+}
+
+func (s *EnvStore) subscribe(channel string, handler func(old, updated ports.BotConfig)) func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.subscriptions == nil {
+		s.subscriptions = make(map[int]subscription)
+	}
+	id := s.nextSubscription
+	s.nextSubscription++
+	s.subscriptions[id] = subscription{channel: channel, handler: handler}
+
+	return func() {
+		s.mu.Lock()
+		delete(s.subscriptions, id)
+		s.mu.Unlock()
+	}
+}
+
+// Watch reloads the configuration whenever .env, the configuration file or
+// the secrets file changes, checking every interval until ctx is done.
+func (s *EnvStore) Watch(ctx context.Context, interval time.Duration, logger *logging.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	stamp := s.sourceStamp()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current := s.sourceStamp()
+		if current == stamp {
+			continue
+		}
+		stamp = current
+
+		ignored, err := s.Reload()
+		if err != nil {
+			logger.Errorf(ctx, "Configuration not reloaded, keeping the previous one: %v", err)
+			continue
+		}
+		logger.Infof(ctx, "Configuration reloaded")
+		if len(ignored) > 0 {
+			logger.Warnf(ctx, "Changes to %s need a restart and were not applied", strings.Join(ignored, ", "))
+		}
+	}
+}
+
+// sourceStamp identifies the current version of .env, the configuration file
+// and the secrets file.
+func (s *EnvStore) sourceStamp() string {
+	var b strings.Builder
+	for _, path := range []string{s.envPath, ResolveConfigFile(s.envPath), ResolveSecretsFile(s.envPath)} {
+		if info, err := os.Stat(path); err == nil {
+			fmt.Fprintf(&b, "%s:%d:%d;", path, info.ModTime().UnixNano(), info.Size())
+		}
+	}
+	return b.String()
+}
+
+// Reload reads .env, the configuration file and the secrets file again and
+// applies the new configuration, notifying the subscribers. Settings that
+// need a restart keep their old value and are returned. An invalid
+// configuration is not applied.
+func (s *EnvStore) Reload() (ignored []string, err error) {
+	s.reloadEnvFile()
+
+	fresh, err := NewEnvStore(s.envPath)
+	if err != nil {
+		return nil, err
+	}
+
+	restart := make(map[string]bool)
+	s.apply(fresh, restart)
+	for _, account := range s.accounts {
+		i := slices.IndexFunc(fresh.accounts, func(a *EnvStore) bool { return a.account == account.account })
+		if i < 0 {
+			restart["TWITCH_ACCOUNTS"] = true
+			continue
+		}
+		accountRestart := make(map[string]bool)
+		account.apply(fresh.accounts[i], accountRestart)
+		for key := range accountRestart {
+			restart[account.prefix+key] = true
+		}
+	}
+	if len(fresh.accounts) != len(s.accounts) {
+		restart["TWITCH_ACCOUNTS"] = true
+	}
+	return slices.Sorted(maps.Keys(restart)), nil
+}
+
+// apply takes over the configuration of fresh, keeping the restart settings,
+// and notifies the subscribers whose configuration changed.
+func (s *EnvStore) apply(fresh *EnvStore, restart map[string]bool) {
+	s.mu.Lock()
+	old, oldChannels := s.config, s.channels
+
+	updated := fresh.config
+	keepRestartSettings(old, &updated, restart)
+	channels := make(map[string]ports.BotConfig, len(oldChannels))
+	for channel, cfg := range oldChannels {
+		next, ok := fresh.channels[channel]
+		if !ok {
+			next = updated
+			next.Channel = channel
+		}
+		keepRestartSettings(cfg, &next, restart)
+		channels[channel] = next
+	}
+	if fresh.oauth != s.oauth {
+		restart["TWITCH_OAUTH"] = true
+	}
+
+	s.config, s.channels, s.file, s.secrets = updated, channels, fresh.file, fresh.secrets
+	subscriptions := slices.Collect(maps.Values(s.subscriptions))
+	s.mu.Unlock()
+
+	for _, sub := range subscriptions {
+		before, after := old, updated
+		if sub.channel != "" {
+			before, after = channelOf(old, oldChannels, sub.channel), channelOf(updated, channels, sub.channel)
+		}
+		if !reflect.DeepEqual(before, after) {
+			sub.handler(before, after)
+		}
+	}
+}
+
+func channelOf(base ports.BotConfig, channels map[string]ports.BotConfig, channel string) ports.BotConfig {
+	if cfg, ok := channels[channel]; ok {
+		return cfg
+	}
+	base.Channel = channel
+	return base
+}
+
+// keepRestartSettings copies the restart settings of old into updated and
+// records the ones that differed.
+func keepRestartSettings(old ports.BotConfig, updated *ports.BotConfig, restart map[string]bool) {
+	oldValue := reflect.ValueOf(old)
+	updatedValue := reflect.ValueOf(updated).Elem()
+	for _, setting := range restartSettings {
+		before, after := oldValue.FieldByName(setting.field), updatedValue.FieldByName(setting.field)
+		if !reflect.DeepEqual(before.Interface(), after.Interface()) {
+			restart[setting.key] = true
+			after.Set(before)
+		}
+	}
+	updated.Channel = old.Channel
+}
+
+// readEnvFile returns the variables of .env that are not overridden by the
+// environment.
+func readEnvFile(envPath string) map[string]string {
+	values, err := godotenv.Read(envPath)
+	if err != nil {
+		return map[string]string{}
+	}
+	for key, value := range values {
+		if current, ok := os.LookupEnv(key); ok && current != value {
+			delete(values, key)
+		}
+	}
+	return values
+}
+
+// reloadEnvFile updates the environment with the current content of .env.
+// Variables set outside .env are left alone.
+func (s *EnvStore) reloadEnvFile() {
+	values, err := godotenv.Read(s.envPath)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, value := range values {
+		current, set := os.LookupEnv(key)
+		if _, fromFile := s.dotenv[key]; fromFile || !set || current == value {
+			_ = os.Setenv(key, value)
+			s.dotenv[key] = value
+		}
+	}
+	for key := range s.dotenv {
+		if _, ok := values[key]; !ok {
+			_ = os.Unsetenv(key)
+			delete(s.dotenv, key)
+		}
+	}
+}
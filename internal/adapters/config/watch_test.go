@@ -0,0 +1,94 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"streamgogambler/internal/domain/gambling"
+	"streamgogambler/internal/ports"
+)
+
+func TestReloadAppliesChanges(t *testing.T) {
+	setRequiredEnv(t, "foo")
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("SLOTS_COST", "")
+	t.Setenv("SAY_BUCKET_SIZE", "")
+	t.Setenv("AUTO_SLOTS_INTERVAL", "15")
+	envPath := writeConfigFile(t, "config.yaml", "costs:\n  slots: 3000\n")
+	configPath := filepath.Join(filepath.Dir(envPath), "config.yaml")
+	require.NoError(t, os.WriteFile(envPath, []byte("AUTO_SLOTS_INTERVAL=15\n"), 0600))
+
+	store, err := NewEnvStore(envPath)
+	require.NoError(t, err)
+	channel := store.ForChannel("foo")
+
+	var changes []ports.BotConfig
+	unsubscribe := channel.Subscribe(func(old, updated ports.BotConfig) {
+		assert.Equal(t, 3000, old.SlotsCost, "previous configuration")
+		changes = append(changes, updated)
+	})
+
+	require.NoError(t, os.WriteFile(configPath, []byte("costs:\n  slots: 4000\nchat:\n  bucket_size: 50\n"), 0600))
+	require.NoError(t, os.WriteFile(envPath, []byte("AUTO_SLOTS_INTERVAL=5\n"), 0600))
+
+	ignored, err := store.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"SAY_BUCKET_SIZE"}, ignored, "settings needing a restart")
+
+	cfg := channel.GetConfig()
+	assert.Equal(t, 4000, cfg.SlotsCost, "file change applied")
+	assert.Equal(t, 5, cfg.AutoSlotsInterval, ".env change applied")
+	assert.Equal(t, DefaultSayBucketSize, cfg.SayBucketSize, "restart setting kept")
+	assert.Equal(t, "foo", cfg.Channel)
+	require.Len(t, changes, 1, "subscriber notified")
+	assert.Equal(t, cfg, changes[0])
+
+	require.NoError(t, os.WriteFile(configPath, []byte("costs:\n  slots: -1\n"), 0600))
+	_, err = store.Reload()
+	require.ErrorIs(t, err, ErrInvalidConfig)
+	assert.Equal(t, 4000, channel.GetConfig().SlotsCost, "invalid configuration not applied")
+
+	unsubscribe()
+	require.NoError(t, os.WriteFile(configPath, []byte("costs:\n  slots: 6000\n"), 0600))
+	_, err = store.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, 6000, channel.GetConfig().SlotsCost)
+	assert.Len(t, changes, 1, "no notification after unsubscribing")
+}
+
+func TestReloadKeepsVariablesSetToEmpty(t *testing.T) {
+	setRequiredEnv(t, "foo")
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("SLOTS_COST", "")
+	envPath := filepath.Join(t.TempDir(), ".env")
+	store, err := NewEnvStore(envPath)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(envPath, []byte("SLOTS_COST=4000\n"), 0600))
+	_, err = store.Reload()
+	require.NoError(t, err)
+	assert.Empty(t, os.Getenv("SLOTS_COST"), "environment overrides .env")
+	assert.Equal(t, gambling.DefaultSlotsCost, store.GetConfig().SlotsCost)
+}
+
+func TestReloadKeepsCredentials(t *testing.T) {
+	setRequiredEnv(t, "foo")
+	t.Setenv("CONFIG_FILE", "")
+	store, err := NewEnvStore(filepath.Join(t.TempDir(), ".env"))
+	require.NoError(t, err)
+
+	t.Setenv("TWITCH_USERNAME", "otherbot")
+	t.Setenv("TWITCH_OAUTH", "other")
+	t.Setenv("TWITCH_CHANNEL", "foo,bar")
+
+	ignored, err := store.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"TWITCH_CHANNEL", "TWITCH_OAUTH", "TWITCH_USERNAME"}, ignored)
+	assert.Equal(t, "testuser", store.GetConfig().Username)
+	assert.Equal(t, []string{"foo"}, store.GetConfig().Channels)
+	assert.Equal(t, "token", store.GetOAuth())
+}
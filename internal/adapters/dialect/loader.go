@@ -0,0 +1,87 @@
+package dialect
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"streamgogambler/internal/domain/parsing"
+)
+
+const dialectsDir = "dialects"
+
+var extensions = []string{".yaml", ".yml", ".json"}
+
+// Resolve returns the dialect selected by BOSS_BOT_DIALECT. The setting may be
+// empty, the name of a built-in dialect, a name of a file in the dialects
+// directory next to .env, or a path to a YAML/JSON file.
+func Resolve(setting, baseDir string) (*parsing.Dialect, error) {
+	setting = strings.TrimSpace(setting)
+	if setting == "" {
+		return parsing.DefaultDialect(), nil
+	}
+
+	if d, ok := parsing.BuiltinDialects()[strings.ToLower(setting)]; ok {
+		return d, nil
+	}
+
+	if hasKnownExtension(setting) {
+		path := setting
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(baseDir, path)
+		}
+		return Load(path)
+	}
+
+	for _, ext := range extensions {
+		path := filepath.Join(baseDir, dialectsDir, setting+ext)
+		if _, err := os.Stat(path); err == nil {
+			return Load(path)
+		}
+	}
+
+	return nil, fmt.Errorf("unknown dialect %q: not built in and no file found in %s", setting, filepath.Join(baseDir, dialectsDir))
+}
+
+// Load reads a dialect profile. Sections missing from the file keep the
+// values of the built-in default dialect.
+func Load(path string) (*parsing.Dialect, error) {
+	path = filepath.Clean(path)
+	// #nosec G304 -- dialect path is intentionally user-configurable
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading dialect %s: %w", path, err)
+	}
+
+	d := parsing.DefaultDialect()
+	d.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, d)
+	default:
+		err = yaml.Unmarshal(data, d)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing dialect %s: %w", path, err)
+	}
+
+	if err := d.Compile(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func hasKnownExtension(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, known := range extensions {
+		if ext == known {
+			return true
+		}
+	}
+	return false
+}
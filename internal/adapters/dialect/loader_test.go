@@ -0,0 +1,85 @@
+package dialect
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"streamgogambler/internal/domain/parsing"
+)
+
+func TestResolveBuiltin(t *testing.T) {
+	t.Parallel()
+
+	for _, setting := range []string{"", "default", "DEFAULT"} {
+		d, err := Resolve(setting, t.TempDir())
+		require.NoError(t, err, "Resolve(%q)", setting)
+		assert.Equal(t, parsing.DefaultDialectName, d.Name, "Resolve(%q) name", setting)
+	}
+}
+
+func TestResolveUnknown(t *testing.T) {
+	t.Parallel()
+
+	_, err := Resolve("nosuchbot", t.TempDir())
+	require.Error(t, err, "Resolve() should fail for unknown dialect")
+}
+
+func TestLoadYAMLFromDialectsDir(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, dialectsDir), 0750))
+	content := `
+slots:
+  trigger: "{user} spins the wheel"
+  outcomes:
+    - outcome: lost
+      contains: ["nothing"]
+cooldown:
+  - user_at_start: true
+    contains: ["wait"]
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, dialectsDir, "wheelbot.yaml"), []byte(content), 0600))
+
+	d, err := Resolve("wheelbot", dir)
+	require.NoError(t, err, "Resolve() should load dialect file")
+
+	assert.Equal(t, "wheelbot", d.Name, "dialect name from file name")
+	assert.True(t, d.IsSlotsReply("me spins the wheel", "me"), "custom slots trigger")
+	assert.True(t, d.IsCooldown("me please wait", "me"), "custom cooldown")
+	assert.True(t, d.IsHeistResult("Results from the Heist: me (1)"), "missing sections keep defaults")
+
+	n, ok := d.ParseBalance("me bombs: 77", "me")
+	assert.True(t, ok, "default balance pattern compiled")
+	assert.Equal(t, 77, n, "default balance pattern value")
+}
+
+func TestLoadJSON(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "points.json")
+	content := `{"name": "pointsbot", "balance": {"marker": "points:", "pattern": "points:\\s*(\\d+)"}}`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+
+	d, err := Resolve(path, "")
+	require.NoError(t, err, "Resolve() should load JSON path")
+
+	assert.Equal(t, "pointsbot", d.Name, "name from file content")
+	n, ok := d.ParseBalance("me points: 12", "me")
+	assert.True(t, ok, "ParseBalance ok")
+	assert.Equal(t, 12, n, "ParseBalance value")
+}
+
+func TestLoadInvalid(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "broken.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("balance:\n  pattern: \"(\"\n"), 0600))
+
+	_, err := Load(path)
+	require.ErrorIs(t, err, parsing.ErrInvalidDialect, "Load() should reject invalid pattern")
+}
@@ -2,6 +2,7 @@ package gui
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"streamgogambler/internal/adapters/gui/assets"
@@ -10,6 +11,7 @@ import (
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/driver/desktop"
 	"fyne.io/fyne/v2/widget"
 )
@@ -19,6 +21,16 @@ type StatsProvider interface {
 	IsAutoSlotsEnabled() bool
 	SetAutoSlots(enabled bool)
 	ExecuteCommand(command string)
+	ResetSession()
+	Channels() []string
+	SelectedChannel() string
+	SelectChannel(channel string) bool
+	Accounts() []string
+	SelectedAccount() string
+	SelectAccount(name string) bool
+	IsAccountRunning(name string) bool
+	StartAccount(name string) error
+	StopAccount(name string) error
 }
 
 type GUI struct {
@@ -34,13 +46,23 @@ type GUI struct {
 	sentLabel     *widget.Label
 	recvLabel     *widget.Label
 	reconnLabel   *widget.Label
+	syncLabel     *widget.Label
+	sessionLabel  *widget.Label
 
 	logList  *widget.List
 	logLines []string
 	maxLogs  int
 
-	autoSlotsChk *widget.Check
-	commandInput *widget.Entry
+	autoSlotsChk  *widget.Check
+	channelSelect *widget.Select
+	accountSelect *widget.Select
+	accountBtn    *widget.Button
+	commandInput  *widget.Entry
+
+	onToken func(account, token string) error
+	// tokenPrompts remembers the accounts already asked for a new token.
+	tokenMu      sync.Mutex
+	tokenPrompts map[string]bool
 
 	stopChan chan struct{}
 }
@@ -51,9 +73,16 @@ func New(statsProvider StatsProvider, maxLogs int) *GUI {
 		stopChan:      make(chan struct{}),
 		maxLogs:       maxLogs,
 		logLines:      make([]string, 0, maxLogs),
+		tokenPrompts:  make(map[string]bool),
 	}
 }
 
+// OnTokenEntered sets what is done with a new token entered for an account
+// whose token Twitch rejected. Without a handler no token is asked for.
+func (g *GUI) OnTokenEntered(handler func(account, token string) error) {
+	g.onToken = handler
+}
+
 func (g *GUI) Run() {
 	g.app = app.New()
 	g.app.SetIcon(assets.AppIcon())
@@ -142,6 +171,8 @@ func (g *GUI) buildUI() {
 	g.sentLabel = widget.NewLabel("Messages Sent: 0")
 	g.recvLabel = widget.NewLabel("Messages Received: 0")
 	g.reconnLabel = widget.NewLabel("Reconnects: 0")
+	g.syncLabel = widget.NewLabel("Last Sync: -")
+	g.sessionLabel = widget.NewLabel("Session: +0 (ok)")
 
 	statsCard := widget.NewCard("Statistics", "",
 		container.NewVBox(
@@ -149,6 +180,8 @@ func (g *GUI) buildUI() {
 			g.sentLabel,
 			g.recvLabel,
 			g.reconnLabel,
+			g.syncLabel,
+			g.sessionLabel,
 		),
 	)
 
@@ -161,11 +194,41 @@ func (g *GUI) buildUI() {
 		g.autoSlotsChk.Checked = g.statsProvider.IsAutoSlotsEnabled()
 	}
 
-	controlsCard := widget.NewCard("Controls", "",
-		container.NewVBox(
-			g.autoSlotsChk,
-		),
+	resetSessionBtn := widget.NewButton("Reset Session", func() {
+		if g.statsProvider != nil {
+			g.statsProvider.ResetSession()
+		}
+	})
+
+	controls := container.NewVBox(
+		g.autoSlotsChk,
+		resetSessionBtn,
 	)
+	if g.statsProvider != nil {
+		multiAccount := len(g.statsProvider.Accounts()) > 1
+		if multiAccount || len(g.statsProvider.Channels()) > 1 {
+			g.channelSelect = widget.NewSelect(g.statsProvider.Channels(), func(channel string) {
+				g.statsProvider.SelectChannel(channel)
+				g.updateStats()
+			})
+			g.channelSelect.Selected = g.statsProvider.SelectedChannel()
+			controls.Objects = append([]fyne.CanvasObject{g.channelSelect}, controls.Objects...)
+		}
+		if multiAccount {
+			g.accountBtn = widget.NewButton("Stop Account", g.toggleAccount)
+			g.accountSelect = widget.NewSelect(g.statsProvider.Accounts(), func(name string) {
+				g.statsProvider.SelectAccount(name)
+				g.channelSelect.Options = g.statsProvider.Channels()
+				g.channelSelect.Selected = g.statsProvider.SelectedChannel()
+				g.channelSelect.Refresh()
+				g.updateStats()
+			})
+			g.accountSelect.Selected = g.statsProvider.SelectedAccount()
+			controls.Objects = append([]fyne.CanvasObject{g.accountSelect, g.accountBtn}, controls.Objects...)
+		}
+	}
+
+	controlsCard := widget.NewCard("Controls", "", controls)
 
 	g.logList = widget.NewList(
 		func() int {
@@ -241,27 +304,120 @@ func (g *GUI) updateStats() {
 		return
 	}
 
-	stats := g.statsProvider.GetStats()
+	total := g.statsProvider.GetStats()
+	stats := selectedStats(total, g.statsProvider.SelectedAccount(), g.statsProvider.SelectedChannel())
 
 	g.statusLabel.SetText(fmt.Sprintf("Status: %s", stats.Status))
 	g.channelLabel.SetText(fmt.Sprintf("Channel: #%s", stats.Channel))
 	g.usernameLabel.SetText(fmt.Sprintf("Username: %s", stats.Username))
 	g.uptimeLabel.SetText(fmt.Sprintf("Uptime: %s", stats.Uptime))
 
-	g.balanceLabel.SetText(fmt.Sprintf("Balance: %d bombs", stats.Balance))
+	if len(total.Accounts) > 0 || len(total.Channels) > 0 {
+		g.balanceLabel.SetText(fmt.Sprintf("Balance: %d bombs (total: %d)", stats.Balance, total.Balance))
+	} else {
+		g.balanceLabel.SetText(fmt.Sprintf("Balance: %d bombs", stats.Balance))
+	}
 	g.sentLabel.SetText(fmt.Sprintf("Messages Sent: %d", stats.MessagesSent))
 	g.recvLabel.SetText(fmt.Sprintf("Messages Received: %d", stats.MessagesRecv))
 	g.reconnLabel.SetText(fmt.Sprintf("Reconnects: %d", stats.ReconnectCount))
+	if stats.LastSync != "" {
+		g.syncLabel.SetText(fmt.Sprintf("Last Sync: %s (drift %+d)", stats.LastSync, stats.LastDrift))
+	}
+	g.sessionLabel.SetText(fmt.Sprintf("Session: %+d (%s)", stats.SessionProfit, stats.Guardrail))
 
 	if g.autoSlotsChk.Checked != g.statsProvider.IsAutoSlotsEnabled() {
 		g.autoSlotsChk.Checked = g.statsProvider.IsAutoSlotsEnabled()
 		g.autoSlotsChk.Refresh()
 	}
+
+	if g.accountBtn != nil {
+		if g.statsProvider.IsAccountRunning(g.statsProvider.SelectedAccount()) {
+			g.accountBtn.SetText("Stop Account")
+		} else {
+			g.accountBtn.SetText("Start Account")
+		}
+	}
+
+	g.checkTokens(total)
+}
+
+// checkTokens asks for a new token for every account stopped because Twitch
+// rejected its token, once per rejection.
+func (g *GUI) checkTokens(total ports.BotStats) {
+	if g.onToken == nil {
+		return
+	}
+	accounts := total.Accounts
+	if len(accounts) == 0 {
+		accounts = []ports.BotStats{total}
+	}
+
+	g.tokenMu.Lock()
+	defer g.tokenMu.Unlock()
+	for _, stats := range accounts {
+		invalid := stats.Status == ports.StatusTokenInvalid
+		if invalid && !g.tokenPrompts[stats.Username] {
+			g.promptToken(stats.Username)
+		}
+		g.tokenPrompts[stats.Username] = invalid
+	}
+}
+
+func (g *GUI) promptToken(account string) {
+	g.window.Show()
+	entry := widget.NewPasswordEntry()
+	dialog.ShowForm("Twitch rejected the OAuth token of "+account, "Save & Reconnect", "Cancel",
+		[]*widget.FormItem{widget.NewFormItem("New OAuth token", entry)},
+		func(ok bool) {
+			if !ok || entry.Text == "" {
+				return
+			}
+			if err := g.onToken(account, entry.Text); err != nil {
+				g.AppendLog(fmt.Sprintf("Could not use the new token for %s: %v", account, err))
+			}
+			g.updateStats()
+		}, g.window)
+}
+
+func (g *GUI) toggleAccount() {
+	name := g.statsProvider.SelectedAccount()
+
+	var err error
+	if g.statsProvider.IsAccountRunning(name) {
+		err = g.statsProvider.StopAccount(name)
+	} else {
+		err = g.statsProvider.StartAccount(name)
+	}
+	if err != nil {
+		g.AppendLog(err.Error())
+	}
+	g.updateStats()
+}
+
+// selectedStats picks the stats of the selected account and channel out of
+// the per-account and per-channel stats, falling back to the totals.
+func selectedStats(total ports.BotStats, account, channel string) ports.BotStats {
+	stats := total
+	for _, a := range total.Accounts {
+		if a.Username == account {
+			stats = a
+			break
+		}
+	}
+	for _, c := range stats.Channels {
+		if c.Channel == channel {
+			return c
+		}
+	}
+	return stats
 }
 
 type SetupResult struct {
 	Values    map[string]string
 	Completed bool
+	// EncryptOAuth asks to keep TWITCH_OAUTH in the encrypted secrets file
+	// instead of .env.
+	EncryptOAuth bool
 }
 
 func ShowSetupDialog(missingVars []string, defaults map[string]string) SetupResult {
@@ -279,7 +435,7 @@ func ShowSetupDialog(missingVars []string, defaults map[string]string) SetupResu
 	varDescriptions := map[string]string{
 		"TWITCH_USERNAME": "Twitch Bot Username",
 		"TWITCH_OAUTH":    "Twitch OAuth Token (without 'oauth:' prefix)",
-		"TWITCH_CHANNEL":  "Twitch Channel to Join (without #)",
+		"TWITCH_CHANNEL":  "Twitch Channels to Join (without #, comma-separated)",
 		"COMMAND_PREFIX":  "Command Prefix (e.g., !)",
 		"STATUS_COMMAND":  "Status Command Name (e.g., status)",
 		"CONNECT_MESSAGE": "Message on Connect (e.g., !pyk)",
@@ -309,6 +465,11 @@ func ShowSetupDialog(missingVars []string, defaults map[string]string) SetupResu
 		formItems = append(formItems, widget.NewFormItem(label, entry))
 	}
 
+	encrypt := widget.NewCheck("Store the OAuth token encrypted (secrets.enc, this computer only)", nil)
+	if _, ok := inputs["TWITCH_OAUTH"]; ok {
+		formItems = append(formItems, widget.NewFormItem("", encrypt))
+	}
+
 	form := widget.NewForm(formItems...)
 
 	done := make(chan bool)
@@ -329,7 +490,7 @@ func ShowSetupDialog(missingVars []string, defaults map[string]string) SetupResu
 				widget.NewLabel("Error"),
 				errDialog,
 				widget.NewButton("OK", func() {
-					w.SetContent(buildSetupContent(form, inputs, done, &result))
+					w.SetContent(buildSetupContent(form, inputs, encrypt, done, &result))
 				}),
 			))
 			return
@@ -338,6 +499,7 @@ func ShowSetupDialog(missingVars []string, defaults map[string]string) SetupResu
 		for varName, entry := range inputs {
 			result.Values[varName] = entry.Text
 		}
+		result.EncryptOAuth = encrypt.Checked
 		result.Completed = true
 		done <- true
 	})
@@ -375,7 +537,7 @@ func ShowSetupDialog(missingVars []string, defaults map[string]string) SetupResu
 	return result
 }
 
-func buildSetupContent(form *widget.Form, inputs map[string]*widget.Entry, done chan bool, result *SetupResult) fyne.CanvasObject {
+func buildSetupContent(form *widget.Form, inputs map[string]*widget.Entry, encrypt *widget.Check, done chan bool, result *SetupResult) fyne.CanvasObject {
 	saveBtn := widget.NewButton("Save & Start Bot", func() {
 		allFilled := true
 		for _, entry := range inputs {
@@ -392,6 +554,7 @@ func buildSetupContent(form *widget.Form, inputs map[string]*widget.Entry, done
 		for varName, entry := range inputs {
 			result.Values[varName] = entry.Text
 		}
+		result.EncryptOAuth = encrypt.Checked
 		result.Completed = true
 		done <- true
 	})
@@ -0,0 +1,162 @@
+package healthcheck
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"streamgogambler/internal/ports"
+)
+
+const metricsPrefix = "streamgogambler_"
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// channelStats are the stats of one bot, one channel of one account.
+type channelStats struct {
+	account string
+	stats   ports.BotStats
+}
+
+func (s *HealthServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	stats := s.provider.GetStats()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+
+	if err := WriteMetrics(w, stats); err != nil {
+		s.logger.Errorf(r.Context(), "Writing /metrics: %v", err)
+	}
+}
+
+// WriteMetrics writes stats in the Prometheus text exposition format, one
+// series per account and channel.
+func WriteMetrics(out io.Writer, stats ports.BotStats) error {
+	accounts := stats.Accounts
+	if len(accounts) == 0 {
+		accounts = []ports.BotStats{stats}
+	}
+	var channels []channelStats
+	for _, account := range accounts {
+		perChannel := account.Channels
+		if len(perChannel) == 0 {
+			perChannel = []ports.BotStats{account}
+		}
+		for _, c := range perChannel {
+			if c.Channel != "" {
+				channels = append(channels, channelStats{account: account.Username, stats: c})
+			}
+		}
+	}
+
+	w := &metricsWriter{w: bufio.NewWriter(out)}
+
+	w.family("account_up", "gauge", "Whether the account is running (1) or stopped (0).")
+	for _, account := range accounts {
+		w.sample("account_up", boolValue(account.Status == "ok"), "account", account.Username)
+	}
+	w.family("uptime_seconds", "gauge", "Seconds since the account connected.")
+	for _, account := range accounts {
+		w.sample("uptime_seconds", account.UptimeSeconds, "account", account.Username)
+	}
+
+	perChannel := func(name, typ, help string, value func(ports.BotStats) int) {
+		w.family(name, typ, help)
+		for _, c := range channels {
+			w.sample(name, float64(value(c.stats)), "account", c.account, "channel", c.stats.Channel)
+		}
+	}
+	perKey := func(name, help, label string, counts func(ports.Counters) map[string]int) {
+		w.family(name, "counter", help)
+		for _, c := range channels {
+			values := counts(c.stats.Counters)
+			for _, key := range slices.Sorted(maps.Keys(values)) {
+				w.sample(name, float64(values[key]), "account", c.account, "channel", c.stats.Channel, label, key)
+			}
+		}
+	}
+
+	perChannel("balance_bombs", "gauge", "Tracked balance in bombs.",
+		func(s ports.BotStats) int { return s.Balance })
+	perKey("bombs_spent_total", "Bombs staked on settled games.", "game",
+		func(c ports.Counters) map[string]int { return c.Spent })
+	perKey("bombs_won_total", "Bombs returned by settled games.", "game",
+		func(c ports.Counters) map[string]int { return c.Won })
+	perKey("slots_outcomes_total", "Slots rolls by outcome.", "outcome",
+		func(c ports.Counters) map[string]int { return c.SlotsOutcomes })
+	perChannel("messages_sent_total", "counter", "Chat messages sent.",
+		func(s ports.BotStats) int { return s.MessagesSent })
+	perChannel("messages_received_total", "counter", "Chat messages received.",
+		func(s ports.BotStats) int { return s.MessagesRecv })
+	perChannel("reconnects_total", "counter", "Reconnects requested by Twitch.",
+		func(s ports.BotStats) int { return s.Counters.Reconnects })
+	perKey("parse_failures_total", "Boss bot replies that could not be parsed, by kind.", "kind",
+		func(c ports.Counters) map[string]int { return c.ParseFailures })
+	perChannel("drift_corrections_total", "counter", "Balance syncs that corrected the tracked balance.",
+		func(s ports.BotStats) int { return s.DriftCorrections })
+
+	w.family("say_wait_seconds", "histogram", "Time messages waited for a rate-limit token.")
+	for _, account := range accounts {
+		if account.Say == nil {
+			continue
+		}
+		for _, b := range account.Say.Buckets {
+			w.sample("say_wait_seconds_bucket", float64(b.Count), "account", account.Username, "le", formatValue(b.UpperBound))
+		}
+		w.sample("say_wait_seconds_bucket", float64(account.Say.Sent), "account", account.Username, "le", "+Inf")
+		w.sample("say_wait_seconds_sum", account.Say.WaitSum, "account", account.Username)
+		w.sample("say_wait_seconds_count", float64(account.Say.Sent), "account", account.Username)
+	}
+	w.family("say_timeouts_total", "counter", "Messages dropped after waiting too long for a rate-limit token.")
+	for _, account := range accounts {
+		if account.Say != nil {
+			w.sample("say_timeouts_total", float64(account.Say.Timeouts), "account", account.Username)
+		}
+	}
+
+	if w.err != nil {
+		return w.err
+	}
+	return w.w.Flush()
+}
+
+// metricsWriter writes metric families, keeping the first write error.
+type metricsWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (m *metricsWriter) family(name, typ, help string) {
+	m.printf("# HELP %s%s %s\n# TYPE %s%s %s\n", metricsPrefix, name, help, metricsPrefix, name, typ)
+}
+
+// sample writes one value; labels are name, value pairs.
+func (m *metricsWriter) sample(name string, value float64, labels ...string) {
+	pairs := make([]string, 0, len(labels)/2)
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, labels[i]+`="`+labelEscaper.Replace(labels[i+1])+`"`)
+	}
+	m.printf("%s%s{%s} %s\n", metricsPrefix, name, strings.Join(pairs, ","), formatValue(value))
+}
+
+func (m *metricsWriter) printf(format string, args ...any) {
+	if m.err == nil {
+		_, m.err = fmt.Fprintf(m.w, format, args...)
+	}
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
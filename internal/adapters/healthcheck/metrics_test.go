@@ -0,0 +1,106 @@
+package healthcheck
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"streamgogambler/internal/adapters/logging"
+	"streamgogambler/internal/ports"
+)
+
+type staticStats ports.BotStats
+
+func (s staticStats) GetStats() ports.BotStats { return ports.BotStats(s) }
+
+func TestWriteMetrics(t *testing.T) {
+	t.Parallel()
+
+	stats := ports.BotStats{Accounts: []ports.BotStats{
+		{
+			Username: "bot", Status: "ok", UptimeSeconds: 90,
+			Channels: []ports.BotStats{
+				{
+					Channel: "foo", Balance: 12500, MessagesSent: 3, MessagesRecv: 40, ReconnectCount: 1, DriftCorrections: 2,
+					Counters: ports.Counters{
+						Spent:         map[string]int{"slots": 4000, "heist": 1000},
+						Won:           map[string]int{"slots": 0, "heist": 2500},
+						SlotsOutcomes: map[string]int{"lost": 2, "jackpot": 0},
+						ParseFailures: map[string]int{"balance": 1},
+						Reconnects:    4,
+					},
+				},
+				{Channel: "bar", Balance: 300},
+			},
+			Say: &ports.SayStats{
+				Buckets:  []ports.SayBucket{{UpperBound: 0.1, Count: 2}, {UpperBound: 1, Count: 3}},
+				WaitSum:  0.75,
+				Sent:     4,
+				Timeouts: 1,
+			},
+		},
+		{Username: "alt", Status: "stopped", Channels: []ports.BotStats{{Channel: `we"ird`}}},
+	}}
+
+	var out strings.Builder
+	require.NoError(t, WriteMetrics(&out, stats), "WriteMetrics()")
+	lines := strings.Split(out.String(), "\n")
+
+	for _, want := range []string{
+		"# HELP streamgogambler_balance_bombs Tracked balance in bombs.",
+		"# TYPE streamgogambler_balance_bombs gauge",
+		"# TYPE streamgogambler_bombs_spent_total counter",
+		"# TYPE streamgogambler_say_wait_seconds histogram",
+		`streamgogambler_account_up{account="bot"} 1`,
+		`streamgogambler_account_up{account="alt"} 0`,
+		`streamgogambler_uptime_seconds{account="bot"} 90`,
+		`streamgogambler_balance_bombs{account="bot",channel="foo"} 12500`,
+		`streamgogambler_balance_bombs{account="bot",channel="bar"} 300`,
+		`streamgogambler_balance_bombs{account="alt",channel="we\"ird"} 0`,
+		`streamgogambler_bombs_spent_total{account="bot",channel="foo",game="heist"} 1000`,
+		`streamgogambler_bombs_won_total{account="bot",channel="foo",game="heist"} 2500`,
+		`streamgogambler_slots_outcomes_total{account="bot",channel="foo",outcome="lost"} 2`,
+		`streamgogambler_slots_outcomes_total{account="bot",channel="foo",outcome="jackpot"} 0`,
+		`streamgogambler_messages_sent_total{account="bot",channel="foo"} 3`,
+		`streamgogambler_messages_received_total{account="bot",channel="foo"} 40`,
+		`streamgogambler_reconnects_total{account="bot",channel="foo"} 4`,
+		`streamgogambler_parse_failures_total{account="bot",channel="foo",kind="balance"} 1`,
+		`streamgogambler_drift_corrections_total{account="bot",channel="foo"} 2`,
+		`streamgogambler_say_wait_seconds_bucket{account="bot",le="0.1"} 2`,
+		`streamgogambler_say_wait_seconds_bucket{account="bot",le="1"} 3`,
+		`streamgogambler_say_wait_seconds_bucket{account="bot",le="+Inf"} 4`,
+		`streamgogambler_say_wait_seconds_sum{account="bot"} 0.75`,
+		`streamgogambler_say_wait_seconds_count{account="bot"} 4`,
+		`streamgogambler_say_timeouts_total{account="bot"} 1`,
+	} {
+		assert.Contains(t, lines, want)
+	}
+	assert.NotContains(t, out.String(), `say_timeouts_total{account="alt"}`, "no say stats for a stopped account")
+}
+
+func TestWriteMetricsOfSingleChannelBot(t *testing.T) {
+	t.Parallel()
+
+	var out strings.Builder
+	require.NoError(t, WriteMetrics(&out, ports.BotStats{Username: "bot", Channel: "foo", Status: "ok", Balance: 42}), "WriteMetrics()")
+
+	assert.Contains(t, out.String(), "streamgogambler_account_up{account=\"bot\"} 1\n")
+	assert.Contains(t, out.String(), "streamgogambler_balance_bombs{account=\"bot\",channel=\"foo\"} 42\n")
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	t.Parallel()
+
+	server := NewHealthServer(0, staticStats{Username: "bot", Channel: "foo", Status: "ok"}, logging.New(logging.LevelError))
+	rec := httptest.NewRecorder()
+
+	server.handleMetrics(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code, "status")
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"), "content type")
+	assert.Contains(t, rec.Body.String(), `streamgogambler_account_up{account="bot"} 1`)
+}
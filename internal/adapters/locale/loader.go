@@ -0,0 +1,65 @@
+package locale
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"streamgogambler/internal/domain/i18n"
+)
+
+// Resolve returns the built-in catalog selected by BOT_LANGUAGE with the
+// templates of the BOT_MESSAGES file applied. A relative file path is
+// resolved against baseDir, the directory of .env.
+func Resolve(language, messagesFile, baseDir string) (*i18n.Catalog, error) {
+	language = strings.TrimSpace(language)
+	if language == "" {
+		language = i18n.DefaultLanguage
+	}
+	if err := i18n.CheckLanguage(language); err != nil {
+		return nil, err
+	}
+	catalog, _ := i18n.Builtin(language)
+
+	messagesFile = strings.TrimSpace(messagesFile)
+	if messagesFile == "" {
+		return catalog, nil
+	}
+	if !filepath.IsAbs(messagesFile) {
+		messagesFile = filepath.Join(baseDir, messagesFile)
+	}
+	templates, err := Load(messagesFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := catalog.Override(templates); err != nil {
+		return nil, fmt.Errorf("messages %s: %w", messagesFile, err)
+	}
+	return catalog, nil
+}
+
+// Load reads a YAML or JSON file mapping message IDs to templates.
+func Load(path string) (map[string]string, error) {
+	path = filepath.Clean(path)
+	// #nosec G304 -- messages path is intentionally user-configurable
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading messages %s: %w", path, err)
+	}
+
+	var templates map[string]string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &templates)
+	default:
+		err = yaml.Unmarshal(data, &templates)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing messages %s: %w", path, err)
+	}
+	return templates, nil
+}
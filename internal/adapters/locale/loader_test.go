@@ -0,0 +1,63 @@
+package locale
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"streamgogambler/internal/domain/i18n"
+)
+
+func TestResolveBuiltin(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct{ setting, want string }{{"", "pl"}, {"EN", "en"}, {"pl", "pl"}} {
+		c, err := Resolve(tt.setting, "", t.TempDir())
+		require.NoError(t, err, "Resolve(%q)", tt.setting)
+		assert.Equal(t, tt.want, c.Language, "Resolve(%q) language", tt.setting)
+	}
+}
+
+func TestResolveUnknownLanguage(t *testing.T) {
+	t.Parallel()
+
+	_, err := Resolve("xx", "", t.TempDir())
+	require.ErrorIs(t, err, i18n.ErrUnknownLanguage)
+}
+
+func TestResolveAppliesOverrides(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "messages.yaml"), []byte(`status: "@{user}, all good! Heist: {heist}"`), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "messages.json"), []byte(`{"trust.self": "@{user}, nope"}`), 0600))
+
+	c, err := Resolve("en", "messages.yaml", dir)
+	require.NoError(t, err, "YAML relative to .env")
+	assert.Equal(t, "@bob, all good! Heist: 1000", c.Format("status", i18n.Vars{"user": "bob", "heist": 1000}), "overridden")
+	assert.Equal(t, "@bob, You cannot add yourself!", c.Format("trust.self", i18n.Vars{"user": "bob"}), "others keep the language")
+
+	c, err = Resolve("pl", filepath.Join(dir, "messages.json"), "")
+	require.NoError(t, err, "absolute JSON path")
+	assert.Equal(t, "@bob, nope", c.Format("trust.self", i18n.Vars{"user": "bob"}))
+}
+
+func TestResolveRejectsBadOverrides(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "unknown.yaml"), []byte(`statsu: "typo"`), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.yaml"), []byte(`status: [`), 0600))
+
+	_, err := Resolve("en", "unknown.yaml", dir)
+	require.ErrorIs(t, err, i18n.ErrInvalidCatalog, "unknown message ID")
+
+	_, err = Resolve("en", "broken.yaml", dir)
+	require.Error(t, err, "invalid YAML")
+
+	_, err = Resolve("en", "missing.yaml", dir)
+	require.Error(t, err, "missing file")
+}
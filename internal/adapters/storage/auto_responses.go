@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"streamgogambler/internal/domain/autoresponse"
+)
+
+// AutoResponsesStore persists the auto-responses so they can be edited from
+// chat or by hand without rebuilding.
+type AutoResponsesStore struct {
+	filePath string
+	defaults []autoresponse.Rule
+	// modTime is the modification time of the file last read or written.
+	modTime time.Time
+	mu      sync.Mutex
+}
+
+type AutoResponsesData struct {
+	Responses []autoresponse.Rule `json:"responses"`
+}
+
+func NewAutoResponsesStore(filePath string) *AutoResponsesStore {
+	return &AutoResponsesStore{
+		filePath: filepath.Clean(filePath),
+		defaults: autoresponse.Defaults(),
+	}
+}
+
+// SetDefaults replaces the rules a missing file is created with.
+func (s *AutoResponsesStore) SetDefaults(rules []autoresponse.Rule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.defaults = rules
+}
+
+// Load reads and validates the auto-responses in file order. A missing file
+// is created with the defaults, autoresponse.Defaults unless set otherwise.
+func (s *AutoResponsesStore) Load() ([]autoresponse.Rule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.load()
+}
+
+// Reload reads the file again if it was modified since it was last read or
+// written, reporting whether it was.
+func (s *AutoResponsesStore) Reload() ([]autoresponse.Rule, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info, err := os.Stat(s.filePath)
+	if err != nil || info.ModTime().Equal(s.modTime) {
+		return nil, false, nil
+	}
+	rules, err := s.load()
+	if err != nil {
+		return nil, false, err
+	}
+	return rules, true, nil
+}
+
+func (s *AutoResponsesStore) load() ([]autoresponse.Rule, error) {
+	data, err := os.ReadFile(s.filePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+		rules := slices.Clone(s.defaults)
+		if err := s.write(rules); err != nil {
+			return nil, fmt.Errorf("creating %s: %w", s.filePath, err)
+		}
+		return rules, nil
+	}
+
+	s.touch()
+
+	var stored AutoResponsesData
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("%s: %w", s.filePath, err)
+	}
+
+	set := autoresponse.NewSet(nil)
+	for _, r := range stored.Responses {
+		if err := set.Add(r); err != nil {
+			return nil, fmt.Errorf("%s: %w", s.filePath, err)
+		}
+	}
+	return set.Rules(), nil
+}
+
+func (s *AutoResponsesStore) Save(rules []autoresponse.Rule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.write(rules)
+}
+
+func (s *AutoResponsesStore) write(rules []autoresponse.Rule) error {
+	if rules == nil {
+		rules = []autoresponse.Rule{}
+	}
+	jsonData, err := json.MarshalIndent(AutoResponsesData{Responses: rules}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(s.filePath, jsonData); err != nil {
+		return err
+	}
+	s.touch()
+	return nil
+}
+
+func (s *AutoResponsesStore) touch() {
+	if info, err := os.Stat(s.filePath); err == nil {
+		s.modTime = info.ModTime()
+	}
+}
+
+func ResolveAutoResponsesPath(envPath string) string {
+	dir := filepath.Dir(envPath)
+	return filepath.Join(dir, "auto_responses.json")
+}
@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"streamgogambler/internal/domain/autoresponse"
+)
+
+func TestAutoResponsesStore_LoadCreatesDefaults(t *testing.T) {
+	t.Parallel()
+
+	filePath := filepath.Join(t.TempDir(), "auto_responses.json")
+	store := NewAutoResponsesStore(filePath)
+
+	rules, err := store.Load()
+	require.NoError(t, err, "Load()")
+	assert.Equal(t, autoresponse.Defaults(), rules, "rules of a new file")
+	assert.FileExists(t, filePath, "defaults written")
+
+	again, err := store.Load()
+	require.NoError(t, err, "Load() after creating")
+	assert.Equal(t, rules, again, "rules read back")
+}
+
+func TestAutoResponsesStore_LoadCreatesGivenDefaults(t *testing.T) {
+	t.Parallel()
+
+	store := NewAutoResponsesStore(filepath.Join(t.TempDir(), "auto_responses.json"))
+	defaults := []autoresponse.Rule{{Trigger: "Raffle!", Response: "!join"}}
+	store.SetDefaults(defaults)
+
+	rules, err := store.Load()
+	require.NoError(t, err, "Load()")
+	assert.Equal(t, defaults, rules, "rules of a new file")
+}
+
+func TestAutoResponsesStore_SaveAndLoad(t *testing.T) {
+	t.Parallel()
+
+	store := NewAutoResponsesStore(filepath.Join(t.TempDir(), "auto_responses.json"))
+	rules := []autoresponse.Rule{
+		{Trigger: "Raffle!", Response: "!join", CooldownSeconds: 60, Probability: 0.5, MinBalance: 1000},
+		{Trigger: "Type !ffa to start!", Response: "!ffa", Disabled: true},
+	}
+
+	require.NoError(t, store.Save(rules), "Save()")
+	loaded, err := store.Load()
+	require.NoError(t, err, "Load()")
+	assert.Equal(t, rules, loaded, "order and fields are kept")
+
+	require.NoError(t, store.Save(nil), "Save(nil)")
+	loaded, err = store.Load()
+	require.NoError(t, err, "Load() of an empty list")
+	assert.Empty(t, loaded, "an emptied list is not replaced by the defaults")
+}
+
+func TestAutoResponsesStore_LoadRejectsInvalidRules(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"malformed", "{"},
+		{"missing response", `{"responses":[{"trigger":"x"}]}`},
+		{"duplicate trigger", `{"responses":[{"trigger":"x","response":"!a"},{"trigger":"x","response":"!b"}]}`},
+		{"probability above 1", `{"responses":[{"trigger":"x","response":"!a","probability":2}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			filePath := filepath.Join(t.TempDir(), "auto_responses.json")
+			require.NoError(t, os.WriteFile(filePath, []byte(tt.content), 0600), "writing file")
+
+			_, err := NewAutoResponsesStore(filePath).Load()
+			assert.Error(t, err, "Load()")
+		})
+	}
+}
+
+func TestAutoResponsesStore_ReloadReadsChangedFile(t *testing.T) {
+	t.Parallel()
+
+	filePath := filepath.Join(t.TempDir(), "auto_responses.json")
+	store := NewAutoResponsesStore(filePath)
+	require.NoError(t, store.Save([]autoresponse.Rule{{Trigger: "x", Response: "!a"}}), "Save()")
+
+	_, changed, err := store.Reload()
+	require.NoError(t, err, "Reload() of an unchanged file")
+	assert.False(t, changed, "own writes are not reported")
+
+	require.NoError(t, os.WriteFile(filePath, []byte(`{"responses":[{"trigger":"y","response":"!b"}]}`), 0600), "editing file")
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(filePath, later, later), "touching file")
+
+	rules, changed, err := store.Reload()
+	require.NoError(t, err, "Reload() of an edited file")
+	assert.True(t, changed, "edit reported")
+	assert.Equal(t, []autoresponse.Rule{{Trigger: "y", Response: "!b"}}, rules, "rules of the edited file")
+}
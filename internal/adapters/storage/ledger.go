@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"path/filepath"
+	"sync"
+
+	"streamgogambler/internal/domain/wallet"
+)
+
+// LedgerStore persists wallet entries to an append-only JSON Lines file.
+type LedgerStore struct {
+	filePath string
+	mu       sync.Mutex
+}
+
+func NewLedgerStore(filePath string) *LedgerStore {
+	return &LedgerStore{
+		filePath: filepath.Clean(filePath),
+	}
+}
+
+func (s *LedgerStore) Append(entry wallet.Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return appendJSONLine(s.filePath, entry)
+}
+
+func (s *LedgerStore) Load() ([]wallet.Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return loadJSONLines[wallet.Entry](s.filePath)
+}
+
+func ResolveLedgerPath(envPath string) string {
+	dir := filepath.Dir(envPath)
+	return filepath.Join(dir, "ledger.jsonl")
+}
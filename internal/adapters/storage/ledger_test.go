@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"streamgogambler/internal/domain/wallet"
+)
+
+func TestLedgerStore_LoadMissing(t *testing.T) {
+	t.Parallel()
+
+	store := NewLedgerStore(filepath.Join(t.TempDir(), "ledger.jsonl"))
+	entries, err := store.Load()
+
+	require.NoError(t, err, "Load() should not error for non-existent file")
+	assert.Empty(t, entries, "Load() should return no entries")
+}
+
+func TestLedgerStore_AppendAndLoad(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "nested", "ledger.jsonl")
+	store := NewLedgerStore(path)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	first := wallet.Entry{Time: now, Game: wallet.GameSlots, Amount: -2000, Before: 5000, After: 3000, Message: "!slots"}
+	second := wallet.Entry{Time: now, Game: wallet.GameSync, Amount: 100, Before: 3000, After: 3100, Message: "user bombs: 3100"}
+
+	require.NoError(t, store.Append(first), "Append(first)")
+	require.NoError(t, store.Append(second), "Append(second)")
+
+	entries, err := store.Load()
+	require.NoError(t, err, "Load()")
+	require.Len(t, entries, 2, "entry count")
+	assert.Equal(t, first, entries[0], "first entry")
+	assert.Equal(t, second, entries[1], "second entry")
+}
+
+func TestLedgerStore_AppendOnly(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "ledger.jsonl")
+	require.NoError(t, NewLedgerStore(path).Append(wallet.Entry{Amount: 1}))
+	require.NoError(t, NewLedgerStore(path).Append(wallet.Entry{Amount: 2}))
+
+	entries, err := NewLedgerStore(path).Load()
+	require.NoError(t, err, "Load()")
+	assert.Len(t, entries, 2, "entries from separate stores should accumulate")
+}
+
+func TestLedgerStore_LoadCorrupted(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "ledger.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte("not json\n"), 0600))
+
+	_, err := NewLedgerStore(path).Load()
+	assert.Error(t, err, "Load() should fail on corrupted line")
+}
+
+func TestResolveLedgerPath(t *testing.T) {
+	t.Parallel()
+
+	got := ResolveLedgerPath(filepath.Join("some", "dir", ".env"))
+	assert.Equal(t, filepath.Join("some", "dir", "ledger.jsonl"), got, "ResolveLedgerPath()")
+}
@@ -0,0 +1,18 @@
+package storage
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// ScopedFilePath returns the variant of a storage file for one channel or
+// account, e.g. trusted_users_foo.json for trusted_users.json. The primary
+// channel or account keeps the plain file so single-channel setups are
+// unaffected.
+func ScopedFilePath(path, scope string, primary bool) string {
+	if primary || scope == "" {
+		return path
+	}
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "_" + strings.ToLower(scope) + ext
+}
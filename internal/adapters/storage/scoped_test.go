@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScopedFilePath(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join("data", "bot")
+
+	tests := []struct {
+		name    string
+		path    string
+		scope   string
+		primary bool
+		want    string
+	}{
+		{"primary keeps plain file", filepath.Join(dir, "trusted_users.json"), "foo", true, filepath.Join(dir, "trusted_users.json")},
+		{"other scope gets suffix", filepath.Join(dir, "trusted_users.json"), "foo", false, filepath.Join(dir, "trusted_users_foo.json")},
+		{"scope is lowercased", filepath.Join(dir, "ledger.jsonl"), "FooBar", false, filepath.Join(dir, "ledger_foobar.jsonl")},
+		{"no extension", filepath.Join(dir, "ledger"), "foo", false, filepath.Join(dir, "ledger_foo")},
+		{"empty scope", filepath.Join(dir, "ledger.jsonl"), "", false, filepath.Join(dir, "ledger.jsonl")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, ScopedFilePath(tt.path, tt.scope, tt.primary))
+		})
+	}
+}
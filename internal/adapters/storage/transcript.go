@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"path/filepath"
+	"sync"
+	"time"
+
+	"streamgogambler/internal/ports"
+)
+
+// TranscriptEntry is a received chat message with the time it arrived.
+type TranscriptEntry struct {
+	Time    time.Time `json:"time"`
+	ID      string    `json:"id,omitempty"`
+	Channel string    `json:"channel"`
+	User    string    `json:"user"`
+	UserID  string    `json:"user_id,omitempty"`
+	Text    string    `json:"text"`
+
+	Badges map[string]int `json:"badges,omitempty"`
+}
+
+func NewTranscriptEntry(at time.Time, msg ports.ChatMessage) TranscriptEntry {
+	return TranscriptEntry{
+		Time:    at,
+		ID:      msg.ID,
+		Channel: msg.Channel,
+		User:    msg.UserName,
+		UserID:  msg.UserID,
+		Text:    msg.Text,
+		Badges:  msg.Badges,
+	}
+}
+
+func (e TranscriptEntry) Message() ports.ChatMessage {
+	return ports.ChatMessage{
+		ID:       e.ID,
+		UserName: e.User,
+		UserID:   e.UserID,
+		Channel:  e.Channel,
+		Text:     e.Text,
+		Badges:   e.Badges,
+	}
+}
+
+// TranscriptStore records received chat messages to a JSON Lines file so a
+// session can be replayed later.
+type TranscriptStore struct {
+	filePath string
+	mu       sync.Mutex
+}
+
+func NewTranscriptStore(filePath string) *TranscriptStore {
+	return &TranscriptStore{
+		filePath: filepath.Clean(filePath),
+	}
+}
+
+func (s *TranscriptStore) Append(entry TranscriptEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return appendJSONLine(s.filePath, entry)
+}
+
+func (s *TranscriptStore) Load() ([]TranscriptEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return loadJSONLines[TranscriptEntry](s.filePath)
+}
+
+// ResolveTranscriptPath places a relative TRANSCRIPT_FILE next to .env.
+func ResolveTranscriptPath(envPath, setting string) string {
+	if setting == "" || filepath.IsAbs(setting) {
+		return setting
+	}
+	return filepath.Join(filepath.Dir(envPath), setting)
+}
@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"streamgogambler/internal/ports"
+)
+
+func TestTranscriptStore_AppendAndLoad(t *testing.T) {
+	t.Parallel()
+
+	store := NewTranscriptStore(filepath.Join(t.TempDir(), "logs", "transcript.jsonl"))
+	now := time.Date(2025, time.March, 1, 20, 0, 0, 0, time.UTC)
+	msgs := []ports.ChatMessage{
+		{ID: "1", UserName: "bossbot", UserID: "42", Channel: "chan", Text: "testuser bombs: 5000"},
+		{ID: "2", UserName: "viewer", Channel: "chan", Text: "hello", Badges: map[string]int{"moderator": 1}},
+	}
+
+	for i, msg := range msgs {
+		require.NoError(t, store.Append(NewTranscriptEntry(now.Add(time.Duration(i)*time.Second), msg)), "Append(%d)", i)
+	}
+
+	entries, err := store.Load()
+	require.NoError(t, err, "Load()")
+	require.Len(t, entries, 2, "entry count")
+	assert.Equal(t, msgs[0], entries[0].Message(), "first message round-trips")
+	assert.Equal(t, msgs[1], entries[1].Message(), "second message round-trips")
+	assert.True(t, now.Add(time.Second).Equal(entries[1].Time), "timestamp kept")
+}
+
+func TestTranscriptStore_LoadMissing(t *testing.T) {
+	t.Parallel()
+
+	entries, err := NewTranscriptStore(filepath.Join(t.TempDir(), "none.jsonl")).Load()
+	require.NoError(t, err, "Load() should not error for non-existent file")
+	assert.Empty(t, entries, "no entries")
+}
+
+func TestResolveTranscriptPath(t *testing.T) {
+	t.Parallel()
+
+	envPath := filepath.Join("home", "bot", ".env")
+
+	assert.Empty(t, ResolveTranscriptPath(envPath, ""), "disabled")
+	assert.Equal(t, filepath.Join("home", "bot", "chat.jsonl"), ResolveTranscriptPath(envPath, "chat.jsonl"), "relative to .env")
+	abs := filepath.Join(string(filepath.Separator), "var", "log", "chat.jsonl")
+	assert.Equal(t, abs, ResolveTranscriptPath(envPath, abs), "absolute kept")
+}
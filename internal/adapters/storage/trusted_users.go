@@ -2,8 +2,11 @@ package storage
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
 )
 
@@ -12,7 +15,37 @@ type TrustedUsersStore struct {
 	mu       sync.Mutex
 }
 
+// TrustedUsersVersion is the current trusted_users.json format: users are
+// identified by their Twitch user ID, with login and display name kept for
+// matching not yet seen users and for listing.
+const TrustedUsersVersion = 2
+
+// TrustedUser is a trusted chatter. ID is empty until the user is first seen
+// in chat under Login.
+type TrustedUser struct {
+	ID          string `json:"id,omitempty"`
+	Login       string `json:"login"`
+	DisplayName string `json:"display_name,omitempty"`
+}
+
+// Name returns the display name, falling back to the login.
+func (u TrustedUser) Name() string {
+	if u.DisplayName != "" {
+		return u.DisplayName
+	}
+	return u.Login
+}
+
+// TrustedUsersData is the trusted_users.json format. BossBots holds the user
+// IDs learned for boss bot logins when BOSS_BOT_ID is not set.
 type TrustedUsersData struct {
+	Version  int               `json:"version"`
+	Users    []TrustedUser     `json:"users"`
+	BossBots map[string]string `json:"boss_bots,omitempty"`
+}
+
+// legacyTrustedUsersData is the version 1 format: a list of lowercase logins.
+type legacyTrustedUsersData struct {
 	Users []string `json:"users"`
 }
 
@@ -22,76 +55,107 @@ func NewTrustedUsersStore(filePath string) *TrustedUsersStore {
 	}
 }
 
-func (s *TrustedUsersStore) Load() (map[string]bool, error) {
+// Load reads the trusted users. A file in the legacy list-of-logins format is
+// migrated and rewritten in the current format.
+func (s *TrustedUsersStore) Load() ([]TrustedUser, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	users := make(map[string]bool)
-
 	data, err := os.ReadFile(s.filePath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			// File doesn't exist, return empty map (not an error)
-			return users, nil
+			// File doesn't exist, return no users (not an error)
+			return nil, nil
 		}
 		return nil, err
 	}
 
 	var stored TrustedUsersData
-	if err := json.Unmarshal(data, &stored); err != nil {
-		return nil, err
+	if err := json.Unmarshal(data, &stored); err == nil {
+		return stored.Users, nil
 	}
 
-	for _, user := range stored.Users {
-		users[user] = true
+	var legacy legacyTrustedUsersData
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return nil, err
 	}
 
+	users := make([]TrustedUser, 0, len(legacy.Users))
+	for _, login := range legacy.Users {
+		users = append(users, TrustedUser{Login: strings.ToLower(login)})
+	}
+	if err := s.write(TrustedUsersData{Users: users}); err != nil {
+		return nil, fmt.Errorf("migrating %s: %w", s.filePath, err)
+	}
 	return users, nil
 }
 
-func (s *TrustedUsersStore) Save(users map[string]bool) error {
+func (s *TrustedUsersStore) Save(users []TrustedUser) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	userList := make([]string, 0, len(users))
-	for user := range users {
-		userList = append(userList, user)
-	}
+	stored := s.read()
+	stored.Users = users
+	return s.write(stored)
+}
 
-	data := TrustedUsersData{Users: userList}
-	jsonData, err := json.MarshalIndent(data, "", "  ")
+// LoadBossBots returns the learned boss bot IDs keyed by lowercase login.
+func (s *TrustedUsersStore) LoadBossBots() (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.filePath)
 	if err != nil {
-		return err
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
 	}
 
-	dir := filepath.Dir(s.filePath)
-	if err := os.MkdirAll(dir, 0750); err != nil {
-		return err
+	var stored TrustedUsersData
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, err
 	}
+	return stored.BossBots, nil
+}
 
-	tmpFile, err := os.CreateTemp(dir, "trusted_users.tmp-*")
-	if err != nil {
-		return err
+// SaveBossBot records the user ID learned for the boss bot login, keeping the
+// trusted users as they are.
+func (s *TrustedUsersStore) SaveBossBot(login, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := s.read()
+	if stored.BossBots == nil {
+		stored.BossBots = make(map[string]string)
 	}
-	tmpPath := tmpFile.Name()
+	stored.BossBots[strings.ToLower(login)] = id
+	return s.write(stored)
+}
 
-	if _, err := tmpFile.Write(jsonData); err != nil {
-		_ = tmpFile.Close()
-		_ = os.Remove(tmpPath)
-		return err
+// read returns the stored data, empty if the file is missing or unreadable.
+func (s *TrustedUsersStore) read() TrustedUsersData {
+	var stored TrustedUsersData
+	if data, err := os.ReadFile(s.filePath); err == nil {
+		_ = json.Unmarshal(data, &stored)
 	}
+	return stored
+}
 
-	if err := tmpFile.Close(); err != nil {
-		_ = os.Remove(tmpPath)
-		return err
+func (s *TrustedUsersStore) write(stored TrustedUsersData) error {
+	sorted := slices.Clone(stored.Users)
+	if sorted == nil {
+		sorted = []TrustedUser{}
 	}
+	slices.SortFunc(sorted, func(a, b TrustedUser) int { return strings.Compare(a.Login, b.Login) })
 
-	if err := os.Rename(tmpPath, s.filePath); err != nil {
-		_ = os.Remove(tmpPath)
+	data := TrustedUsersData{Version: TrustedUsersVersion, Users: sorted, BossBots: stored.BossBots}
+	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
 		return err
 	}
 
-	return nil
+	return writeFileAtomic(s.filePath, jsonData)
 }
 
 func ResolveTrustedUsersPath(envPath string) string {
@@ -39,7 +39,7 @@ func TestTrustedUsersStore_LoadEmpty(t *testing.T) {
 	users, err := store.Load()
 
 	require.NoError(t, err, "Load() should not error for non-existent file")
-	assert.Empty(t, users, "Load() should return empty map for non-existent file")
+	assert.Empty(t, users, "Load() should return no users for non-existent file")
 }
 
 func TestTrustedUsersStore_SaveAndLoad(t *testing.T) {
@@ -47,32 +47,29 @@ func TestTrustedUsersStore_SaveAndLoad(t *testing.T) {
 
 	tests := []struct {
 		name  string
-		users map[string]bool
+		users []TrustedUser
 	}{
 		{
-			name:  "empty map",
-			users: map[string]bool{},
+			name:  "no users",
+			users: []TrustedUser{},
 		},
 		{
-			name: "single user",
-			users: map[string]bool{
-				"testuser": true,
-			},
+			name:  "single user",
+			users: []TrustedUser{{ID: "1001", Login: "testuser", DisplayName: "TestUser"}},
 		},
 		{
 			name: "multiple users",
-			users: map[string]bool{
-				"user1":    true,
-				"user2":    true,
-				"user3":    true,
-				"frankos6": true,
+			users: []TrustedUser{
+				{ID: "1", Login: "frankos6", DisplayName: "Frankos6"},
+				{ID: "2", Login: "user1"},
+				{ID: "3", Login: "user2", DisplayName: "User2"},
 			},
 		},
 		{
-			name: "users with special chars",
-			users: map[string]bool{
-				"user_with_underscore": true,
-				"user123":              true,
+			name: "user not yet seen",
+			users: []TrustedUser{
+				{Login: "user_with_underscore"},
+				{ID: "123", Login: "user123"},
 			},
 		},
 	}
@@ -94,15 +91,61 @@ func TestTrustedUsersStore_SaveAndLoad(t *testing.T) {
 			loaded, err := store.Load()
 			require.NoError(t, err, "Load() error")
 
-			assert.Len(t, loaded, len(tt.users), "Load() returned wrong number of users")
-
-			for user := range tt.users {
-				assert.True(t, loaded[user], "Load() missing user %q", user)
-			}
+			assert.ElementsMatch(t, tt.users, loaded, "Load() returned different users")
 		})
 	}
 }
 
+func TestTrustedUsersStore_MigratesLegacyFormat(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "trusted_users.json")
+	require.NoError(t, os.WriteFile(filePath, []byte(`{"users":["Frankos6","user2"]}`), 0600), "Could not write test file")
+
+	store := NewTrustedUsersStore(filePath)
+	loaded, err := store.Load()
+	require.NoError(t, err, "Load() error")
+	assert.ElementsMatch(t, []TrustedUser{{Login: "frankos6"}, {Login: "user2"}}, loaded, "legacy logins become users without an ID")
+
+	data, err := os.ReadFile(filePath)
+	require.NoError(t, err, "reading migrated file")
+	assert.Contains(t, string(data), `"version": 2`, "file rewritten in the current format")
+	assert.Contains(t, string(data), `"login": "frankos6"`, "logins kept")
+
+	reloaded, err := store.Load()
+	require.NoError(t, err, "Load() of migrated file")
+	assert.Equal(t, loaded, reloaded, "migrated file loads the same users")
+}
+
+func TestTrustedUsersStore_KeepsBossBots(t *testing.T) {
+	t.Parallel()
+
+	store := NewTrustedUsersStore(filepath.Join(t.TempDir(), "trusted_users.json"))
+
+	bossBots, err := store.LoadBossBots()
+	require.NoError(t, err, "LoadBossBots() of a missing file")
+	assert.Empty(t, bossBots)
+
+	require.NoError(t, store.SaveBossBot("BossBot", "9"), "SaveBossBot()")
+	require.NoError(t, store.Save([]TrustedUser{{Login: "friend"}}), "Save()")
+
+	bossBots, err = store.LoadBossBots()
+	require.NoError(t, err, "LoadBossBots()")
+	assert.Equal(t, map[string]string{"bossbot": "9"}, bossBots, "boss bot kept across Save()")
+
+	users, err := store.Load()
+	require.NoError(t, err, "Load()")
+	assert.Equal(t, []TrustedUser{{Login: "friend"}}, users, "users kept across SaveBossBot()")
+}
+
+func TestTrustedUserName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "Frankos6", TrustedUser{Login: "frankos6", DisplayName: "Frankos6"}.Name(), "display name preferred")
+	assert.Equal(t, "frankos6", TrustedUser{Login: "frankos6"}.Name(), "login fallback")
+}
+
 func TestTrustedUsersStore_LoadInvalidJSON(t *testing.T) {
 	t.Parallel()
 
@@ -125,20 +168,18 @@ func TestTrustedUsersStore_SaveOverwrite(t *testing.T) {
 	filePath := filepath.Join(tmpDir, "trusted_users.json")
 	store := NewTrustedUsersStore(filePath)
 
-	initial := map[string]bool{"user1": true, "user2": true}
+	initial := []TrustedUser{{ID: "1", Login: "user1"}, {ID: "2", Login: "user2"}}
 	err := store.Save(initial)
 	require.NoError(t, err, "Save() initial error")
 
-	updated := map[string]bool{"user3": true}
+	updated := []TrustedUser{{ID: "3", Login: "user3"}}
 	err = store.Save(updated)
 	require.NoError(t, err, "Save() updated error")
 
 	loaded, err := store.Load()
 	require.NoError(t, err, "Load() error")
 
-	assert.Len(t, loaded, 1, "Load() returned wrong number of users")
-	assert.True(t, loaded["user3"], "Load() missing user3")
-	assert.False(t, loaded["user1"], "Load() should not contain user1 after overwrite")
+	assert.Equal(t, updated, loaded, "Load() should only contain user3 after overwrite")
 }
 
 func TestResolveTrustedUsersPath(t *testing.T) {
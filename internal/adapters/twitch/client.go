@@ -3,6 +3,10 @@ package twitch
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gempir/go-twitch-irc/v4"
@@ -22,6 +26,10 @@ const (
 	SafeSayTimeout    = 30 * time.Second
 )
 
+// SayWaitBuckets are the upper bounds, in seconds, of the histogram of how
+// long messages wait for a rate-limit token.
+var SayWaitBuckets = []float64{0.001, 0.01, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
 var (
 	ErrSayTimeout   = errors.New("say timeout waiting for token")
 	ErrDisconnected = errors.New("client disconnected")
@@ -41,6 +49,15 @@ type Client struct {
 	onReconnect func() bool
 	onNotice    func(channel, message string)
 
+	oauth     string
+	validator *TokenValidator
+	tokenMu   sync.Mutex
+	token     ports.TokenStatus
+	tokenErr  error
+
+	sayMu sync.Mutex
+	say   ports.SayStats
+
 	ctx    context.Context
 	cancel context.CancelFunc
 }
@@ -69,9 +86,19 @@ func WithLogger(logger *logging.Logger) ClientOption {
 	}
 }
 
+// WithTokenValidator checks the token before connecting and every
+// TokenValidateInterval while connected.
+func WithTokenValidator(validator *TokenValidator) ClientOption {
+	return func(c *Client) {
+		c.validator = validator
+	}
+}
+
 func NewClient(username, oauth string, opts ...ClientOption) *Client {
 	c := &Client{
 		irc:        twitch.NewClient(username, "oauth:"+oauth),
+		oauth:      oauth,
+		token:      ports.TokenStatus{State: ports.TokenUnknown},
 		bucketSize: DefaultBucketSize,
 		refillMs:   DefaultRefillMs,
 	}
@@ -85,6 +112,9 @@ func NewClient(username, oauth string, opts ...ClientOption) *Client {
 	}
 
 	c.tokens = make(chan struct{}, c.bucketSize)
+	for _, bound := range SayWaitBuckets {
+		c.say.Buckets = append(c.say.Buckets, ports.SayBucket{UpperBound: bound})
+	}
 	c.setupHandlers()
 
 	return c
@@ -100,11 +130,14 @@ func (c *Client) setupHandlers() {
 	c.irc.OnPrivateMessage(func(msg twitch.PrivateMessage) {
 		if c.onMessage != nil {
 			c.onMessage(ports.ChatMessage{
-				ID:       msg.ID,
-				UserName: msg.User.Name,
-				UserID:   msg.User.ID,
-				Channel:  msg.Channel,
-				Text:     msg.Message,
+				ID:          msg.ID,
+				UserName:    msg.User.Name,
+				UserID:      msg.User.ID,
+				DisplayName: msg.User.DisplayName,
+				Channel:     msg.Channel,
+				Text:        msg.Message,
+				Badges:      msg.User.Badges,
+				Tags:        msg.Tags,
 			})
 		}
 	})
@@ -141,6 +174,13 @@ func (c *Client) Connect(ctx context.Context) error {
 
 	go c.runTokenRefiller()
 
+	if c.validator != nil {
+		if err := c.validateToken(); errors.Is(err, ports.ErrTokenInvalid) {
+			return err
+		}
+		go c.runTokenValidation()
+	}
+
 	delay := InitialRetryDelay
 	attempt := 0
 
@@ -150,6 +190,12 @@ func (c *Client) Connect(ctx context.Context) error {
 		if err == nil {
 			return nil
 		}
+		if err := c.tokenError(); err != nil {
+			return err
+		}
+		if errors.Is(err, twitch.ErrLoginAuthenticationFailed) {
+			return c.rejectToken(err)
+		}
 
 		select {
 		case <-c.ctx.Done():
@@ -179,6 +225,90 @@ func (c *Client) Connect(ctx context.Context) error {
 	}
 }
 
+// validateToken checks the token and records the result. Only a token Twitch
+// rejected returns an error wrapping ports.ErrTokenInvalid.
+func (c *Client) validateToken() error {
+	info, err := c.validator.Validate(c.ctx, c.oauth)
+	switch {
+	case errors.Is(err, ports.ErrTokenInvalid):
+		return c.rejectToken(err)
+	case err != nil:
+		c.logger.Warnf(c.ctx, "Could not validate the OAuth token: %v", err)
+		c.tokenMu.Lock()
+		c.token.Error = err.Error()
+		c.tokenMu.Unlock()
+		return err
+	}
+
+	status := ports.TokenStatus{
+		State:       ports.TokenValid,
+		Login:       info.Login,
+		Scopes:      info.Scopes,
+		ValidatedAt: time.Now().Format(time.RFC3339),
+	}
+	expiry := "never expires"
+	if !info.ExpiresAt.IsZero() {
+		status.ExpiresAt = info.ExpiresAt.Format(time.RFC3339)
+		expiry = "expires " + status.ExpiresAt
+	}
+	c.tokenMu.Lock()
+	c.token = status
+	c.tokenMu.Unlock()
+
+	c.logger.Infof(c.ctx, "OAuth token valid for %s, scopes: %s, %s", info.Login, strings.Join(info.Scopes, " "), expiry)
+	if !info.ExpiresAt.IsZero() && time.Until(info.ExpiresAt) < TokenExpiryWarning {
+		c.logger.Warnf(c.ctx, "OAuth token for %s expires at %s - generate a new one", info.Login, status.ExpiresAt)
+	}
+	return nil
+}
+
+// rejectToken records that Twitch rejected the token and returns the error
+// ending the connection.
+func (c *Client) rejectToken(err error) error {
+	if !errors.Is(err, ports.ErrTokenInvalid) {
+		err = fmt.Errorf("%w: %w", ports.ErrTokenInvalid, err)
+	}
+	c.tokenMu.Lock()
+	c.token = ports.TokenStatus{State: ports.TokenInvalid, ValidatedAt: time.Now().Format(time.RFC3339), Error: err.Error()}
+	c.tokenErr = err
+	c.tokenMu.Unlock()
+
+	c.logger.Errorf(c.ctx, "OAuth %v - enter a new token, reconnecting will not help", err)
+	return err
+}
+
+func (c *Client) tokenError() error {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	return c.tokenErr
+}
+
+// runTokenValidation validates the token every TokenValidateInterval and
+// disconnects once Twitch rejects it, which ends Connect.
+func (c *Client) runTokenValidation() {
+	ticker := time.NewTicker(TokenValidateInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.validateToken(); errors.Is(err, ports.ErrTokenInvalid) {
+				_ = c.irc.Disconnect()
+				return
+			}
+		}
+	}
+}
+
+// TokenStatus returns the result of the last token validation.
+func (c *Client) TokenStatus() ports.TokenStatus {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	return c.token
+}
+
 func (c *Client) runTokenRefiller() {
 	ticker := time.NewTicker(time.Duration(c.refillMs) * time.Millisecond)
 	defer ticker.Stop()
@@ -211,11 +341,16 @@ func (c *Client) Say(ctx context.Context, channel, message string) error {
 	timeout := time.NewTimer(SafeSayTimeout)
 	defer timeout.Stop()
 
+	start := time.Now()
 	select {
 	case <-c.tokens:
+		c.observeSayWait(time.Since(start))
 	case <-ctx.Done():
 		return ctx.Err()
 	case <-timeout.C:
+		c.sayMu.Lock()
+		c.say.Timeouts++
+		c.sayMu.Unlock()
 		c.logger.Warnf(ctx, "Say timeout after %v for: %s", SafeSayTimeout, message)
 		return ErrSayTimeout
 	}
@@ -224,6 +359,30 @@ func (c *Client) Say(ctx context.Context, channel, message string) error {
 	return nil
 }
 
+func (c *Client) observeSayWait(wait time.Duration) {
+	seconds := wait.Seconds()
+
+	c.sayMu.Lock()
+	defer c.sayMu.Unlock()
+	for i := range c.say.Buckets {
+		if seconds <= c.say.Buckets[i].UpperBound {
+			c.say.Buckets[i].Count++
+		}
+	}
+	c.say.WaitSum += seconds
+	c.say.Sent++
+}
+
+// SayStats returns how long messages waited for a rate-limit token and how
+// many gave up waiting.
+func (c *Client) SayStats() ports.SayStats {
+	c.sayMu.Lock()
+	defer c.sayMu.Unlock()
+	stats := c.say
+	stats.Buckets = slices.Clone(c.say.Buckets)
+	return stats
+}
+
 func (c *Client) OnMessage(handler func(ports.ChatMessage)) {
 	c.onMessage = handler
 }
@@ -0,0 +1,29 @@
+package twitch
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"streamgogambler/internal/adapters/logging"
+)
+
+func TestSayRecordsTokenWaits(t *testing.T) {
+	t.Parallel()
+
+	client := NewClient("testbot", "token", WithLogger(logging.New(logging.LevelError)))
+	client.tokens <- struct{}{}
+	require.NoError(t, client.Say(context.Background(), "foo", "hello"), "token available")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, client.Say(ctx, "foo", "hello"), context.Canceled, "no token")
+
+	stats := client.SayStats()
+	assert.Equal(t, 1, stats.Sent, "canceled messages are not counted")
+	assert.Zero(t, stats.Timeouts)
+	require.Len(t, stats.Buckets, len(SayWaitBuckets))
+	assert.Equal(t, 1, stats.Buckets[len(stats.Buckets)-1].Count, "buckets are cumulative")
+}
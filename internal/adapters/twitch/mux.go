@@ -0,0 +1,173 @@
+package twitch
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"streamgogambler/internal/ports"
+)
+
+// Mux shares one chat connection, and with it one rate-limit bucket, between
+// several channels. Each channel gets its own ports.ChatClient view that only
+// sees that channel's messages, bans and notices.
+type Mux struct {
+	client ports.ChatClient
+
+	mu       sync.Mutex
+	channels map[string]*channelClient
+	order    []*channelClient
+
+	connectOnce sync.Once
+	connected   chan struct{}
+	connectErr  error
+
+	disconnectOnce sync.Once
+	disconnectErr  error
+}
+
+func NewMux(client ports.ChatClient) *Mux {
+	m := &Mux{
+		client:    client,
+		channels:  make(map[string]*channelClient),
+		connected: make(chan struct{}),
+	}
+
+	client.OnMessage(func(msg ports.ChatMessage) {
+		if c := m.lookup(msg.Channel); c != nil && c.onMessage != nil {
+			c.onMessage(msg)
+		}
+	})
+	client.OnBan(func(event ports.BanEvent) {
+		if c := m.lookup(event.Channel); c != nil && c.onBan != nil {
+			c.onBan(event)
+		}
+	})
+	client.OnNotice(func(channel, message string) {
+		if c := m.lookup(channel); c != nil && c.onNotice != nil {
+			c.onNotice(channel, message)
+		}
+	})
+	client.OnConnect(func() {
+		for _, c := range m.all() {
+			if c.onConnect != nil {
+				go c.onConnect()
+			}
+		}
+	})
+	client.OnReconnect(func() bool {
+		frequent := false
+		for _, c := range m.all() {
+			if c.onReconnect != nil && c.onReconnect() {
+				frequent = true
+			}
+		}
+		return frequent
+	})
+
+	return m
+}
+
+// Channel returns the chat client view of channel.
+func (m *Mux) Channel(channel string) ports.ChatClient {
+	name := normalizeChannel(channel)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if c, ok := m.channels[name]; ok {
+		return c
+	}
+	c := &channelClient{mux: m, channel: name}
+	m.channels[name] = c
+	m.order = append(m.order, c)
+	return c
+}
+
+func (m *Mux) lookup(channel string) *channelClient {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.channels[normalizeChannel(channel)]
+}
+
+func (m *Mux) all() []*channelClient {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*channelClient(nil), m.order...)
+}
+
+// connect opens the shared connection on the first call; every caller blocks
+// until it ends, like a single client's Connect.
+func (m *Mux) connect(ctx context.Context) error {
+	m.connectOnce.Do(func() {
+		go func() {
+			m.connectErr = m.client.Connect(ctx)
+			close(m.connected)
+		}()
+	})
+
+	select {
+	case <-m.connected:
+		return m.connectErr
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *Mux) disconnect() error {
+	m.disconnectOnce.Do(func() {
+		m.disconnectErr = m.client.Disconnect()
+	})
+	return m.disconnectErr
+}
+
+func normalizeChannel(channel string) string {
+	return strings.ToLower(strings.TrimPrefix(channel, "#"))
+}
+
+type channelClient struct {
+	mux     *Mux
+	channel string
+
+	onMessage   func(ports.ChatMessage)
+	onConnect   func()
+	onBan       func(ports.BanEvent)
+	onReconnect func() bool
+	onNotice    func(channel, message string)
+}
+
+func (c *channelClient) Connect(ctx context.Context) error {
+	return c.mux.connect(ctx)
+}
+
+func (c *channelClient) Disconnect() error {
+	return c.mux.disconnect()
+}
+
+func (c *channelClient) Join(channel string) {
+	c.mux.client.Join(channel)
+}
+
+func (c *channelClient) Say(ctx context.Context, channel, message string) error {
+	return c.mux.client.Say(ctx, channel, message)
+}
+
+func (c *channelClient) OnMessage(handler func(ports.ChatMessage)) {
+	c.onMessage = handler
+}
+
+func (c *channelClient) OnConnect(handler func()) {
+	c.onConnect = handler
+}
+
+func (c *channelClient) OnBan(handler func(ports.BanEvent)) {
+	c.onBan = handler
+}
+
+func (c *channelClient) OnReconnect(handler func() bool) {
+	c.onReconnect = handler
+}
+
+func (c *channelClient) OnNotice(handler func(channel, message string)) {
+	c.onNotice = handler
+}
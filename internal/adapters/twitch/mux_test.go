@@ -0,0 +1,132 @@
+package twitch
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"streamgogambler/internal/ports"
+)
+
+type fakeChat struct {
+	mu          sync.Mutex
+	joined      []string
+	said        []string
+	connects    int
+	disconnects int
+	release     chan struct{}
+
+	onMessage   func(ports.ChatMessage)
+	onConnect   func()
+	onBan       func(ports.BanEvent)
+	onReconnect func() bool
+	onNotice    func(channel, message string)
+}
+
+func (f *fakeChat) Say(_ context.Context, channel, message string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.said = append(f.said, channel+": "+message)
+	return nil
+}
+
+func (f *fakeChat) Connect(_ context.Context) error {
+	f.mu.Lock()
+	f.connects++
+	f.mu.Unlock()
+	<-f.release
+	return nil
+}
+
+func (f *fakeChat) Disconnect() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.disconnects++
+	return nil
+}
+
+func (f *fakeChat) Join(channel string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.joined = append(f.joined, channel)
+}
+
+func (f *fakeChat) OnMessage(handler func(ports.ChatMessage))      { f.onMessage = handler }
+func (f *fakeChat) OnConnect(handler func())                       { f.onConnect = handler }
+func (f *fakeChat) OnBan(handler func(ports.BanEvent))             { f.onBan = handler }
+func (f *fakeChat) OnReconnect(handler func() bool)                { f.onReconnect = handler }
+func (f *fakeChat) OnNotice(handler func(channel, message string)) { f.onNotice = handler }
+
+func TestMuxRoutesEventsByChannel(t *testing.T) {
+	t.Parallel()
+
+	chat := &fakeChat{release: make(chan struct{})}
+	mux := NewMux(chat)
+
+	received := make(map[string][]string)
+	banned := make(map[string]int)
+	for _, channel := range []string{"foo", "#Bar"} {
+		view := mux.Channel(channel)
+		view.OnMessage(func(msg ports.ChatMessage) {
+			received[channel] = append(received[channel], msg.Text)
+		})
+		view.OnBan(func(ports.BanEvent) {
+			banned[channel]++
+		})
+	}
+
+	chat.onMessage(ports.ChatMessage{Channel: "foo", Text: "hello foo"})
+	chat.onMessage(ports.ChatMessage{Channel: "bar", Text: "hello bar"})
+	chat.onMessage(ports.ChatMessage{Channel: "baz", Text: "not joined"})
+	chat.onBan(ports.BanEvent{Channel: "bar"})
+
+	assert.Equal(t, []string{"hello foo"}, received["foo"])
+	assert.Equal(t, []string{"hello bar"}, received["#Bar"])
+	assert.Equal(t, 0, banned["foo"])
+	assert.Equal(t, 1, banned["#Bar"])
+	assert.Same(t, mux.Channel("bar"), mux.Channel("#Bar"), "views are shared per channel")
+}
+
+func TestMuxSharesOneConnection(t *testing.T) {
+	t.Parallel()
+
+	chat := &fakeChat{release: make(chan struct{})}
+	mux := NewMux(chat)
+	foo, bar := mux.Channel("foo"), mux.Channel("bar")
+
+	foo.Join("foo")
+	bar.Join("bar")
+	require.NoError(t, foo.Say(context.Background(), "foo", "!slots"))
+	require.NoError(t, bar.Say(context.Background(), "bar", "!slots"))
+
+	errs := make(chan error, 2)
+	go func() { errs <- foo.Connect(context.Background()) }()
+	go func() { errs <- bar.Connect(context.Background()) }()
+	close(chat.release)
+	require.NoError(t, <-errs)
+	require.NoError(t, <-errs)
+
+	require.NoError(t, foo.Disconnect())
+	require.NoError(t, bar.Disconnect())
+
+	assert.Equal(t, 1, chat.connects, "one connection")
+	assert.Equal(t, 1, chat.disconnects, "disconnected once")
+	assert.Equal(t, []string{"foo", "bar"}, chat.joined)
+	assert.Equal(t, []string{"foo: !slots", "bar: !slots"}, chat.said)
+}
+
+func TestMuxReconnectReportsAnyFrequentChannel(t *testing.T) {
+	t.Parallel()
+
+	chat := &fakeChat{release: make(chan struct{})}
+	mux := NewMux(chat)
+	calls := 0
+	mux.Channel("foo").OnReconnect(func() bool { calls++; return false })
+	mux.Channel("bar").OnReconnect(func() bool { calls++; return true })
+
+	assert.True(t, chat.onReconnect())
+	assert.Equal(t, 2, calls, "every channel tracks the reconnect")
+}
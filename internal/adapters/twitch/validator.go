@@ -0,0 +1,104 @@
+package twitch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"streamgogambler/internal/ports"
+)
+
+const (
+	DefaultAuthURL = "https://id.twitch.tv"
+
+	// TokenValidateInterval follows Twitch's requirement to validate tokens
+	// used for chat every hour.
+	TokenValidateInterval = time.Hour
+	TokenValidateTimeout  = 10 * time.Second
+
+	// TokenExpiryWarning is how long before expiry a token is reported as
+	// about to expire.
+	TokenExpiryWarning = 24 * time.Hour
+)
+
+// TokenInfo describes a token accepted by Twitch. ExpiresAt is zero for
+// tokens that do not expire.
+type TokenInfo struct {
+	Login     string
+	UserID    string
+	ClientID  string
+	Scopes    []string
+	ExpiresAt time.Time
+}
+
+// TokenValidator checks OAuth tokens against the validate endpoint of the
+// Twitch OAuth server.
+type TokenValidator struct {
+	baseURL string
+	client  *http.Client
+	now     func() time.Time
+}
+
+// NewTokenValidator returns a validator for the OAuth server at baseURL,
+// DefaultAuthURL when empty.
+func NewTokenValidator(baseURL string) *TokenValidator {
+	if baseURL == "" {
+		baseURL = DefaultAuthURL
+	}
+	return &TokenValidator{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: TokenValidateTimeout},
+		now:     time.Now,
+	}
+}
+
+type validateResponse struct {
+	ClientID  string   `json:"client_id"`
+	Login     string   `json:"login"`
+	UserID    string   `json:"user_id"`
+	Scopes    []string `json:"scopes"`
+	ExpiresIn int      `json:"expires_in"`
+	Message   string   `json:"message"`
+}
+
+// Validate asks Twitch about token. A rejected token returns an error
+// wrapping ports.ErrTokenInvalid; other errors mean the token could not be
+// checked.
+func (v *TokenValidator) Validate(ctx context.Context, token string) (TokenInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.baseURL+"/oauth2/validate", nil)
+	if err != nil {
+		return TokenInfo{}, err
+	}
+	req.Header.Set("Authorization", "OAuth "+strings.TrimPrefix(token, "oauth:"))
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return TokenInfo{}, fmt.Errorf("validating token: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var body validateResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&body); err != nil && resp.StatusCode == http.StatusOK {
+		return TokenInfo{}, fmt.Errorf("reading token validation: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		if body.Message == "" {
+			body.Message = "rejected by Twitch"
+		}
+		return TokenInfo{}, fmt.Errorf("%w: %s", ports.ErrTokenInvalid, body.Message)
+	case resp.StatusCode != http.StatusOK:
+		return TokenInfo{}, fmt.Errorf("validating token: %s", resp.Status)
+	}
+
+	info := TokenInfo{Login: body.Login, UserID: body.UserID, ClientID: body.ClientID, Scopes: body.Scopes}
+	if body.ExpiresIn > 0 {
+		info.ExpiresAt = v.now().Add(time.Duration(body.ExpiresIn) * time.Second).Truncate(time.Second)
+	}
+	return info, nil
+}
@@ -0,0 +1,79 @@
+package twitch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"streamgogambler/internal/adapters/logging"
+	"streamgogambler/internal/ports"
+)
+
+// newValidateStub serves Twitch's validate endpoint, accepting only "good".
+func newValidateStub(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path != "/oauth2/validate":
+			w.WriteHeader(http.StatusNotFound)
+		case r.Header.Get("Authorization") == "OAuth good":
+			_, _ = w.Write([]byte(`{"client_id":"abc","login":"testbot","scopes":["chat:read","chat:edit"],"user_id":"42","expires_in":3600}`))
+		case r.Header.Get("Authorization") == "OAuth broken":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"status":401,"message":"invalid access token"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestTokenValidator(t *testing.T) {
+	t.Parallel()
+
+	server := newValidateStub(t)
+	validator := NewTokenValidator(server.URL + "/")
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	validator.now = func() time.Time { return now }
+
+	info, err := validator.Validate(context.Background(), "oauth:good")
+	require.NoError(t, err, "valid token")
+	assert.Equal(t, TokenInfo{
+		Login:     "testbot",
+		UserID:    "42",
+		ClientID:  "abc",
+		Scopes:    []string{"chat:read", "chat:edit"},
+		ExpiresAt: now.Add(time.Hour),
+	}, info)
+
+	_, err = validator.Validate(context.Background(), "expired")
+	require.ErrorIs(t, err, ports.ErrTokenInvalid, "rejected token")
+	assert.ErrorContains(t, err, "invalid access token")
+
+	_, err = validator.Validate(context.Background(), "broken")
+	require.Error(t, err, "server error")
+	assert.NotErrorIs(t, err, ports.ErrTokenInvalid, "an unreachable server does not invalidate the token")
+}
+
+func TestConnectStopsOnInvalidToken(t *testing.T) {
+	t.Parallel()
+
+	server := newValidateStub(t)
+	client := NewClient("testbot", "expired",
+		WithLogger(logging.New(logging.LevelError)),
+		WithTokenValidator(NewTokenValidator(server.URL)))
+
+	err := client.Connect(context.Background())
+	require.ErrorIs(t, err, ports.ErrTokenInvalid, "no connection attempts with a rejected token")
+	_ = client.Disconnect()
+
+	status := client.TokenStatus()
+	assert.Equal(t, ports.TokenInvalid, status.State)
+	assert.Contains(t, status.Error, "invalid access token")
+}
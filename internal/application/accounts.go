@@ -0,0 +1,306 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"streamgogambler/internal/adapters/logging"
+	"streamgogambler/internal/ports"
+)
+
+var (
+	ErrUnknownAccount = errors.New("unknown account")
+	ErrAccountRunning = errors.New("account is already running")
+	ErrAccountStopped = errors.New("account is not running")
+	ErrNotStarted     = errors.New("account manager is not started")
+)
+
+// AccountFactory builds a fresh session of an account, with its own chat
+// connection; it is called every time the account is started.
+type AccountFactory func() *Supervisor
+
+type accountSession struct {
+	name    string
+	factory AccountFactory
+	current *Supervisor
+	running bool
+	// err is why the last session ended on its own.
+	err error
+}
+
+// AccountManager hosts the sessions of several Twitch accounts, which can be
+// started and stopped independently. Controls act on the selected account.
+type AccountManager struct {
+	logger *logging.Logger
+
+	mu         sync.Mutex
+	ctx        context.Context
+	sessions   []*accountSession
+	selected   int
+	allStopped chan error
+}
+
+func NewAccountManager(logger *logging.Logger) *AccountManager {
+	return &AccountManager{logger: logger, allStopped: make(chan error, 1)}
+}
+
+// AllStopped receives why the last running account ended on its own, once no
+// account is left running. Accounts stopped with StopAccount are not reported.
+func (m *AccountManager) AllStopped() <-chan error {
+	return m.allStopped
+}
+
+func (m *AccountManager) Add(name string, factory AccountFactory) {
+	m.mu.Lock()
+	m.sessions = append(m.sessions, &accountSession{name: name, factory: factory})
+	m.mu.Unlock()
+}
+
+// Start starts every account and blocks until ctx is canceled.
+func (m *AccountManager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	m.ctx = ctx
+	m.mu.Unlock()
+
+	for _, name := range m.Accounts() {
+		if err := m.StartAccount(name); err != nil {
+			return err
+		}
+	}
+
+	<-ctx.Done()
+	return nil
+}
+
+func (m *AccountManager) StartAccount(name string) error {
+	m.mu.Lock()
+	session, err := m.find(name)
+	if err == nil && m.ctx == nil {
+		err = ErrNotStarted
+	}
+	if err == nil && session.running {
+		err = ErrAccountRunning
+	}
+	if err != nil {
+		m.mu.Unlock()
+		return fmt.Errorf("starting %s: %w", name, err)
+	}
+	sup := session.factory()
+	sup.Attach(m.ctx)
+	session.current = sup
+	session.running = true
+	session.err = nil
+	ctx := m.ctx
+	m.mu.Unlock()
+
+	m.logger.Infof(ctx, "Starting account %s", session.name)
+	go func() {
+		err := sup.Run()
+		// The connection is gone, so the bots' loops and config
+		// subscriptions go with it.
+		sup.Stop()
+
+		m.mu.Lock()
+		stopped := session.current != sup || !session.running
+		if !stopped {
+			session.running = false
+			session.err = err
+		}
+		idle := !stopped && !m.anyRunning()
+		m.mu.Unlock()
+
+		if err != nil && !stopped && ctx.Err() == nil {
+			m.logger.Errorf(ctx, "Account %s stopped: %v", session.name, err)
+		}
+		if idle && ctx.Err() == nil {
+			if err == nil {
+				err = fmt.Errorf("account %s: %w", session.name, ErrAccountStopped)
+			}
+			select {
+			case m.allStopped <- err:
+			default:
+			}
+		}
+	}()
+	return nil
+}
+
+func (m *AccountManager) StopAccount(name string) error {
+	m.mu.Lock()
+	session, err := m.find(name)
+	if err == nil && !session.running {
+		err = ErrAccountStopped
+	}
+	if err != nil {
+		m.mu.Unlock()
+		return fmt.Errorf("stopping %s: %w", name, err)
+	}
+	sup := session.current
+	session.running = false
+	m.mu.Unlock()
+
+	sup.Stop()
+	m.logger.Infof(context.Background(), "Stopped account %s", session.name)
+	return nil
+}
+
+func (m *AccountManager) Stop() {
+	for _, name := range m.Accounts() {
+		if m.IsAccountRunning(name) {
+			_ = m.StopAccount(name)
+		}
+	}
+}
+
+// anyRunning reports whether an account is running; m.mu must be held.
+func (m *AccountManager) anyRunning() bool {
+	for _, session := range m.sessions {
+		if session.running {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *AccountManager) find(name string) (*accountSession, error) {
+	for _, session := range m.sessions {
+		if strings.EqualFold(session.name, name) {
+			return session, nil
+		}
+	}
+	return nil, ErrUnknownAccount
+}
+
+func (m *AccountManager) Accounts() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make([]string, 0, len(m.sessions))
+	for _, session := range m.sessions {
+		names = append(names, session.name)
+	}
+	return names
+}
+
+func (m *AccountManager) IsAccountRunning(name string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	session, err := m.find(name)
+	return err == nil && session.running
+}
+
+// SelectAccount makes name the target of the control methods.
+func (m *AccountManager) SelectAccount(name string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, session := range m.sessions {
+		if strings.EqualFold(session.name, name) {
+			m.selected = i
+			return true
+		}
+	}
+	return false
+}
+
+func (m *AccountManager) SelectedAccount() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.sessions) == 0 {
+		return ""
+	}
+	return m.sessions[m.selected].name
+}
+
+// current returns the latest session of the selected account, nil before it
+// was first started.
+func (m *AccountManager) current() *Supervisor {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.sessions) == 0 {
+		return nil
+	}
+	return m.sessions[m.selected].current
+}
+
+// GetStats returns the stats of the only account, or totals over all accounts
+// with the per-account stats in Accounts.
+func (m *AccountManager) GetStats() ports.BotStats {
+	m.mu.Lock()
+	accounts := make([]ports.BotStats, 0, len(m.sessions))
+	for _, session := range m.sessions {
+		stats := ports.BotStats{Status: "stopped", Username: session.name}
+		if session.current != nil {
+			stats = session.current.GetStats()
+		}
+		switch {
+		case !session.running && errors.Is(session.err, ports.ErrTokenInvalid):
+			stats.Status = ports.StatusTokenInvalid
+		case !session.running:
+			stats.Status = "stopped"
+		}
+		accounts = append(accounts, stats)
+	}
+	m.mu.Unlock()
+
+	switch len(accounts) {
+	case 0:
+		return ports.BotStats{Status: "stopped"}
+	case 1:
+		return accounts[0]
+	}
+
+	total := sumStats(accounts)
+	total.Accounts = accounts
+	return total
+}
+
+func (m *AccountManager) IsAutoSlotsEnabled() bool {
+	if sup := m.current(); sup != nil {
+		return sup.IsAutoSlotsEnabled()
+	}
+	return false
+}
+
+func (m *AccountManager) SetAutoSlots(enabled bool) {
+	if sup := m.current(); sup != nil {
+		sup.SetAutoSlots(enabled)
+	}
+}
+
+func (m *AccountManager) ExecuteCommand(command string) {
+	if sup := m.current(); sup != nil {
+		sup.ExecuteCommand(command)
+	}
+}
+
+func (m *AccountManager) ResetSession() {
+	if sup := m.current(); sup != nil {
+		sup.ResetSession()
+	}
+}
+
+func (m *AccountManager) Channels() []string {
+	if sup := m.current(); sup != nil {
+		return sup.Channels()
+	}
+	return nil
+}
+
+func (m *AccountManager) SelectedChannel() string {
+	if sup := m.current(); sup != nil {
+		return sup.SelectedChannel()
+	}
+	return ""
+}
+
+func (m *AccountManager) SelectChannel(channel string) bool {
+	if sup := m.current(); sup != nil {
+		return sup.SelectChannel(channel)
+	}
+	return false
+}
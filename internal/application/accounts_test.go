@@ -0,0 +1,186 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"streamgogambler/internal/adapters/config"
+	"streamgogambler/internal/adapters/logging"
+	"streamgogambler/internal/ports"
+)
+
+// idleChat is a ports.ChatClient whose connection stays open until the
+// context is canceled.
+type idleChat struct{}
+
+func (idleChat) Say(context.Context, string, string) error { return nil }
+func (idleChat) Connect(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+func (idleChat) Disconnect() error                  { return nil }
+func (idleChat) Join(string)                        {}
+func (idleChat) OnMessage(func(ports.ChatMessage))  {}
+func (idleChat) OnConnect(func())                   {}
+func (idleChat) OnBan(func(ports.BanEvent))         {}
+func (idleChat) OnReconnect(func() bool)            {}
+func (idleChat) OnNotice(func(channel, msg string)) {}
+
+func newTestAccountManager(builds map[string]int) *AccountManager {
+	m := NewAccountManager(logging.New(logging.LevelError))
+	for name, balance := range map[string]int{"main": 10000, "alt": 5000} {
+		m.Add(name, func() *Supervisor {
+			builds[name]++
+			bot := newChannelBot("foo", balance)
+			bot.chat = idleChat{}
+			return NewSupervisor(bot)
+		})
+	}
+	return m
+}
+
+func TestAccountManagerStartsAndStopsAccountsIndependently(t *testing.T) {
+	t.Parallel()
+
+	builds := make(map[string]int)
+	m := newTestAccountManager(builds)
+
+	require.ErrorIs(t, m.StartAccount("main"), ErrNotStarted)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- m.Start(ctx) }()
+
+	require.Eventually(t, func() bool {
+		return m.IsAccountRunning("main") && m.IsAccountRunning("alt")
+	}, time.Second, 10*time.Millisecond)
+
+	require.ErrorIs(t, m.StartAccount("alt"), ErrAccountRunning)
+	require.NoError(t, m.StopAccount("alt"))
+	assert.False(t, m.IsAccountRunning("alt"))
+	assert.True(t, m.IsAccountRunning("main"), "other account keeps running")
+	require.ErrorIs(t, m.StopAccount("alt"), ErrAccountStopped)
+	require.ErrorIs(t, m.StopAccount("nobody"), ErrUnknownAccount)
+
+	require.NoError(t, m.StartAccount("ALT"))
+	assert.True(t, m.IsAccountRunning("alt"))
+
+	cancel()
+	require.NoError(t, <-done)
+	m.Stop()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	assert.Equal(t, map[string]int{"main": 1, "alt": 2}, builds, "a fresh session on every start")
+}
+
+func TestAccountManagerAggregatesAccountStats(t *testing.T) {
+	t.Parallel()
+
+	m := NewAccountManager(logging.New(logging.LevelError))
+	main := newChannelBot("foo", 10000)
+	alt := newChannelBot("bar", 5000)
+	alt.config = config.NewStaticStore(ports.BotConfig{Username: "alt", Channel: "bar"})
+	m.Add("testuser", func() *Supervisor { return NewSupervisor(main) })
+	m.Add("alt", func() *Supervisor { return NewSupervisor(alt) })
+
+	stats := m.GetStats()
+	require.Len(t, stats.Accounts, 2)
+	assert.Equal(t, "stopped", stats.Accounts[0].Status, "never started")
+	assert.Equal(t, "alt", stats.Accounts[1].Username)
+
+	m.sessions[0].current, m.sessions[0].running = NewSupervisor(main), true
+	m.sessions[1].current, m.sessions[1].running = NewSupervisor(alt), true
+
+	stats = m.GetStats()
+	assert.Equal(t, 15000, stats.Balance, "total balance")
+	assert.Equal(t, "testuser,alt", stats.Username)
+	assert.Equal(t, "foo,bar", stats.Channel)
+	require.Len(t, stats.Accounts, 2)
+	assert.Equal(t, 10000, stats.Accounts[0].Balance)
+	assert.Equal(t, 5000, stats.Accounts[1].Balance)
+
+	require.True(t, m.SelectAccount("alt"))
+	m.SetAutoSlots(true)
+	assert.True(t, alt.IsAutoSlotsEnabled(), "controls act on the selected account")
+	assert.False(t, main.IsAutoSlotsEnabled())
+	assert.Equal(t, []string{"bar"}, m.Channels())
+}
+
+// rejectedChat is a ports.ChatClient whose token Twitch rejects.
+type rejectedChat struct{ idleChat }
+
+func (rejectedChat) Connect(context.Context) error {
+	return fmt.Errorf("%w: invalid access token", ports.ErrTokenInvalid)
+}
+
+func TestAccountManagerReportsInvalidToken(t *testing.T) {
+	t.Parallel()
+
+	m := NewAccountManager(logging.New(logging.LevelError))
+	m.Add("testuser", func() *Supervisor {
+		bot := newChannelBot("foo", 10000)
+		bot.chat = rejectedChat{}
+		sup := NewSupervisor(bot)
+		sup.ReportToken(func() ports.TokenStatus {
+			return ports.TokenStatus{State: ports.TokenInvalid, Error: "invalid access token"}
+		})
+		return sup
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = m.Start(ctx) }()
+
+	require.Eventually(t, func() bool {
+		return m.GetStats().Status == ports.StatusTokenInvalid
+	}, time.Second, 10*time.Millisecond, "account stopped by the rejected token")
+	assert.False(t, m.IsAccountRunning("testuser"))
+
+	stats := m.GetStats()
+	require.NotNil(t, stats.Token)
+	assert.Equal(t, ports.TokenInvalid, stats.Token.State, "token status in the stats")
+	m.Stop()
+}
+
+func TestAccountEndingOnItsOwnStopsItsBots(t *testing.T) {
+	t.Parallel()
+
+	m := NewAccountManager(logging.New(logging.LevelError))
+	var bots []*BotService
+	var mu sync.Mutex
+	m.Add("testuser", func() *Supervisor {
+		bot := newChannelBot("foo", 10000)
+		bot.chat = rejectedChat{}
+		mu.Lock()
+		bots = append(bots, bot)
+		mu.Unlock()
+		return NewSupervisor(bot)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = m.Start(ctx) }()
+
+	select {
+	case err := <-m.AllStopped():
+		require.ErrorIs(t, err, ports.ErrTokenInvalid, "why the last account stopped")
+	case <-time.After(time.Second):
+		t.Fatal("no report that every account stopped")
+	}
+	require.NoError(t, m.StartAccount("testuser"), "restart with a new token")
+	<-m.AllStopped()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, bots, 2, "a session per start")
+	for i, bot := range bots {
+		assert.Error(t, bot.ctx.Err(), "loops of session %d stopped", i+1)
+	}
+}
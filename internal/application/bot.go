@@ -5,13 +5,18 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
 	"streamgogambler/internal/adapters/logging"
 	"streamgogambler/internal/adapters/storage"
+	"streamgogambler/internal/domain/autoresponse"
 	"streamgogambler/internal/domain/gambling"
+	"streamgogambler/internal/domain/i18n"
+	"streamgogambler/internal/domain/parsing"
+	"streamgogambler/internal/domain/permissions"
 	"streamgogambler/internal/domain/wallet"
 	"streamgogambler/internal/ports"
 )
@@ -20,18 +25,45 @@ const (
 	SlotsJitterFraction     = 0.02
 	InitialBombsDelay       = 2 * time.Second
 	PostReconnectSlotsDelay = 3 * time.Second
+
+	SlotsBetTimeout          = 1 * time.Minute
+	GameBetTimeout           = 10 * time.Minute
+	PendingBetsSweepInterval = 5 * time.Second
+	FragmentTimeout          = 5 * time.Second
+
+	MinBalanceSyncGap = 30 * time.Second
+
+	// MaxKnownChatters bounds the login to user ID lookup used by !trust.
+	MaxKnownChatters = 10000
 )
 
-var SlotsInterval time.Duration
+// TrackedGames are the games whose results are kept in the game log.
+var TrackedGames = []wallet.Game{wallet.GameHeist, wallet.GameFFA, wallet.GameBoss}
+
+// ParseFailureKinds are the boss bot replies counted when they cannot be
+// parsed: balance and points replies, and the results of each game.
+var ParseFailureKinds = []string{"balance", "points", "slots", "heist", "ffa", "boss"}
+
+// chatter is the identity last seen behind a login.
+type chatter struct {
+	id          string
+	displayName string
+}
 
 type BotService struct {
 	config ports.ConfigStore
 	chat   ports.ChatClient
 	wallet *wallet.Wallet
+	escrow *wallet.Escrow
 	logger *logging.Logger
 
 	msgHandler *MessageHandler
 	cmdHandler *CommandHandler
+	dialect    *parsing.Dialect
+	messages   *i18n.Catalog
+	history    *gambling.History
+	games      *gambling.GameLog
+	transcript *storage.TranscriptStore
 
 	mu                 sync.Mutex
 	startTime          time.Time
@@ -42,67 +74,226 @@ type BotService struct {
 	didGreet           bool
 	lastMessageSent    string
 	userCmdTimes       map[string]time.Time
-	pendingArenaMsg    string
-	pendingArenaTime   time.Time
 	lastSlotsTime      time.Time
 	autoSlotsEnabled   bool
-	trustedUsers       map[string]bool
+	trustedUsers       []storage.TrustedUser
 	trustedStore       *storage.TrustedUsersStore
+	autoResponses      *autoresponse.Set
+	autoResponseStore  *storage.AutoResponsesStore
+	chatters           map[string]chatter
+	bossBotIDs         map[string]string
+	offlineReplay      bool
 	slotsOffTime       time.Time
 	slotsOffCancelChan chan struct{}
-
+	syncRequests       chan string
+	syncReschedule     chan struct{}
+	slotsInterval      time.Duration
+	slotsReschedule    chan struct{}
+	lastSyncRequest    time.Time
+	lastSync           time.Time
+	lastDrift          int
+	driftCorrections   int
+	strategy           gambling.Strategy
+	policy             *permissions.Policy
+	guardrails         gambling.Guardrails
+	sessionStarted     bool
+	sessionStart       int
+	guardrailState     string
+	counters           ports.Counters
+
+	now    func() time.Time
 	ctx    context.Context
 	cancel context.CancelFunc
 }
 
-func NewBotService(config ports.ConfigStore, chat ports.ChatClient, logger *logging.Logger, trustedStore *storage.TrustedUsersStore) *BotService {
-	trustedUsers, err := trustedStore.Load()
-	if err != nil {
-		logger.Warnf(context.Background(), "Could not load trusted users: %v, using defaults", err)
-		trustedUsers = make(map[string]bool)
+type BotOption func(*BotService)
+
+func WithDialect(d *parsing.Dialect) BotOption {
+	return func(s *BotService) {
+		if d != nil {
+			s.dialect = d
+		}
+	}
+}
+
+// WithMessages sets the catalog of chat replies.
+func WithMessages(c *i18n.Catalog) BotOption {
+	return func(s *BotService) {
+		if c != nil {
+			s.messages = c
+		}
+	}
+}
+
+// WithAutoResponses sets the auto-responses to boss bot messages; changes made
+// from chat are saved to store when it is not nil.
+func WithAutoResponses(rules []autoresponse.Rule, store *storage.AutoResponsesStore) BotOption {
+	return func(s *BotService) {
+		s.autoResponses = autoresponse.NewSet(rules)
+		s.autoResponseStore = store
+	}
+}
+
+// WithTranscript records every received chat message so the session can be
+// replayed.
+func WithTranscript(transcript *storage.TranscriptStore) BotOption {
+	return func(s *BotService) {
+		s.transcript = transcript
+	}
+}
+
+// WithOfflineReplay marks a bot fed recorded or simulated chat, whose boss bot
+// messages may carry no user ID and are then matched by name.
+func WithOfflineReplay() BotOption {
+	return func(s *BotService) {
+		s.offlineReplay = true
 	}
+}
 
-	if len(trustedUsers) == 0 {
-		if err := trustedStore.Save(trustedUsers); err != nil {
-			logger.Warnf(context.Background(), "Could not save default trusted users: %v", err)
+// WithClock replaces the wall clock used for slots intervals and bet
+// deadlines, e.g. with a simulated one.
+func WithClock(now func() time.Time) BotOption {
+	return func(s *BotService) {
+		if now != nil {
+			s.now = now
+			s.escrow.SetClock(now)
 		}
 	}
+}
+
+func WithLedger(ledger *storage.LedgerStore) BotOption {
+	return func(s *BotService) {
+		if ledger == nil {
+			return
+		}
+		s.wallet.SetRecorder(func(e wallet.Entry) {
+			if err := ledger.Append(e); err != nil {
+				s.logger.Warnf(s.ctx, "Could not write ledger entry: %v", err)
+			}
+		})
+	}
+}
+
+func NewBotService(config ports.ConfigStore, chat ports.ChatClient, logger *logging.Logger, trustedStore *storage.TrustedUsersStore, opts ...BotOption) *BotService {
+	var trustedUsers []storage.TrustedUser
+	var bossBotIDs map[string]string
+	if trustedStore != nil {
+		loaded, err := trustedStore.Load()
+		if err != nil {
+			logger.Warnf(context.Background(), "Could not load trusted users: %v, using defaults", err)
+		} else {
+			trustedUsers = loaded
+		}
+
+		if len(trustedUsers) == 0 {
+			if err := trustedStore.Save(trustedUsers); err != nil {
+				logger.Warnf(context.Background(), "Could not save default trusted users: %v", err)
+			}
+		}
+
+		bossBotIDs, err = trustedStore.LoadBossBots()
+		if err != nil {
+			logger.Warnf(context.Background(), "Could not load learned boss bot IDs: %v", err)
+		}
+	}
+
+	strategy, err := gambling.ParseStrategy(config.GetConfig().BettingStrategy)
+	if err != nil {
+		logger.Warnf(context.Background(), "Invalid betting strategy: %v, using fixed", err)
+		strategy = gambling.FixedStrategy{}
+	}
+	policy, err := permissions.ParsePolicy(config.GetConfig().CommandPermissions)
+	if err != nil {
+		logger.Warnf(context.Background(), "Invalid command permissions: %v, using defaults", err)
+		policy = permissions.Policy{}
+	}
 
-	SlotsInterval = time.Duration(config.GetConfig().AutoSlotsInterval) * time.Minute
+	cfg := config.GetConfig()
+	guardrails := gambling.Guardrails{
+		Reserve:    cfg.ReserveFloor,
+		StopLoss:   cfg.StopLoss,
+		TakeProfit: cfg.TakeProfit,
+	}
 
-	return &BotService{
+	w := wallet.New(0)
+	s := &BotService{
 		config:           config,
 		chat:             chat,
-		wallet:           wallet.New(0),
+		wallet:           w,
+		escrow:           wallet.NewEscrow(w),
 		logger:           logger,
+		dialect:          parsing.DefaultDialect(),
+		messages:         i18n.Default(),
+		history:          gambling.NewHistory(gambling.DefaultHistorySize),
+		games:            gambling.NewGameLog(gambling.DefaultGameLogSize),
+		strategy:         strategy,
+		policy:           &policy,
+		guardrails:       guardrails,
 		userCmdTimes:     make(map[string]time.Time),
 		trustedUsers:     trustedUsers,
 		trustedStore:     trustedStore,
+		bossBotIDs:       bossBotIDs,
+		autoResponses:    autoresponse.NewSet(nil),
+		chatters:         make(map[string]chatter),
 		autoSlotsEnabled: config.GetConfig().AutoSlotsEnabled,
+		syncRequests:     make(chan string, 1),
+		syncReschedule:   make(chan struct{}, 1),
+		slotsInterval:    time.Duration(config.GetConfig().AutoSlotsInterval) * time.Minute,
+		slotsReschedule:  make(chan struct{}, 1),
+		now:              time.Now,
 	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
 }
 
 func (s *BotService) Start(ctx context.Context) error {
-	s.ctx, s.cancel = context.WithCancel(ctx)
-	s.startTime = time.Now()
+	s.Attach(ctx)
+	return s.Run()
+}
 
+// Run starts the background loops and connects an attached bot, blocking
+// until the connection ends.
+func (s *BotService) Run() error {
 	cfg := s.config.GetConfig()
 
+	go s.runSlotsLoop()
+
+	go s.runUserCmdTimesCleanup()
+
+	go s.runPendingBetsSweeper()
+
+	go s.runBalanceSyncLoop()
+
+	go s.runAutoResponsesReload()
+
+	s.chat.Join(cfg.Channel)
+	return s.chat.Connect(s.ctx)
+}
+
+// Attach creates the handlers and registers them with the chat client without
+// connecting or starting the background loops.
+func (s *BotService) Attach(ctx context.Context) {
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	s.startTime = s.clock()
+
 	s.msgHandler = NewMessageHandler(s, s.logger)
 	s.cmdHandler = NewCommandHandler(s, s.config, s.logger)
 
+	unsubscribe := s.config.Subscribe(s.onConfigChange)
+	go func() {
+		<-s.ctx.Done()
+		unsubscribe()
+	}()
+
 	s.chat.OnConnect(s.onConnect)
 	s.chat.OnMessage(s.onMessage)
 	s.chat.OnBan(s.onBan)
 	s.chat.OnReconnect(s.trackReconnect)
 	s.chat.OnNotice(s.onNotice)
-
-	go s.runSlotsLoop()
-
-	go s.runUserCmdTimesCleanup()
-
-	s.chat.Join(cfg.Channel)
-	return s.chat.Connect(s.ctx)
 }
 
 func (s *BotService) Stop() {
@@ -121,13 +312,18 @@ func (s *BotService) onConnect() {
 	if !s.hasGreeted() || cfg.GreetOnReconnect {
 		s.SafeSay(cfg.Channel, cfg.ConnectMessage)
 		time.Sleep(InitialBombsDelay)
-		s.SafeSay(cfg.Channel, "!bombs")
+		s.sendBalanceRequest(cfg.Channel)
 		s.setGreeted(true)
 	}
 }
 
 func (s *BotService) onMessage(msg ports.ChatMessage) {
 	s.incMessagesRecv()
+	if s.transcript != nil {
+		if err := s.transcript.Append(storage.NewTranscriptEntry(s.clock(), msg)); err != nil {
+			s.logger.Warnf(s.ctx, "Could not write transcript: %v", err)
+		}
+	}
 	s.msgHandler.HandleMessage(msg)
 }
 
@@ -151,14 +347,17 @@ func (s *BotService) onNotice(channel, message string) {
 func (s *BotService) runSlotsLoop() {
 	cfg := s.config.GetConfig()
 	time.Sleep(2 * InitialBombsDelay)
-	s.SafeSay(cfg.Channel, "!slots")
+	s.autoSay(cfg.Channel, "!slots")
 	for {
-		d := jitterDuration(SlotsInterval, SlotsJitterFraction)
+		d := jitterDuration(s.SlotsInterval(), SlotsJitterFraction)
 		t := time.NewTimer(d)
 		select {
 		case <-s.ctx.Done():
 			t.Stop()
 			return
+		case <-s.slotsReschedule:
+			t.Stop()
+			continue
 		case <-t.C:
 		}
 		select {
@@ -167,17 +366,23 @@ func (s *BotService) runSlotsLoop() {
 		case <-time.After(PostReconnectSlotsDelay):
 		}
 
-		if !s.IsAutoSlotsEnabled() {
-			continue
-		}
+		s.PlayAutoSlots()
+	}
+}
 
-		if !s.canPlaySlots() {
-			s.logger.Debugf(s.ctx, "Slots cooldown active, skipping this cycle")
-			continue
-		}
+// PlayAutoSlots sends !slots when auto slots are enabled and the slots
+// interval has passed since the last result.
+func (s *BotService) PlayAutoSlots() {
+	if !s.IsAutoSlotsEnabled() {
+		return
+	}
 
-		s.SafeSay(cfg.Channel, "!slots")
+	if !s.canPlaySlots() {
+		s.logger.Debugf(s.ctx, "Slots cooldown active, skipping this cycle")
+		return
 	}
+
+	s.autoSay(s.config.GetConfig().Channel, "!slots")
 }
 
 func (s *BotService) SafeSay(channel, message string) {
@@ -188,73 +393,150 @@ func (s *BotService) SafeSay(channel, message string) {
 
 	cfg := s.config.GetConfig()
 
+	var bet wallet.Bet
 	lower := strings.ToLower(message)
-	if strings.HasPrefix(lower, "!slots") || strings.HasPrefix(lower, "!heist") || strings.HasPrefix(lower, "!ffa") {
-		ok, normalized := s.handleOwnCommands(message, cfg)
+	if strings.HasPrefix(lower, "!slots") || strings.HasPrefix(lower, "!heist") || strings.HasPrefix(lower, "!ffa") || strings.HasPrefix(lower, "!boss") {
+		ok, normalized, placed := s.handleOwnCommands(message, cfg)
 		if !ok {
 			return
 		}
 		message = normalized
+		bet = placed
 	}
 
 	if err := s.chat.Say(s.ctx, channel, message); err != nil {
 		s.logger.Warnf(s.ctx, "Failed to send message: %v", err)
+		if bet.ID != 0 {
+			s.escrow.Rollback(bet.ID, "send failed: "+message)
+			s.logger.Infof(s.ctx, "Refunded %d bombs for unsent %s", bet.Amount, message)
+		}
 		return
 	}
 
+	if strings.EqualFold(message, s.Dialect().Balance.Command) {
+		s.escrow.SyncRequested()
+	}
 	s.logger.Infof(s.ctx, "Sent: %s", message)
 	s.setLastMessage(message)
 	s.incMessagesSent()
 }
 
-func (s *BotService) handleOwnCommands(cmd string, cfg ports.BotConfig) (bool, string) {
+// autoSay sends a message on the bot's own initiative; it is dropped while a
+// stop-loss or take-profit guardrail is tripped.
+func (s *BotService) autoSay(channel, message string) {
+	if state := s.GuardrailState(); state != gambling.GuardrailOK {
+		s.logger.Debugf(s.ctx, "Paused by %s guardrail, not sending: %s", state, message)
+		return
+	}
+	s.SafeSay(channel, message)
+}
+
+func (s *BotService) handleOwnCommands(cmd string, cfg ports.BotConfig) (bool, string, wallet.Bet) {
 	parts := strings.Fields(cmd)
 	if len(parts) == 0 {
-		return true, cmd
+		return true, cmd, wallet.Bet{}
 	}
 
 	base := strings.ToLower(parts[0])
 	switch base {
 	case "!ffa":
-		if s.wallet.Spend(cfg.ArenaCost) {
-			s.logger.Infof(s.ctx, "Bot sent !ffa - deducted %d bombs", cfg.ArenaCost)
-			return true, "!ffa"
+		if !s.strategyPlays(wallet.GameFFA, cfg.ArenaCost) || !s.withinReserve(cfg.ArenaCost) {
+			return false, cmd, wallet.Bet{}
+		}
+		if bet, ok := s.escrow.Reserve(wallet.GameFFA, cfg.ArenaCost, "!ffa", GameBetTimeout); ok {
+			s.logger.Infof(s.ctx, "Bot sent !ffa - reserved %d bombs", cfg.ArenaCost)
+			return true, "!ffa", bet
 		}
 		s.logger.Warnf(s.ctx, "Not enough bombs for !ffa (need %d, have %d)", cfg.ArenaCost, s.wallet.GetBalance())
-		return false, cmd
+		return false, cmd, wallet.Bet{}
+
+	case "!boss":
+		if cfg.BossCost > 0 && (!s.strategyPlays(wallet.GameBoss, cfg.BossCost) || !s.withinReserve(cfg.BossCost)) {
+			return false, cmd, wallet.Bet{}
+		}
+		if bet, ok := s.escrow.Reserve(wallet.GameBoss, cfg.BossCost, "!boss", GameBetTimeout); ok {
+			s.logger.Infof(s.ctx, "Bot sent !boss - reserved %d bombs", cfg.BossCost)
+			return true, "!boss", bet
+		}
+		s.logger.Warnf(s.ctx, "Not enough bombs for !boss (need %d, have %d)", cfg.BossCost, s.wallet.GetBalance())
+		return false, cmd, wallet.Bet{}
 
 	case "!slots":
-		if s.wallet.Spend(cfg.SlotsCost) {
-			s.logger.Infof(s.ctx, "Bot sent !slots - deducted %d bombs", cfg.SlotsCost)
-			return true, "!slots"
+		if !s.strategyPlays(wallet.GameSlots, cfg.SlotsCost) || !s.withinReserve(cfg.SlotsCost) {
+			return false, cmd, wallet.Bet{}
+		}
+		if bet, ok := s.escrow.Reserve(wallet.GameSlots, cfg.SlotsCost, "!slots", SlotsBetTimeout); ok {
+			s.logger.Infof(s.ctx, "Bot sent !slots - reserved %d bombs", cfg.SlotsCost)
+			return true, "!slots", bet
 		}
 		s.logger.Warnf(s.ctx, "Not enough bombs for !slots (need %d, have %d)", cfg.SlotsCost, s.wallet.GetBalance())
-		return false, cmd
+		return false, cmd, wallet.Bet{}
 
 	case "!heist":
-		amount := cfg.DefaultHeist
+		amount := 0
 		if len(parts) >= 2 {
 			if v, err := fmt.Sscanf(parts[1], "%d", &amount); err != nil || v != 1 {
-				amount = cfg.DefaultHeist
+				amount = 0
 			}
 		}
+		if amount <= 0 {
+			amount = s.stakeFor(wallet.GameHeist, cfg.DefaultHeist)
+		}
 
 		amount, _ = gambling.ValidateHeistAmount(amount)
 		if amount <= 0 {
 			s.logger.Warnf(s.ctx, "Invalid heist amount: %d, skipping", amount)
-			return false, cmd
+			return false, cmd, wallet.Bet{}
+		}
+		if !s.withinReserve(amount) {
+			return false, cmd, wallet.Bet{}
 		}
 
-		if s.wallet.Spend(amount) {
-			norm := fmt.Sprintf("!heist %d", amount)
-			s.logger.Infof(s.ctx, "Bot sent %s - deducted %d bombs", norm, amount)
-			return true, norm
+		norm := fmt.Sprintf("!heist %d", amount)
+		if bet, ok := s.escrow.Reserve(wallet.GameHeist, amount, norm, GameBetTimeout); ok {
+			s.logger.Infof(s.ctx, "Bot sent %s - reserved %d bombs", norm, amount)
+			return true, norm, bet
 		}
 		s.logger.Warnf(s.ctx, "Not enough bombs for heist (need %d, have %d)", amount, s.wallet.GetBalance())
-		return false, cmd
+		return false, cmd, wallet.Bet{}
+	}
+
+	return true, cmd, wallet.Bet{}
+}
+
+func (s *BotService) stakeFor(game wallet.Game, base int) int {
+	var history []gambling.Result
+	if s.history != nil {
+		history = s.history.Results()
 	}
+	return s.Strategy().Stake(gambling.Opportunity{
+		Game:      string(game),
+		Balance:   s.wallet.GetBalance(),
+		BaseStake: base,
+		History:   history,
+	})
+}
 
-	return true, cmd
+// strategyPlays reports whether the strategy wants to join a fixed-cost game.
+func (s *BotService) strategyPlays(game wallet.Game, cost int) bool {
+	if s.stakeFor(game, cost) >= cost {
+		return true
+	}
+	s.logger.Infof(s.ctx, "Strategy %s skipped %s", s.Strategy().Name(), game)
+	return false
+}
+
+func (s *BotService) withinReserve(amount int) bool {
+	s.mu.Lock()
+	guardrails := s.guardrails
+	s.mu.Unlock()
+
+	balance := s.wallet.GetBalance()
+	if guardrails.CanSpend(balance, amount) {
+		return true
+	}
+	s.logger.Warnf(s.ctx, "Refusing to spend %d bombs: balance %d would drop below the reserve of %d", amount, balance, guardrails.Reserve)
+	return false
 }
 
 func (s *BotService) retrySend(channel string) {
@@ -268,22 +550,64 @@ func (s *BotService) retrySend(channel string) {
 }
 
 func (s *BotService) GetStats() ports.BotStats {
+	guardrail := s.checkGuardrails(false)
+	equity := s.equity()
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	cfg := s.config.GetConfig()
-	uptime := time.Since(s.startTime).Truncate(time.Second)
+	uptime := s.clock().Sub(s.startTime).Truncate(time.Second)
+
+	stats := ports.BotStats{
+		Status:           "ok",
+		Uptime:           uptime.String(),
+		UptimeSeconds:    math.Floor(uptime.Seconds()),
+		Balance:          s.wallet.GetBalance(),
+		MessagesSent:     s.messagesSent,
+		MessagesRecv:     s.messagesRecv,
+		ReconnectCount:   s.reconnectCount,
+		Channel:          cfg.Channel,
+		Username:         cfg.Username,
+		LastDrift:        s.lastDrift,
+		DriftCorrections: s.driftCorrections,
+		Strategy:         gambling.StrategyFixed,
+		Guardrail:        guardrail,
+		ReserveFloor:     s.guardrails.Reserve,
+		Counters:         s.countersSnapshot(),
+	}
+	if s.sessionStarted {
+		stats.SessionProfit = equity - s.sessionStart
+	}
+	if s.strategy != nil {
+		stats.Strategy = s.strategy.Name()
+	}
+	if !s.lastSync.IsZero() {
+		stats.LastSync = s.lastSync.Format(time.RFC3339)
+	}
+	for _, game := range TrackedGames {
+		if summary := s.GameSummary(game); summary.Seen > 0 {
+			if stats.Games == nil {
+				stats.Games = make(map[string]ports.GameStats)
+			}
+			stats.Games[string(game)] = gameStats(summary)
+		}
+	}
+	return stats
+}
 
-	return ports.BotStats{
-		Status:         "ok",
-		Uptime:         uptime.String(),
-		UptimeSeconds:  math.Floor(uptime.Seconds()),
-		Balance:        s.wallet.GetBalance(),
-		MessagesSent:   s.messagesSent,
-		MessagesRecv:   s.messagesRecv,
-		ReconnectCount: s.reconnectCount,
-		Channel:        cfg.Channel,
-		Username:       cfg.Username,
+func gameStats(s gambling.GameSummary) ports.GameStats {
+	return ports.GameStats{
+		Seen:     s.Seen,
+		Joined:   s.Joined,
+		Survived: s.Survived,
+		Staked:   s.Staked,
+		Returned: s.Returned,
+		WinRate:  s.WinRate(),
+		AvgCrew:  s.AvgCrew,
+
+		Unresolved:   s.Unresolved,
+		AvgPlacement: s.AvgPlacement,
 	}
 }
 
@@ -296,7 +620,7 @@ func (s *BotService) ExecuteCommand(command string) {
 		if len(parts) > 0 {
 			cmdName := strings.ToLower(parts[0])
 			if s.cmdHandler.IsInternalCommand(cmdName) {
-				s.cmdHandler.HandleCommand(cfg.Username, cfg.Channel, command)
+				s.cmdHandler.HandleCommand(ports.ChatMessage{UserName: cfg.Username, Channel: cfg.Channel}, command)
 				s.logger.Infof(s.ctx, "Executed internal command: %s", command)
 				return
 			}
@@ -310,6 +634,10 @@ func (s *BotService) Wallet() *wallet.Wallet {
 	return s.wallet
 }
 
+func (s *BotService) Escrow() *wallet.Escrow {
+	return s.escrow
+}
+
 func (s *BotService) Config() ports.ConfigStore {
 	return s.config
 }
@@ -318,6 +646,194 @@ func (s *BotService) Logger() *logging.Logger {
 	return s.logger
 }
 
+func (s *BotService) Dialect() *parsing.Dialect {
+	if s.dialect == nil {
+		return parsing.DefaultDialect()
+	}
+	return s.dialect
+}
+
+// Messages returns the catalog of chat replies.
+func (s *BotService) Messages() *i18n.Catalog {
+	if s.messages == nil {
+		return i18n.Default()
+	}
+	return s.messages
+}
+
+func (s *BotService) Strategy() gambling.Strategy {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.strategy == nil {
+		return gambling.FixedStrategy{}
+	}
+	return s.strategy
+}
+
+func (s *BotService) SetStrategy(strategy gambling.Strategy) {
+	s.mu.Lock()
+	s.strategy = strategy
+	s.mu.Unlock()
+}
+
+// GuardrailState returns the tripped stop-loss/take-profit guardrail, or
+// GuardrailOK. Once tripped it stays so until ResetSession.
+func (s *BotService) GuardrailState() string {
+	return s.checkGuardrails(true)
+}
+
+// checkGuardrails checks the guardrails against the balance including stakes
+// still in escrow, so a pending bet is not counted as lost. With trip unset
+// it only reports the state, as the stats do, without tripping a guardrail.
+func (s *BotService) checkGuardrails(trip bool) string {
+	equity := s.equity()
+
+	s.mu.Lock()
+	if !s.sessionStarted {
+		s.mu.Unlock()
+		return gambling.GuardrailOK
+	}
+	if s.guardrailState != "" && s.guardrailState != gambling.GuardrailOK {
+		state := s.guardrailState
+		s.mu.Unlock()
+		return state
+	}
+	start := s.sessionStart
+	state := s.guardrails.Check(start, equity)
+	if !trip {
+		s.mu.Unlock()
+		return state
+	}
+	s.guardrailState = state
+	s.mu.Unlock()
+
+	if state != gambling.GuardrailOK {
+		s.logger.Warnf(s.ctx, "Guardrail %s tripped (session start %d, balance %d) - automated games paused", state, start, equity)
+	}
+	return state
+}
+
+// equity is the balance plus the stakes of bets still pending.
+func (s *BotService) equity() int {
+	return s.wallet.GetBalance() + s.escrow.Reserved()
+}
+
+// ResetSession starts a new session at the current balance and resumes
+// automated games.
+func (s *BotService) ResetSession() {
+	balance := s.equity()
+
+	s.mu.Lock()
+	s.sessionStarted = true
+	s.sessionStart = balance
+	s.guardrailState = gambling.GuardrailOK
+	s.mu.Unlock()
+
+	s.logger.Infof(s.ctx, "Session reset at %d bombs", balance)
+}
+
+func (s *BotService) SessionProfit() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.sessionStarted {
+		return 0
+	}
+	return s.equity() - s.sessionStart
+}
+
+func (s *BotService) RecordResult(game wallet.Game, stake, payout int) {
+	s.mu.Lock()
+	s.counters.Spent = addCount(s.counters.Spent, string(game), stake)
+	s.counters.Won = addCount(s.counters.Won, string(game), payout)
+	s.mu.Unlock()
+
+	if s.history == nil || stake <= 0 {
+		return
+	}
+	s.history.Add(gambling.Result{Game: string(game), Stake: stake, Return: payout})
+}
+
+// RecordUnresolved counts the stake of a game whose outcome could not be
+// read; the balance sync settles what it returned.
+func (s *BotService) RecordUnresolved(game wallet.Game, stake int) {
+	s.mu.Lock()
+	s.counters.Spent = addCount(s.counters.Spent, string(game), stake)
+	s.mu.Unlock()
+}
+
+// RecordSlotsOutcome counts a slots roll by its outcome.
+func (s *BotService) RecordSlotsOutcome(outcome parsing.SlotsOutcome) {
+	s.mu.Lock()
+	s.counters.SlotsOutcomes = addCount(s.counters.SlotsOutcomes, string(outcome), 1)
+	s.mu.Unlock()
+}
+
+// RecordParseFailure counts a boss bot reply of one of ParseFailureKinds
+// that could not be parsed.
+func (s *BotService) RecordParseFailure(kind string) {
+	s.mu.Lock()
+	s.counters.ParseFailures = addCount(s.counters.ParseFailures, kind, 1)
+	s.mu.Unlock()
+}
+
+func addCount(counts map[string]int, key string, n int) map[string]int {
+	if counts == nil {
+		counts = make(map[string]int)
+	}
+	counts[key] += max(n, 0)
+	return counts
+}
+
+// countersSnapshot copies the running totals, listing every game, slots
+// outcome and parse failure kind so their series exist from the start.
+// Callers hold s.mu.
+func (s *BotService) countersSnapshot() ports.Counters {
+	games := append([]wallet.Game{wallet.GameSlots}, TrackedGames...)
+	c := ports.Counters{
+		Spent:         make(map[string]int, len(games)),
+		Won:           make(map[string]int, len(games)),
+		SlotsOutcomes: make(map[string]int),
+		ParseFailures: make(map[string]int, len(ParseFailureKinds)),
+		Reconnects:    s.counters.Reconnects,
+	}
+	for _, game := range games {
+		c.Spent[string(game)] = s.counters.Spent[string(game)]
+		c.Won[string(game)] = s.counters.Won[string(game)]
+	}
+	for outcome := range parsing.DefaultPayoutTable() {
+		c.SlotsOutcomes[string(outcome)] = s.counters.SlotsOutcomes[string(outcome)]
+	}
+	for _, kind := range ParseFailureKinds {
+		c.ParseFailures[kind] = s.counters.ParseFailures[kind]
+	}
+	return c
+}
+
+// RecordGame adds a finished game to the game log, whether or not we joined it.
+func (s *BotService) RecordGame(r gambling.GameRecord) {
+	if s.games == nil {
+		return
+	}
+	if r.Time.IsZero() {
+		r.Time = s.clock()
+	}
+	s.games.Add(r)
+}
+
+func (s *BotService) GameSummary(game wallet.Game) gambling.GameSummary {
+	if s.games == nil {
+		return gambling.GameSummary{}
+	}
+	return s.games.Summary(string(game))
+}
+
+func (s *BotService) clock() time.Time {
+	if s.now == nil {
+		return time.Now()
+	}
+	return s.now()
+}
+
 func (s *BotService) hasGreeted() bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -360,6 +876,7 @@ func (s *BotService) trackReconnect() bool {
 
 	now := time.Now()
 	s.reconnectCount++
+	s.counters.Reconnects++
 
 	if !s.lastReconnect.IsZero() && now.Sub(s.lastReconnect) > 10*time.Minute {
 		s.reconnectCount = 1
@@ -369,21 +886,23 @@ func (s *BotService) trackReconnect() bool {
 	return s.reconnectCount > 5
 }
 
-func (s *BotService) IsUserRateLimited(username string) bool {
+// IsOnCooldown reports whether the user ran the command less than cooldown
+// ago, and otherwise starts a new cooldown.
+func (s *BotService) IsOnCooldown(username, command string, cooldown time.Duration) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	const userCommandRateLimit = 2 * time.Second
-	now := time.Now()
-	lowerName := strings.ToLower(username)
+	now := s.clock()
+	key := strings.ToLower(username) + " " + command
 
-	if lastTime, exists := s.userCmdTimes[lowerName]; exists {
-		if now.Sub(lastTime) < userCommandRateLimit {
-			return true
-		}
+	if readyAt, exists := s.userCmdTimes[key]; exists && now.Before(readyAt) {
+		return true
 	}
 
-	s.userCmdTimes[lowerName] = now
+	if s.userCmdTimes == nil {
+		s.userCmdTimes = make(map[string]time.Time)
+	}
+	s.userCmdTimes[key] = now.Add(cooldown)
 	return false
 }
 
@@ -406,47 +925,136 @@ func (s *BotService) cleanupUserCmdTimes() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	const maxAge = 5 * time.Minute
-	now := time.Now()
+	now := s.clock()
+	for key, readyAt := range s.userCmdTimes {
+		if now.After(readyAt) {
+			delete(s.userCmdTimes, key)
+		}
+	}
+}
 
-	for user, lastTime := range s.userCmdTimes {
-		if now.Sub(lastTime) > maxAge {
-			delete(s.userCmdTimes, user)
+func (s *BotService) runPendingBetsSweeper() {
+	ticker := time.NewTicker(PendingBetsSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.msgHandler.ExpireFragments()
+			for _, bet := range s.escrow.Expire() {
+				s.logger.Warnf(s.ctx, "No reply to %s within %v - refunded %d bombs", bet.Message, bet.Deadline.Sub(bet.PlacedAt), bet.Amount)
+			}
 		}
 	}
 }
 
-func (s *BotService) SetPendingArenaMsg(msg string) {
-	s.mu.Lock()
-	s.pendingArenaMsg = msg
-	s.pendingArenaTime = time.Now()
-	s.mu.Unlock()
+// RequestBalanceSync asks the sync loop to query the boss bot for the real
+// balance. Requests closer than MinBalanceSyncGap to the previous one are
+// deferred until the gap has passed.
+func (s *BotService) RequestBalanceSync(reason string) {
+	select {
+	case s.syncRequests <- reason:
+	default:
+	}
 }
 
-func (s *BotService) GetPendingArenaMsg() string {
+// SyncBalance replaces the tracked balance with the one reported by the boss
+// bot and returns the drift between them. Pending bets are covered by the
+// reported balance and are no longer refunded.
+func (s *BotService) SyncBalance(actual int, message string) int {
+	drift := s.escrow.Sync(actual, wallet.Reason{Game: wallet.GameSync, Message: message})
+	equity := s.equity()
+
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.lastSync = s.clock()
+	s.lastDrift = drift
+	if drift != 0 {
+		s.driftCorrections++
+	}
+	if !s.sessionStarted {
+		s.sessionStarted = true
+		s.sessionStart = equity
+	}
+	s.mu.Unlock()
 
-	const pendingTimeout = 5 * time.Second
+	return drift
+}
 
-	if s.pendingArenaMsg == "" {
-		return ""
+func (s *BotService) runBalanceSyncLoop() {
+	var ticker *time.Ticker
+	var periodic <-chan time.Time
+	schedule := func() {
+		if ticker != nil {
+			ticker.Stop()
+			ticker, periodic = nil, nil
+		}
+		if minutes := s.config.GetConfig().BalanceSyncInterval; minutes > 0 {
+			ticker = time.NewTicker(jitterDuration(time.Duration(minutes)*time.Minute, SlotsJitterFraction))
+			periodic = ticker.C
+		}
 	}
+	schedule()
+	defer func() {
+		if ticker != nil {
+			ticker.Stop()
+		}
+	}()
 
-	if time.Since(s.pendingArenaTime) > pendingTimeout {
-		s.pendingArenaMsg = ""
-		return ""
+	var deferred <-chan time.Time
+	var deferredReason string
+	for {
+		reason := "periodic"
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-s.syncReschedule:
+			schedule()
+			continue
+		case <-periodic:
+		case reason = <-s.syncRequests:
+		case <-deferred:
+			reason = deferredReason
+			deferred = nil
+		}
+
+		if wait := s.balanceSyncWait(); wait > 0 {
+			if deferred == nil {
+				s.logger.Debugf(s.ctx, "Balance sync (%s) deferred by %v, last request was less than %v ago", reason, wait.Round(time.Second), MinBalanceSyncGap)
+				deferred = time.After(wait)
+				deferredReason = reason
+			}
+			continue
+		}
+		deferred = nil
+
+		s.logger.Debugf(s.ctx, "Requesting balance sync (%s)", reason)
+		s.sendBalanceRequest(s.config.GetConfig().Channel)
 	}
+}
+
+// balanceSyncWait returns how long a balance request has to wait for
+// MinBalanceSyncGap to pass, or claims the request when it may be sent now.
+func (s *BotService) balanceSyncWait() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	msg := s.pendingArenaMsg
-	s.pendingArenaMsg = ""
-	return msg
+	if !s.lastSyncRequest.IsZero() {
+		if wait := MinBalanceSyncGap - time.Since(s.lastSyncRequest); wait > 0 {
+			return wait
+		}
+	}
+	s.lastSyncRequest = time.Now()
+	return 0
 }
 
-func (s *BotService) ClearPendingArenaMsg() {
+func (s *BotService) sendBalanceRequest(channel string) {
 	s.mu.Lock()
-	s.pendingArenaMsg = ""
+	s.lastSyncRequest = time.Now()
 	s.mu.Unlock()
+
+	s.SafeSay(channel, s.Dialect().Balance.Command)
 }
 
 func (s *BotService) canPlaySlots() bool {
@@ -456,12 +1064,19 @@ func (s *BotService) canPlaySlots() bool {
 	if s.lastSlotsTime.IsZero() {
 		return true
 	}
-	return time.Since(s.lastSlotsTime) >= SlotsInterval
+	return s.clock().Sub(s.lastSlotsTime) >= s.slotsInterval
+}
+
+// SlotsInterval returns the time between auto slots.
+func (s *BotService) SlotsInterval() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.slotsInterval
 }
 
 func (s *BotService) RecordSlotsPlayed() {
 	s.mu.Lock()
-	s.lastSlotsTime = time.Now()
+	s.lastSlotsTime = s.clock()
 	s.mu.Unlock()
 }
 
@@ -477,6 +1092,8 @@ func (s *BotService) SetAutoSlots(enabled bool) {
 	s.mu.Unlock()
 }
 
+// IsUserTrusted reports whether the user with the given login or display
+// name is the owner or on the trusted list.
 func (s *BotService) IsUserTrusted(username string) bool {
 	cfg := s.config.GetConfig()
 	if strings.EqualFold(username, cfg.Username) {
@@ -485,48 +1102,238 @@ func (s *BotService) IsUserTrusted(username string) bool {
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	return s.trustedUsers[strings.ToLower(username)]
+	return s.findTrustedUser(username) >= 0
+}
+
+func (s *BotService) findTrustedUser(name string) int {
+	return slices.IndexFunc(s.trustedUsers, func(u storage.TrustedUser) bool {
+		return strings.EqualFold(u.Login, name) || strings.EqualFold(u.DisplayName, name)
+	})
 }
 
-func (s *BotService) AddTrustedUser(username string) {
+// isTrustedSender reports whether msg comes from the owner or a trusted user.
+// Trusted users are matched by Twitch user ID; users not yet seen in chat, and
+// messages without an ID such as replayed ones, are matched by name.
+func (s *BotService) isTrustedSender(msg ports.ChatMessage) bool {
+	if msg.UserID == "" || strings.EqualFold(msg.UserName, s.config.GetConfig().Username) {
+		return s.IsUserTrusted(msg.UserName)
+	}
+
 	s.mu.Lock()
-	s.trustedUsers[strings.ToLower(username)] = true
-	usersCopy := make(map[string]bool)
-	for k, v := range s.trustedUsers {
-		usersCopy[k] = v
+	defer s.mu.Unlock()
+	return slices.ContainsFunc(s.trustedUsers, func(u storage.TrustedUser) bool {
+		if u.ID != "" {
+			return u.ID == msg.UserID
+		}
+		return strings.EqualFold(u.Login, msg.UserName)
+	})
+}
+
+// noteChatter remembers the user ID and display name behind a login, so
+// !trust can store the ID, pins trusted users added before they were seen to
+// their ID and follows renames of trusted users.
+func (s *BotService) noteChatter(msg ports.ChatMessage) {
+	if msg.UserID == "" {
+		return
+	}
+	login := strings.ToLower(msg.UserName)
+
+	s.mu.Lock()
+	if s.chatters == nil || len(s.chatters) >= MaxKnownChatters {
+		s.chatters = make(map[string]chatter)
+	}
+	s.chatters[login] = chatter{id: msg.UserID, displayName: msg.DisplayName}
+
+	changed := false
+	for i := range s.trustedUsers {
+		u := &s.trustedUsers[i]
+		switch {
+		case u.ID == "" && u.Login == login:
+			s.logger.Infof(s.ctx, "Trusted user %s identified by user ID %s", login, msg.UserID)
+			u.ID = msg.UserID
+		case u.ID != msg.UserID:
+			continue
+		case u.Login != login:
+			s.logger.Infof(s.ctx, "Trusted user %s (ID %s) renamed to %s", u.Login, u.ID, login)
+		case msg.DisplayName == "" || u.DisplayName == msg.DisplayName:
+			continue
+		}
+		u.Login = login
+		if msg.DisplayName != "" {
+			u.DisplayName = msg.DisplayName
+		}
+		changed = true
+	}
+	var usersCopy []storage.TrustedUser
+	if changed {
+		usersCopy = slices.Clone(s.trustedUsers)
 	}
 	s.mu.Unlock()
 
-	if err := s.trustedStore.Save(usersCopy); err != nil {
-		s.logger.Warnf(s.ctx, "Could not save trusted users: %v", err)
+	if changed {
+		s.saveTrustedUsers(usersCopy)
 	}
 }
 
-func (s *BotService) RemoveTrustedUser(username string) {
+// lookupChatter returns the user ID and display name last seen for a login.
+func (s *BotService) lookupChatter(login string) (chatter, bool) {
 	s.mu.Lock()
-	delete(s.trustedUsers, strings.ToLower(username))
-	usersCopy := make(map[string]bool)
-	for k, v := range s.trustedUsers {
-		usersCopy[k] = v
+	defer s.mu.Unlock()
+	c, ok := s.chatters[strings.ToLower(login)]
+	return c, ok
+}
+
+// IsBossBot reports whether msg comes from the channel's boss bot. The sender
+// must carry BOSS_BOT_ID when set; otherwise the user ID of the first message
+// from BOSS_BOT_NAME is pinned, saved with the trusted users, and later
+// messages must carry the same ID, so an account taking over the name is
+// ignored. Messages without an ID are only matched by name in offline replay.
+func (s *BotService) IsBossBot(msg ports.ChatMessage) bool {
+	cfg := s.config.GetConfig()
+	nameMatches := strings.EqualFold(msg.UserName, cfg.BossBotName)
+	if msg.UserID == "" {
+		return nameMatches && s.offlineReplay
 	}
+
+	expected := cfg.BossBotID
+	if expected == "" {
+		login := strings.ToLower(cfg.BossBotName)
+		s.mu.Lock()
+		expected = s.bossBotIDs[login]
+		if expected == "" && nameMatches {
+			if s.bossBotIDs == nil {
+				s.bossBotIDs = make(map[string]string)
+			}
+			s.bossBotIDs[login] = msg.UserID
+			s.mu.Unlock()
+			s.pinBossBot(login, msg.UserID)
+			return true
+		}
+		s.mu.Unlock()
+	}
+
+	if msg.UserID == expected {
+		return true
+	}
+	if nameMatches {
+		s.logger.Warnf(s.ctx, "Ignoring message from %s: user ID %s is not the boss bot's %s", msg.UserName, msg.UserID, expected)
+	}
+	return false
+}
+
+func (s *BotService) pinBossBot(login, id string) {
+	s.logger.Infof(s.ctx, "Boss bot %s has user ID %s", login, id)
+	if s.trustedStore == nil {
+		return
+	}
+	if err := s.trustedStore.SaveBossBot(login, id); err != nil {
+		s.logger.Warnf(s.ctx, "Could not save boss bot ID: %v", err)
+	}
+}
+
+// Permissions returns the policy deciding who may run each command.
+func (s *BotService) Permissions() permissions.Policy {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.policy == nil {
+		return permissions.Policy{}
+	}
+	return *s.policy
+}
+
+// RolesOf returns the roles of a message's author: their Twitch badges plus
+// owner and trusted from the bot's configuration.
+func (s *BotService) RolesOf(msg ports.ChatMessage) permissions.Roles {
+	roles := permissions.RolesFromBadges(msg.Badges)
+	if strings.EqualFold(msg.UserName, s.config.GetConfig().Username) {
+		roles = append(roles, permissions.RoleOwner)
+	}
+	if s.isTrustedSender(msg) {
+		roles = append(roles, permissions.RoleTrusted)
+	}
+	return roles
+}
+
+// AddTrustedUser trusts the user with the given login. The user ID is stored
+// right away when the user has been seen in chat, otherwise on their first
+// message. It reports whether the ID was known.
+func (s *BotService) AddTrustedUser(username string) bool {
+	user := storage.TrustedUser{Login: strings.ToLower(username)}
+	c, known := s.lookupChatter(username)
+	if known {
+		user.ID = c.id
+		user.DisplayName = c.displayName
+	}
+
+	s.mu.Lock()
+	s.trustedUsers = append(s.trustedUsers, user)
+	usersCopy := slices.Clone(s.trustedUsers)
 	s.mu.Unlock()
 
-	if err := s.trustedStore.Save(usersCopy); err != nil {
+	s.saveTrustedUsers(usersCopy)
+	return known
+}
+
+func (s *BotService) RemoveTrustedUser(username string) {
+	s.mu.Lock()
+	s.trustedUsers = slices.DeleteFunc(s.trustedUsers, func(u storage.TrustedUser) bool {
+		return strings.EqualFold(u.Login, username) || strings.EqualFold(u.DisplayName, username)
+	})
+	usersCopy := slices.Clone(s.trustedUsers)
+	s.mu.Unlock()
+
+	s.saveTrustedUsers(usersCopy)
+}
+
+func (s *BotService) saveTrustedUsers(users []storage.TrustedUser) {
+	if s.trustedStore == nil {
+		return
+	}
+	if err := s.trustedStore.Save(users); err != nil {
 		s.logger.Warnf(s.ctx, "Could not save trusted users: %v", err)
 	}
 }
 
+// GetTrustedUsers returns the display names of the trusted users.
 func (s *BotService) GetTrustedUsers() []string {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	users := make([]string, 0, len(s.trustedUsers))
-	for user := range s.trustedUsers {
-		users = append(users, user)
+	for _, user := range s.trustedUsers {
+		users = append(users, user.Name())
 	}
+	slices.Sort(users)
 	return users
 }
 
+// AutoResponses returns the auto-responses to boss bot messages.
+func (s *BotService) AutoResponses() *autoresponse.Set {
+	if s.autoResponses == nil {
+		return autoresponse.NewSet(nil)
+	}
+	return s.autoResponses
+}
+
+func (s *BotService) saveAutoResponses() {
+	if s.autoResponseStore == nil {
+		return
+	}
+	if err := s.autoResponseStore.Save(s.AutoResponses().Rules()); err != nil {
+		s.logger.Warnf(s.ctx, "Could not save auto-responses: %v", err)
+	}
+}
+
+// respondTo sends the auto-response to a boss bot message, if any applies.
+func (s *BotService) respondTo(channel, text string) {
+	rule, ok := s.AutoResponses().Match(text, s.wallet.GetBalance(), s.clock(), rand.Float64)
+	if !ok {
+		return
+	}
+	s.logger.Debugf(s.ctx, "Auto-response to %q: %s", rule.Trigger, rule.Response)
+	s.autoSay(channel, rule.Response)
+}
+
 func (s *BotService) ScheduleSlotsOff(offTime time.Time) {
 	s.mu.Lock()
 
@@ -0,0 +1,235 @@
+package application
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"streamgogambler/internal/adapters/config"
+	"streamgogambler/internal/adapters/logging"
+	"streamgogambler/internal/adapters/storage"
+	"streamgogambler/internal/domain/gambling"
+	"streamgogambler/internal/domain/wallet"
+	"streamgogambler/internal/ports"
+)
+
+func TestHandleOwnCommandsUsesStrategy(t *testing.T) {
+	t.Parallel()
+
+	cfg := ports.BotConfig{DefaultHeist: 1000, SlotsCost: 2000, ArenaCost: 1000}
+
+	tests := []struct {
+		name     string
+		strategy gambling.Strategy
+		balance  int
+		cmd      string
+		wantOK   bool
+		wantMsg  string
+	}{
+		{"fixed uses default heist", gambling.FixedStrategy{}, 50000, "!heist", true, "!heist 1000"},
+		{"explicit amount wins", gambling.PercentageStrategy{Percent: 10}, 50000, "!heist 300", true, "!heist 300"},
+		{"percentage of balance", gambling.PercentageStrategy{Percent: 10}, 50000, "!heist", true, "!heist 5000"},
+		{"clamped to max", gambling.PercentageStrategy{Percent: 50}, 50000, "!heist", true, "!heist 10000"},
+		{"zero stake skips heist", gambling.PercentageStrategy{Percent: 1}, 50, "!heist", false, "!heist"},
+		{"stake below cost skips slots", gambling.PercentageStrategy{Percent: 1}, 50000, "!slots", false, "!slots"},
+		{"stake above cost plays slots", gambling.PercentageStrategy{Percent: 10}, 50000, "!slots", true, "!slots"},
+		{"free boss fight is tracked", gambling.PercentageStrategy{Percent: 1}, 0, "!boss", true, "!boss"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := newTestMessageHandler()
+			bot := h.bot
+			bot.logger = h.logger
+			bot.strategy = tt.strategy
+			bot.wallet.SetBalance(tt.balance)
+
+			ok, msg, bet := bot.handleOwnCommands(tt.cmd, cfg)
+
+			assert.Equal(t, tt.wantOK, ok, "handleOwnCommands(%q) ok", tt.cmd)
+			assert.Equal(t, tt.wantMsg, msg, "handleOwnCommands(%q) message", tt.cmd)
+			if tt.wantOK {
+				assert.NotZero(t, bet.ID, "bet reserved")
+			} else {
+				assert.Empty(t, bot.escrow.Pending(), "nothing reserved")
+				assert.Equal(t, tt.balance, bot.wallet.GetBalance(), "balance untouched")
+			}
+		})
+	}
+}
+
+func TestStrategyDefaultsToFixed(t *testing.T) {
+	t.Parallel()
+
+	bot := &BotService{wallet: wallet.New(0)}
+	assert.Equal(t, gambling.StrategyFixed, bot.Strategy().Name(), "default strategy")
+
+	bot.SetStrategy(gambling.KellyStrategy{Fraction: 0.5})
+	assert.Equal(t, "kelly:0.5", bot.Strategy().Name(), "strategy after SetStrategy")
+}
+
+func TestHandleOwnCommandsRespectsReserve(t *testing.T) {
+	t.Parallel()
+
+	h := newTestMessageHandler()
+	bot := h.bot
+	bot.logger = h.logger
+	bot.guardrails = gambling.Guardrails{Reserve: 5000}
+	bot.wallet.SetBalance(7000)
+	cfg := ports.BotConfig{DefaultHeist: 1000, SlotsCost: 2000, ArenaCost: 1000}
+
+	ok, _, _ := bot.handleOwnCommands("!slots", cfg)
+	assert.True(t, ok, "slots down to the floor is allowed")
+
+	ok, _, _ = bot.handleOwnCommands("!heist 500", cfg)
+	assert.False(t, ok, "heist below the floor is refused")
+	assert.Equal(t, 5000, bot.wallet.GetBalance(), "balance stays at the floor")
+}
+
+func TestGuardrailsPauseAutomatedGames(t *testing.T) {
+	t.Parallel()
+
+	h := newTestMessageHandler()
+	bot := h.bot
+	bot.logger = h.logger
+	bot.guardrails = gambling.Guardrails{StopLoss: 3000, TakeProfit: 10000}
+
+	assert.Equal(t, gambling.GuardrailOK, bot.GuardrailState(), "no session before first sync")
+
+	bot.SyncBalance(20000, "testuser bombs: 20000")
+	assert.Equal(t, gambling.GuardrailOK, bot.GuardrailState(), "session started")
+
+	bot.wallet.AddBalance(-3000)
+	assert.Equal(t, gambling.GuardrailStopLoss, bot.GuardrailState(), "stop-loss tripped")
+	assert.Equal(t, -3000, bot.SessionProfit(), "session profit")
+
+	bot.wallet.AddBalance(3000)
+	assert.Equal(t, gambling.GuardrailStopLoss, bot.GuardrailState(), "tripped guardrail stays until reset")
+
+	bot.ResetSession()
+	assert.Equal(t, gambling.GuardrailOK, bot.GuardrailState(), "reset resumes")
+	assert.Equal(t, 0, bot.SessionProfit(), "reset starts from current balance")
+
+	bot.wallet.AddBalance(10000)
+	assert.Equal(t, gambling.GuardrailTakeProfit, bot.GuardrailState(), "take-profit tripped")
+}
+
+func TestGuardrailsIgnoreStakesInEscrow(t *testing.T) {
+	t.Parallel()
+
+	bot := newChannelBot("foo", 0)
+	bot.guardrails = gambling.Guardrails{StopLoss: 3000}
+	bot.SyncBalance(20000, "testuser bombs: 20000")
+
+	_, ok := bot.escrow.Reserve(wallet.GameHeist, 5000, "!heist 5000", time.Minute)
+	require.True(t, ok, "Reserve()")
+	assert.Equal(t, gambling.GuardrailOK, bot.GetStats().Guardrail, "pending heist is not a loss")
+	assert.Equal(t, gambling.GuardrailOK, bot.GuardrailState(), "pending heist does not trip stop-loss")
+	assert.Zero(t, bot.SessionProfit(), "pending stake counted in the session")
+
+	_, _ = bot.escrow.Commit(wallet.GameHeist)
+	assert.Equal(t, gambling.GuardrailStopLoss, bot.GetStats().Guardrail, "stats report the breached stop-loss")
+	assert.Equal(t, gambling.GuardrailOK, bot.guardrailState, "stats do not trip the guardrail")
+	assert.Equal(t, gambling.GuardrailStopLoss, bot.GuardrailState(), "lost heist trips stop-loss")
+	assert.Equal(t, gambling.GuardrailStopLoss, bot.GetStats().Guardrail, "stats report the tripped guardrail")
+}
+
+func TestSafeSayReservesBossEntry(t *testing.T) {
+	t.Parallel()
+
+	bot := newChannelBot("foo", 0)
+	bot.chat = idleChat{}
+
+	bot.SafeSay("foo", "!boss")
+
+	pending := bot.escrow.Pending()
+	if assert.Len(t, pending, 1, "boss entry reserved") {
+		assert.Equal(t, wallet.GameBoss, pending[0].Game, "boss bet")
+	}
+}
+
+func TestIsBossBotMatchesUserID(t *testing.T) {
+	t.Parallel()
+
+	t.Run("pinned from first message", func(t *testing.T) {
+		t.Parallel()
+
+		bot := newChannelBot("foo", 0)
+		bot.config = config.NewStaticStore(ports.BotConfig{Username: "testuser", Channel: "foo", BossBotName: "bossbot"})
+
+		assert.False(t, bot.IsBossBot(ports.ChatMessage{UserName: "viewer", UserID: "3"}), "other chatter")
+		assert.True(t, bot.IsBossBot(ports.ChatMessage{UserName: "BossBot", UserID: "9"}), "first message pins the ID")
+		assert.False(t, bot.IsBossBot(ports.ChatMessage{UserName: "bossbot", UserID: "10"}), "impersonator on the name")
+		assert.True(t, bot.IsBossBot(ports.ChatMessage{UserName: "renamedboss", UserID: "9"}), "renamed boss bot")
+		assert.False(t, bot.IsBossBot(ports.ChatMessage{UserName: "bossbot"}), "message without ID in live chat")
+	})
+
+	t.Run("learned ID kept across restarts", func(t *testing.T) {
+		t.Parallel()
+
+		store := storage.NewTrustedUsersStore(filepath.Join(t.TempDir(), "trusted_users.json"))
+		cfg := config.NewStaticStore(ports.BotConfig{Username: "testuser", Channel: "foo", BossBotName: "bossbot"})
+		logger := logging.New(logging.LevelError)
+
+		first := NewBotService(cfg, nil, logger, store)
+		require.True(t, first.IsBossBot(ports.ChatMessage{UserName: "bossbot", UserID: "9"}), "first message pins the ID")
+
+		restarted := NewBotService(cfg, nil, logger, store)
+		assert.False(t, restarted.IsBossBot(ports.ChatMessage{UserName: "bossbot", UserID: "10"}), "impersonator after a restart")
+		assert.True(t, restarted.IsBossBot(ports.ChatMessage{UserName: "bossbot", UserID: "9"}), "saved ID")
+	})
+
+	t.Run("offline replay", func(t *testing.T) {
+		t.Parallel()
+
+		bot := NewBotService(config.NewStaticStore(ports.BotConfig{Username: "testuser", Channel: "foo", BossBotName: "bossbot"}),
+			nil, logging.New(logging.LevelError), nil, WithOfflineReplay())
+
+		assert.True(t, bot.IsBossBot(ports.ChatMessage{UserName: "BossBot"}), "message without ID matched by name")
+		assert.False(t, bot.IsBossBot(ports.ChatMessage{UserName: "viewer"}), "other chatter")
+	})
+
+	t.Run("configured ID", func(t *testing.T) {
+		t.Parallel()
+
+		bot := newChannelBot("foo", 0)
+		bot.config = config.NewStaticStore(ports.BotConfig{Username: "testuser", Channel: "foo", BossBotName: "bossbot", BossBotID: "5"})
+
+		assert.False(t, bot.IsBossBot(ports.ChatMessage{UserName: "bossbot", UserID: "9"}), "name with another ID")
+		assert.True(t, bot.IsBossBot(ports.ChatMessage{UserName: "bossbot", UserID: "5"}), "configured ID")
+	})
+}
+
+func TestReconnectTotalNeverDrops(t *testing.T) {
+	t.Parallel()
+
+	bot := newChannelBot("foo", 0)
+	bot.trackReconnect()
+	bot.trackReconnect()
+	bot.lastReconnect = time.Now().Add(-time.Hour)
+	bot.trackReconnect()
+
+	stats := bot.GetStats()
+	assert.Equal(t, 1, stats.ReconnectCount, "recent reconnects restart after a quiet period")
+	assert.Equal(t, 3, stats.Counters.Reconnects, "total since start")
+}
+
+func TestBalanceReplyExcludesBetsAfterTheRequest(t *testing.T) {
+	t.Parallel()
+
+	bot := newChannelBot("foo", 10000)
+	bot.chat = idleChat{}
+
+	bot.SafeSay("foo", "!bombs")
+	_, ok := bot.escrow.Reserve(wallet.GameHeist, 2000, "!heist 2000", time.Minute)
+	require.True(t, ok, "Reserve()")
+
+	assert.Zero(t, bot.SyncBalance(10000, "testuser bombs: 10000"), "no drift")
+	assert.Equal(t, 8000, bot.wallet.GetBalance(), "heist sent after the request stays deducted")
+	assert.Equal(t, 10000, bot.equity(), "stake counted once")
+}
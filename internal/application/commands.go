@@ -2,6 +2,7 @@ package application
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -9,163 +10,289 @@ import (
 	"time"
 
 	"streamgogambler/internal/adapters/logging"
+	"streamgogambler/internal/domain/autoresponse"
 	"streamgogambler/internal/domain/gambling"
+	"streamgogambler/internal/domain/i18n"
+	"streamgogambler/internal/domain/permissions"
+	"streamgogambler/internal/domain/wallet"
 	"streamgogambler/internal/ports"
 )
 
 type CommandHandler struct {
-	bot    *BotService
-	config ports.ConfigStore
-	logger *logging.Logger
-	cmds   map[string]CommandFunc
+	bot      *BotService
+	config   ports.ConfigStore
+	logger   *logging.Logger
+	commands []*Command
+	byName   map[string]*Command
 }
 
-type CommandFunc func(userName, channel string, args []string)
+var (
+	trustedRoles = permissions.Roles{permissions.RoleTrusted}
+	ownerRoles   = permissions.Roles{permissions.RoleOwner}
+
+	autoSlotsChoices    = []string{"on", "1", "true", "wlacz", "włącz", "off", "0", "false", "wylacz", "wyłącz"}
+	autoResponseActions = []string{"list", "add", "remove", "enable", "disable"}
+)
 
 func NewCommandHandler(bot *BotService, config ports.ConfigStore, logger *logging.Logger) *CommandHandler {
 	h := &CommandHandler{
 		bot:    bot,
 		config: config,
 		logger: logger,
-		cmds:   make(map[string]CommandFunc),
+		byName: make(map[string]*Command),
+	}
+
+	gameChoices := make([]string, 0, len(TrackedGames))
+	for _, game := range TrackedGames {
+		gameChoices = append(gameChoices, string(game))
 	}
 
 	cfg := config.GetConfig()
-	h.cmds[strings.ToLower(cfg.StatusCommand)] = h.handleStatus
-	h.cmds["ustaw"] = h.handleSetHeist
-	h.cmds["jakiheist"] = h.handleCheckHeist
-	h.cmds["autoslots"] = h.handleAutoSlots
-	h.cmds["slotsoff"] = h.handleSlotsOff
-	h.cmds["trust"] = h.handleTrust
-	h.cmds["untrust"] = h.handleUntrust
-	h.cmds["trustlist"] = h.handleTrustList
-	h.cmds["help"] = h.handleHelp
+	commands := []Command{
+		{Name: strings.ToLower(cfg.StatusCommand), Roles: trustedRoles, Help: "help.status", Run: h.handleStatus},
+		{Name: "ustaw", Aliases: []string{"setheist"}, Roles: trustedRoles, Args: []Arg{{Name: "amount"}},
+			Cooldown: 5 * time.Second, Help: "help.ustaw", Run: h.handleSetHeist},
+		{Name: "jakiheist", Aliases: []string{"heistamount"}, Roles: trustedRoles, Help: "help.jakiheist", Run: h.handleCheckHeist},
+		{Name: "autoslots", Aliases: []string{"autosloty"}, Roles: trustedRoles, Args: []Arg{{Name: "on/off", Optional: true, Choices: autoSlotsChoices}},
+			Help: "help.autoslots", Run: h.handleAutoSlots},
+		{Name: "strategia", Aliases: []string{"strategy"}, Roles: trustedRoles, Args: []Arg{{Name: "strategy", Optional: true}},
+			Cooldown: 5 * time.Second, Help: "help.strategia", Run: h.handleStrategy},
+		{Name: "sesja", Aliases: []string{"session"}, Roles: trustedRoles, Args: []Arg{{Name: "reset", Optional: true, Choices: []string{"reset"}}},
+			Help: "help.sesja", Run: h.handleSession},
+		{Name: "heisty", Aliases: []string{"heists"}, Roles: trustedRoles, Cooldown: 5 * time.Second, Help: "help.heisty", Run: h.handleHeistStats},
+		{Name: "gry", Aliases: []string{"games"}, Roles: trustedRoles, Args: []Arg{{Name: strings.Join(gameChoices, "/"), Optional: true, Choices: gameChoices}},
+			Cooldown: 5 * time.Second, Help: "help.gry", Run: h.handleGameStats},
+		{Name: "slotsoff", Aliases: []string{"wylaczsloty"}, Roles: trustedRoles, Args: []Arg{{Name: "time/duration", Optional: true}},
+			Help: "help.slotsoff", Run: h.handleSlotsOff},
+		{Name: "trust", Aliases: []string{"zaufaj"}, Roles: ownerRoles, Args: []Arg{{Name: "user"}}, Help: "help.trust", Run: h.handleTrust},
+		{Name: "untrust", Aliases: []string{"odufaj"}, Roles: ownerRoles, Args: []Arg{{Name: "user"}}, Help: "help.untrust", Run: h.handleUntrust},
+		{Name: "trustlist", Aliases: []string{"zaufani"}, Roles: ownerRoles, Help: "help.trustlist", Run: h.handleTrustList},
+		{Name: "autoresponse", Aliases: []string{"autoodpowiedz"}, Roles: trustedRoles,
+			Args: []Arg{{Name: strings.Join(autoResponseActions, "/"), Choices: autoResponseActions}, {Name: "rule", Optional: true}},
+			Help: "help.autoresponse", Run: h.handleAutoResponse},
+		{Name: "set", Aliases: []string{"zmien"}, Roles: trustedRoles, Args: []Arg{{Name: "key"}, {Name: "value"}},
+			Cooldown: 5 * time.Second, Help: "help.set", Run: h.handleSetSetting},
+		{Name: "get", Aliases: []string{"pokaz"}, Roles: trustedRoles, Args: []Arg{{Name: "key", Optional: true}},
+			Cooldown: 5 * time.Second, Help: "help.get", Run: h.handleGetSetting},
+		{Name: "help", Aliases: []string{"pomoc", "komendy"}, Roles: trustedRoles, Args: []Arg{{Name: "command", Optional: true}},
+			Cooldown: 10 * time.Second, Help: "help.help", Run: h.handleHelp},
+	}
+	for _, c := range commands {
+		if err := h.Register(c); err != nil {
+			logger.Warnf(context.Background(), "Skipping command %s: %v", c.Name, err)
+		}
+	}
 
 	return h
 }
 
-func (h *CommandHandler) HandleCommand(userName, channel, fullMsg string) {
+// HandleCommand runs a command sent by msg's author if the permission policy
+// allows it for their roles, the user's cooldown for it has passed and the
+// arguments match the command's schema.
+func (h *CommandHandler) HandleCommand(msg ports.ChatMessage, fullMsg string) {
 	cfg := h.config.GetConfig()
 
-	cmd, args, ok := splitCommand(fullMsg, cfg.Prefix)
+	name, args, ok := splitCommand(fullMsg, cfg.Prefix)
 	if !ok {
 		return
 	}
+	cmd, ok := h.byName[name]
+	if !ok {
+		return
+	}
+
+	roles := h.bot.RolesOf(msg)
+	if !h.bot.Permissions().Allows(roles, cmd.Roles, cmd.Names()...) {
+		h.logger.Debugf(h.bot.ctx, "Command %s denied for %s (roles: %v)", cmd.Name, msg.UserName, roles)
+		return
+	}
 
-	isOwner := strings.EqualFold(userName, cfg.Username)
-	if !isOwner && h.bot.IsUserRateLimited(userName) {
-		h.logger.Debugf(h.bot.ctx, "Command blocked (rate limit) from %s: %s", userName, cmd)
+	if !roles.Has(permissions.RoleOwner) && h.bot.IsOnCooldown(msg.UserName, cmd.Name, cmd.cooldown()) {
+		h.logger.Debugf(h.bot.ctx, "Command blocked (cooldown) from %s: %s", msg.UserName, cmd.Name)
 		return
 	}
 
-	if handler, ok := h.cmds[cmd]; ok {
-		handler(userName, channel, args)
+	call := CommandCall{User: msg.UserName, Channel: msg.Channel, Args: args, Roles: roles, Command: cmd}
+	if rest, ok := cutPrefixFold(fullMsg, cfg.Prefix); ok {
+		call.text = skipFields(rest, 1)
 	}
+	if !cmd.acceptsArgs(args) {
+		h.replyUsage(call)
+		return
+	}
+	cmd.Run(call)
 }
 
 func (h *CommandHandler) IsInternalCommand(cmdName string) bool {
-	_, exists := h.cmds[strings.ToLower(cmdName)]
+	_, exists := h.Lookup(cmdName)
 	return exists
 }
 
-func (h *CommandHandler) handleStatus(userName, channel string, _ []string) {
-	if !h.bot.IsUserTrusted(userName) {
-		return
+// reply sends the caller a message from the bot's catalog; {user} is the
+// caller.
+func (h *CommandHandler) reply(c CommandCall, id string, vars i18n.Vars) {
+	if vars == nil {
+		vars = i18n.Vars{}
 	}
-
-	cfg := h.config.GetConfig()
-	msg := fmt.Sprintf("@%s, Bot działa prawidłowo ;) | Bombs: %d | Heist: %d",
-		userName, h.bot.Wallet().GetBalance(), cfg.DefaultHeist)
-	h.bot.SafeSay(channel, msg)
+	vars["user"] = c.User
+	h.bot.SafeSay(c.Channel, h.bot.Messages().Format(id, vars))
 }
 
-func (h *CommandHandler) handleSetHeist(userName, channel string, args []string) {
-	if !h.bot.IsUserTrusted(userName) {
-		return
-	}
+// commandName returns the called command with the prefix, for usage hints.
+func (h *CommandHandler) commandName(c CommandCall) string {
+	return h.config.GetConfig().Prefix + c.Command.Name
+}
 
-	if len(args) == 0 {
-		h.bot.SafeSay(channel, fmt.Sprintf("@%s, Podaj liczbę od 1 do max %d!", userName, gambling.MaxHeistAmount))
-		return
-	}
+func (h *CommandHandler) handleStatus(c CommandCall) {
+	cfg := h.config.GetConfig()
+	h.reply(c, "status", i18n.Vars{"balance": h.bot.Wallet().GetBalance(), "heist": cfg.DefaultHeist})
+}
 
-	heist, err := strconv.Atoi(args[0])
+func (h *CommandHandler) handleSetHeist(c CommandCall) {
+	heist, err := strconv.Atoi(c.Args[0])
 	if err != nil || heist <= 0 || heist > gambling.MaxHeistAmount {
-		h.bot.SafeSay(channel, fmt.Sprintf("@%s, Podaj liczbę od 1 do max %d!", userName, gambling.MaxHeistAmount))
+		h.reply(c, "heist.invalid", i18n.Vars{"max": gambling.MaxHeistAmount})
 		return
 	}
 
 	if err := h.config.UpdateHeist(heist); err != nil {
 		h.logger.Errorf(h.bot.ctx, "Error updating HEIST_AMOUNT in .env: %v", err)
-		h.bot.SafeSay(channel, fmt.Sprintf("@%s, Wystąpił błąd podczas aktualizacji wartości heist!", userName))
+		h.reply(c, "heist.update_failed", nil)
 		return
 	}
 
-	h.bot.SafeSay(channel, fmt.Sprintf("@%s, Pomyślnie zmieniono ilość heista na %d!", userName, heist))
+	h.reply(c, "heist.updated", i18n.Vars{"heist": heist})
 	h.logger.Infof(h.bot.ctx, "Successfully updated HEIST_AMOUNT to %d", heist)
 }
 
-func (h *CommandHandler) handleCheckHeist(userName, channel string, _ []string) {
-	if !h.bot.IsUserTrusted(userName) {
-		return
-	}
-
+func (h *CommandHandler) handleCheckHeist(c CommandCall) {
 	cfg := h.config.GetConfig()
-	h.bot.SafeSay(channel, fmt.Sprintf("@%s, Masz aktualnie ustawione %d heista ;)", userName, cfg.DefaultHeist))
+	h.reply(c, "heist.current", i18n.Vars{"heist": cfg.DefaultHeist})
 }
 
-func (h *CommandHandler) handleAutoSlots(userName, channel string, args []string) {
-	if !h.bot.IsUserTrusted(userName) {
-		return
-	}
-
-	if len(args) == 0 {
-		status := "wyłączone"
+func (h *CommandHandler) handleAutoSlots(c CommandCall) {
+	messages := h.bot.Messages()
+	if len(c.Args) == 0 {
+		state := messages.Format("autoslots.state_off", nil)
 		if h.bot.IsAutoSlotsEnabled() {
-			status = "włączone"
+			state = messages.Format("autoslots.state_on", nil)
 		}
-		h.bot.SafeSay(channel, fmt.Sprintf("@%s, Auto slots jest %s. Użyj: !autoslots on/off", userName, status))
+		h.reply(c, "autoslots.status", i18n.Vars{"state": state, "usage": c.Command.Usage(h.config.GetConfig().Prefix, messages)})
 		return
 	}
 
-	switch strings.ToLower(args[0]) {
+	switch strings.ToLower(c.Args[0]) {
 	case "on", "1", "true", "wlacz", "włącz":
 		h.bot.SetAutoSlots(true)
-		h.bot.SafeSay(channel, fmt.Sprintf("@%s, Auto slots włączone!", userName))
-		h.logger.Infof(h.bot.ctx, "Auto slots enabled by %s", userName)
-	case "off", "0", "false", "wylacz", "wyłącz":
-		h.bot.SetAutoSlots(false)
-		h.bot.SafeSay(channel, fmt.Sprintf("@%s, Auto slots wyłączone!", userName))
-		h.logger.Infof(h.bot.ctx, "Auto slots disabled by %s", userName)
+		h.reply(c, "autoslots.enabled", nil)
+		h.logger.Infof(h.bot.ctx, "Auto slots enabled by %s", c.User)
 	default:
-		h.bot.SafeSay(channel, fmt.Sprintf("@%s, Użyj: !autoslots on/off", userName))
+		h.bot.SetAutoSlots(false)
+		h.reply(c, "autoslots.disabled", nil)
+		h.logger.Infof(h.bot.ctx, "Auto slots disabled by %s", c.User)
 	}
 }
 
-func (h *CommandHandler) handleSlotsOff(userName, channel string, args []string) {
-	if !h.bot.IsUserTrusted(userName) {
+func (h *CommandHandler) handleStrategy(c CommandCall) {
+	if len(c.Args) == 0 {
+		h.reply(c, "strategy.current", i18n.Vars{"strategy": h.bot.Strategy().Name(), "command": h.commandName(c)})
+		return
+	}
+
+	strategy, err := gambling.ParseStrategy(c.Args[0])
+	if err != nil {
+		h.reply(c, "strategy.invalid", i18n.Vars{"command": h.commandName(c)})
 		return
 	}
 
-	if len(args) == 0 {
+	h.bot.SetStrategy(strategy)
+	h.reply(c, "strategy.set", i18n.Vars{"strategy": strategy.Name()})
+	h.logger.Infof(h.bot.ctx, "Betting strategy set to %s by %s", strategy.Name(), c.User)
+}
+
+func (h *CommandHandler) handleSession(c CommandCall) {
+	if len(c.Args) > 0 {
+		h.bot.ResetSession()
+		h.reply(c, "session.reset", i18n.Vars{"balance": h.bot.Wallet().GetBalance()})
+		h.logger.Infof(h.bot.ctx, "Session reset by %s", c.User)
+		return
+	}
+
+	h.reply(c, "session.status", i18n.Vars{
+		"profit":  fmt.Sprintf("%+d", h.bot.SessionProfit()),
+		"state":   h.bot.GuardrailState(),
+		"command": h.commandName(c),
+	})
+}
+
+func (h *CommandHandler) handleHeistStats(c CommandCall) {
+	s := h.bot.GameSummary(wallet.GameHeist)
+	if s.Seen == 0 {
+		h.reply(c, "heists.none", nil)
+		return
+	}
+
+	h.reply(c, "heists.stats", i18n.Vars{
+		"seen":     s.Seen,
+		"joined":   s.Joined,
+		"survived": s.Survived,
+		"win_rate": fmt.Sprintf("%.0f", s.WinRate()*100),
+		"staked":   s.Staked,
+		"returned": s.Returned,
+		"avg_crew": fmt.Sprintf("%.1f", s.AvgCrew),
+	})
+}
+
+func (h *CommandHandler) handleGameStats(c CommandCall) {
+	games := TrackedGames
+	if len(c.Args) > 0 {
+		games = []wallet.Game{wallet.Game(strings.ToLower(c.Args[0]))}
+	}
+
+	messages := h.bot.Messages()
+	var parts []string
+	for _, game := range games {
+		s := h.bot.GameSummary(game)
+		if s.Seen == 0 {
+			continue
+		}
+		parts = append(parts, messages.Format("games.game", i18n.Vars{
+			"game":          game,
+			"survived":      s.Survived,
+			"joined":        s.Joined,
+			"win_rate":      fmt.Sprintf("%.0f", s.WinRate()*100),
+			"profit":        fmt.Sprintf("%+d", s.Profit()),
+			"avg_placement": fmt.Sprintf("%.1f", s.AvgPlacement),
+		}))
+	}
+	if len(parts) == 0 {
+		h.reply(c, "games.none", nil)
+		return
+	}
+
+	h.reply(c, "games.stats", i18n.Vars{"games": strings.Join(parts, " | ")})
+}
+
+func (h *CommandHandler) handleSlotsOff(c CommandCall) {
+	if len(c.Args) == 0 {
 		offTime := h.bot.GetSlotsOffTime()
 		if offTime.IsZero() {
-			h.bot.SafeSay(channel, fmt.Sprintf("@%s, Brak zaplanowanego wyłączenia. Użyj: !slotsoff <czas> lub !slotsoff <duration>", userName))
+			h.reply(c, "slotsoff.none", i18n.Vars{"command": h.commandName(c)})
 		} else {
 			remaining := time.Until(offTime).Round(time.Second)
-			h.bot.SafeSay(channel, fmt.Sprintf("@%s, Auto slots wyłączy się o %s (za %s)", userName, offTime.Format("15:04"), remaining))
+			h.reply(c, "slotsoff.scheduled", i18n.Vars{"time": offTime.Format("15:04"), "remaining": remaining})
 		}
 		return
 	}
 
-	arg := strings.ToLower(args[0])
+	arg := strings.ToLower(c.Args[0])
 
 	if arg == "cancel" || arg == "anuluj" {
 		if h.bot.CancelSlotsOffSchedule() {
-			h.bot.SafeSay(channel, fmt.Sprintf("@%s, Anulowano zaplanowane wyłączenie.", userName))
-			h.logger.Infof(h.bot.ctx, "Slots off schedule canceled by %s", userName)
+			h.reply(c, "slotsoff.canceled", nil)
+			h.logger.Infof(h.bot.ctx, "Slots off schedule canceled by %s", c.User)
 		} else {
-			h.bot.SafeSay(channel, fmt.Sprintf("@%s, Brak zaplanowanego wyłączenia.", userName))
+			h.reply(c, "slotsoff.not_planned", nil)
 		}
 		return
 	}
@@ -176,7 +303,7 @@ func (h *CommandHandler) handleSlotsOff(userName, channel string, args []string)
 		minute, _ := strconv.Atoi(parts[1])
 
 		if hour < 0 || hour > 23 || minute < 0 || minute > 59 {
-			h.bot.SafeSay(channel, fmt.Sprintf("@%s, Nieprawidłowy czas. Użyj formatu HH:MM (np. 22:00)", userName))
+			h.reply(c, "slotsoff.bad_time", nil)
 			return
 		}
 
@@ -188,110 +315,255 @@ func (h *CommandHandler) handleSlotsOff(userName, channel string, args []string)
 		}
 
 		h.bot.ScheduleSlotsOff(offTime)
-		h.bot.SafeSay(channel, fmt.Sprintf("@%s, Auto slots wyłączy się o %s", userName, offTime.Format("15:04")))
-		h.logger.Infof(h.bot.ctx, "Slots off scheduled for %s by %s", offTime.Format("15:04"), userName)
+		h.reply(c, "slotsoff.set_at", i18n.Vars{"time": offTime.Format("15:04")})
+		h.logger.Infof(h.bot.ctx, "Slots off scheduled for %s by %s", offTime.Format("15:04"), c.User)
 		return
 	}
 
 	duration, err := time.ParseDuration(arg)
 	if err != nil || duration <= 0 {
-		h.bot.SafeSay(channel, fmt.Sprintf("@%s, Użyj: !slotsoff <HH:MM> lub !slotsoff <duration> (np. 2h, 30m, 1h30m)", userName))
+		h.reply(c, "slotsoff.invalid", i18n.Vars{"command": h.commandName(c)})
 		return
 	}
 
 	offTime := time.Now().Add(duration)
 	h.bot.ScheduleSlotsOff(offTime)
-	h.bot.SafeSay(channel, fmt.Sprintf("@%s, Auto slots wyłączy się za %s (o %s)", userName, duration.Round(time.Second), offTime.Format("15:04")))
-	h.logger.Infof(h.bot.ctx, "Slots off scheduled in %s by %s", duration, userName)
+	h.reply(c, "slotsoff.set_in", i18n.Vars{"duration": duration.Round(time.Second), "time": offTime.Format("15:04")})
+	h.logger.Infof(h.bot.ctx, "Slots off scheduled in %s by %s", duration, c.User)
 }
 
-func (h *CommandHandler) handleTrust(userName, channel string, args []string) {
-	cfg := h.config.GetConfig()
-	if !strings.EqualFold(userName, cfg.Username) {
+func (h *CommandHandler) handleTrust(c CommandCall) {
+	target := strings.ToLower(c.Args[0])
+	if strings.EqualFold(target, h.config.GetConfig().Username) {
+		h.reply(c, "trust.self", nil)
 		return
 	}
 
-	if len(args) == 0 {
-		h.bot.SafeSay(channel, fmt.Sprintf("@%s, Użyj: !trust <nick>", userName))
+	if h.bot.IsUserTrusted(target) {
+		h.reply(c, "trust.already", i18n.Vars{"target": target})
 		return
 	}
 
-	target := strings.ToLower(args[0])
-	if strings.EqualFold(target, cfg.Username) {
-		h.bot.SafeSay(channel, fmt.Sprintf("@%s, Nie możesz dodać siebie do listy!", userName))
+	if h.bot.AddTrustedUser(target) {
+		h.reply(c, "trust.added", i18n.Vars{"target": target})
+	} else {
+		h.reply(c, "trust.added_pending", i18n.Vars{"target": target})
+	}
+	h.logger.Infof(h.bot.ctx, "Added %s to trusted users by %s", target, c.User)
+}
+
+func (h *CommandHandler) handleUntrust(c CommandCall) {
+	target := strings.ToLower(c.Args[0])
+	if !h.bot.IsUserTrusted(target) {
+		h.reply(c, "untrust.not_trusted", i18n.Vars{"target": target})
 		return
 	}
 
-	if h.bot.IsUserTrusted(target) {
-		h.bot.SafeSay(channel, fmt.Sprintf("@%s, %s już jest zaufanym użytkownikiem", userName, target))
+	h.bot.RemoveTrustedUser(target)
+	h.reply(c, "untrust.removed", i18n.Vars{"target": target})
+	h.logger.Infof(h.bot.ctx, "Removed %s from trusted users by %s", target, c.User)
+}
+
+func (h *CommandHandler) handleTrustList(c CommandCall) {
+	users := h.bot.GetTrustedUsers()
+	if len(users) == 0 {
+		h.reply(c, "trustlist.empty", nil)
 		return
 	}
 
-	h.bot.AddTrustedUser(target)
-	h.bot.SafeSay(channel, fmt.Sprintf("@%s, Dodano %s do zaufanych użytkowników!", userName, target))
-	h.logger.Infof(h.bot.ctx, "Added %s to trusted users by %s", target, userName)
+	h.reply(c, "trustlist.users", i18n.Vars{"users": strings.Join(users, ", ")})
 }
 
-func (h *CommandHandler) handleUntrust(userName, channel string, args []string) {
-	cfg := h.config.GetConfig()
-	if !strings.EqualFold(userName, cfg.Username) {
+// handleAutoResponse lists and edits the auto-responses. Rules are referenced
+// by their position in the list or their trigger.
+func (h *CommandHandler) handleAutoResponse(c CommandCall) {
+	action := strings.ToLower(c.Args[0])
+	if action == "list" {
+		h.listAutoResponses(c)
 		return
 	}
 
-	if len(args) == 0 {
-		h.bot.SafeSay(channel, fmt.Sprintf("@%s, Użyj: !untrust <nick>", userName))
+	ref := c.ArgsFrom(1)
+	if ref == "" {
+		h.replyUsage(c)
 		return
 	}
 
-	target := strings.ToLower(args[0])
-	if !h.bot.IsUserTrusted(target) {
-		h.bot.SafeSay(channel, fmt.Sprintf("@%s, %s nie jest zaufanym użytkownikiem", userName, target))
+	set := h.bot.AutoResponses()
+	var (
+		rule autoresponse.Rule
+		err  error
+	)
+	switch action {
+	case "add":
+		if rule, err = autoresponse.Parse(ref); err == nil {
+			err = set.Add(rule)
+		}
+	case "remove":
+		rule, err = set.Remove(ref)
+	default:
+		rule, err = set.SetEnabled(ref, action == "enable")
+	}
+
+	switch {
+	case errors.Is(err, autoresponse.ErrDuplicateTrigger):
+		h.reply(c, "autoresponse.exists", i18n.Vars{"trigger": rule.Trigger})
+		return
+	case errors.Is(err, autoresponse.ErrNotFound):
+		h.reply(c, "autoresponse.not_found", i18n.Vars{"ref": ref, "command": h.commandName(c)})
+		return
+	case err != nil:
+		h.reply(c, "autoresponse.invalid", i18n.Vars{"command": h.commandName(c)})
 		return
 	}
 
-	h.bot.RemoveTrustedUser(target)
-	h.bot.SafeSay(channel, fmt.Sprintf("@%s, Usunięto %s z zaufanych użytkowników!", userName, target))
-	h.logger.Infof(h.bot.ctx, "Removed %s from trusted users by %s", target, userName)
+	h.bot.saveAutoResponses()
+	switch action {
+	case "add":
+		h.reply(c, "autoresponse.added", i18n.Vars{"n": len(set.Rules()), "trigger": rule.Trigger, "response": rule.Response})
+	case "remove":
+		h.reply(c, "autoresponse.removed", i18n.Vars{"trigger": rule.Trigger})
+	case "enable":
+		h.reply(c, "autoresponse.enabled", i18n.Vars{"trigger": rule.Trigger})
+	default:
+		h.reply(c, "autoresponse.disabled", i18n.Vars{"trigger": rule.Trigger})
+	}
+	h.logger.Infof(h.bot.ctx, "Auto-response %q: %s by %s", rule.Trigger, action, c.User)
 }
 
-func (h *CommandHandler) handleTrustList(userName, channel string, _ []string) {
-	cfg := h.config.GetConfig()
-	if !strings.EqualFold(userName, cfg.Username) {
+func (h *CommandHandler) listAutoResponses(c CommandCall) {
+	rules := h.bot.AutoResponses().Rules()
+	if len(rules) == 0 {
+		h.reply(c, "autoresponse.empty", nil)
 		return
 	}
 
-	users := h.bot.GetTrustedUsers()
-	if len(users) == 0 {
-		h.bot.SafeSay(channel, fmt.Sprintf("@%s, Lista zaufanych jest pusta.", userName))
+	messages := h.bot.Messages()
+	parts := []string{messages.Format("autoresponse.list", nil)}
+	for i, r := range rules {
+		item := messages.Format("autoresponse.item", i18n.Vars{"n": i + 1, "trigger": r.Trigger, "response": r.Response})
+		var details []string
+		if r.Disabled {
+			details = append(details, messages.Format("autoresponse.off", nil))
+		}
+		if r.CooldownSeconds > 0 {
+			details = append(details, messages.Format("autoresponse.cooldown", i18n.Vars{"cooldown": r.Cooldown()}))
+		}
+		if r.Probability > 0 {
+			details = append(details, messages.Format("autoresponse.chance", i18n.Vars{"chance": fmt.Sprintf("%.0f", r.Probability*100)}))
+		}
+		if r.MinBalance > 0 {
+			details = append(details, messages.Format("autoresponse.min", i18n.Vars{"min": r.MinBalance}))
+		}
+		if len(details) > 0 {
+			item += " (" + strings.Join(details, ", ") + ")"
+		}
+		parts = append(parts, item)
+	}
+	for _, message := range splitMessages(parts, " | ") {
+		h.bot.SafeSay(c.Channel, message)
+	}
+}
+
+// handleSetSetting changes one of ports.MutableSettings; the value is the
+// rest of the message, so messages may contain spaces.
+func (h *CommandHandler) handleSetSetting(c CommandCall) {
+	key := ports.Setting(strings.ToUpper(c.Args[0]))
+	err := h.config.Set(key, c.ArgsFrom(1))
+	switch {
+	case errors.Is(err, ports.ErrUnknownSetting):
+		h.reply(c, "setting.unknown", i18n.Vars{"key": c.Args[0], "command": h.config.GetConfig().Prefix + "get"})
+	case errors.Is(err, ports.ErrInvalidSetting):
+		h.reply(c, "setting.invalid", i18n.Vars{"error": err})
+	case err != nil:
+		h.logger.Errorf(h.bot.ctx, "Error saving %s: %v", key, err)
+		h.reply(c, "setting.update_failed", i18n.Vars{"key": key})
+	default:
+		value, _ := h.config.GetConfig().Get(key)
+		h.reply(c, "setting.updated", i18n.Vars{"key": key, "value": value})
+		h.logger.Infof(h.bot.ctx, "%s set %s to %s", c.User, key, value)
+	}
+}
+
+// handleGetSetting shows one of ports.MutableSettings, or all of them.
+func (h *CommandHandler) handleGetSetting(c CommandCall) {
+	cfg := h.config.GetConfig()
+	if len(c.Args) > 0 {
+		key := ports.Setting(strings.ToUpper(c.Args[0]))
+		value, ok := cfg.Get(key)
+		if !ok {
+			h.reply(c, "setting.unknown", i18n.Vars{"key": c.Args[0], "command": cfg.Prefix + "get"})
+			return
+		}
+		h.reply(c, "setting.value", i18n.Vars{"key": key, "value": value})
 		return
 	}
 
-	h.bot.SafeSay(channel, fmt.Sprintf("@%s, Zaufani: %s", userName, strings.Join(users, ", ")))
+	parts := []string{h.bot.Messages().Format("setting.list", nil)}
+	for _, key := range ports.MutableSettings {
+		value, _ := cfg.Get(key)
+		parts = append(parts, string(key)+"="+value)
+	}
+	for _, message := range splitMessages(parts, " | ") {
+		h.bot.SafeSay(c.Channel, message)
+	}
 }
 
-func (h *CommandHandler) handleHelp(userName, channel string, _ []string) {
-	if !h.bot.IsUserTrusted(userName) {
+// handleHelp lists the commands the caller may run, or describes one command.
+func (h *CommandHandler) handleHelp(c CommandCall) {
+	prefix := h.config.GetConfig().Prefix
+	policy := h.bot.Permissions()
+	messages := h.bot.Messages()
+
+	if len(c.Args) > 0 {
+		cmd, ok := h.Lookup(strings.TrimPrefix(c.Args[0], prefix))
+		if !ok {
+			h.reply(c, "help.unknown", i18n.Vars{"usage": c.Command.Usage(prefix, messages)})
+			return
+		}
+		parts := []string{messages.Format("help.command", i18n.Vars{
+			"user":  c.User,
+			"usage": cmd.Usage(prefix, messages),
+			"help":  messages.Format(cmd.Help, nil),
+		})}
+		if len(cmd.Aliases) > 0 {
+			parts = append(parts, messages.Format("help.aliases", i18n.Vars{"aliases": prefix + strings.Join(cmd.Aliases, ", "+prefix)}))
+		}
+		parts = append(parts,
+			messages.Format("help.roles", i18n.Vars{"roles": formatRoles(policy.Required(cmd.Roles, cmd.Names()...))}),
+			messages.Format("help.cooldown", i18n.Vars{"cooldown": cmd.cooldown()}))
+		h.bot.SafeSay(c.Channel, strings.Join(parts, " | "))
 		return
 	}
 
-	cfg := h.config.GetConfig()
-	help := strings.Join([]string{
-		"Komendy (zaufani):",
-		fmt.Sprintf("%s%s - status bota", cfg.Prefix, strings.ToLower(cfg.StatusCommand)),
-		fmt.Sprintf("%sustaw <kwota> - ustawia heist", cfg.Prefix),
-		fmt.Sprintf("%sjakiheist - pokazuje heist", cfg.Prefix),
-		fmt.Sprintf("%sautoslots on/off - auto slots", cfg.Prefix),
-		fmt.Sprintf("%sslotsoff <czas/duration> - planuje wyłączenie", cfg.Prefix),
-		fmt.Sprintf("%shelp - ta pomoc", cfg.Prefix),
-	}, " | ")
-	h.bot.SafeSay(channel, help)
+	parts := []string{messages.Format("help.list", nil)}
+	for _, cmd := range h.commands {
+		if policy.Allows(c.Roles, cmd.Roles, cmd.Names()...) {
+			parts = append(parts, fmt.Sprintf("%s - %s", cmd.Usage(prefix, messages), messages.Format(cmd.Help, nil)))
+		}
+	}
+	for _, message := range splitMessages(parts, " | ") {
+		h.bot.SafeSay(c.Channel, message)
+	}
+}
+
+func formatRoles(roles permissions.Roles) string {
+	names := make([]string, 0, len(roles)+1)
+	for _, role := range roles {
+		names = append(names, string(role))
+	}
+	if !roles.Has(permissions.RoleOwner) {
+		names = append(names, string(permissions.RoleOwner))
+	}
+	return strings.Join(names, "/")
 }
 
 func splitCommand(fullMsg, prefix string) (string, []string, bool) {
-	if !strings.HasPrefix(fullMsg, prefix) {
+	text, ok := cutPrefixFold(fullMsg, prefix)
+	if !ok {
 		return "", nil, false
 	}
-	text := strings.TrimSpace(strings.TrimPrefix(fullMsg, prefix))
+	text = strings.TrimSpace(text)
 	if text == "" {
 		return "", nil, false
 	}
@@ -306,5 +578,3 @@ func splitCommand(fullMsg, prefix string) (string, []string, bool) {
 	}
 	return cmd, args, true
 }
-
-var _ = context.Background
@@ -1,11 +1,61 @@
 package application
 
 import (
+	"context"
+	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"streamgogambler/internal/adapters/config"
+	"streamgogambler/internal/adapters/storage"
+	"streamgogambler/internal/domain/autoresponse"
+	"streamgogambler/internal/domain/i18n"
+	"streamgogambler/internal/domain/permissions"
+	"streamgogambler/internal/ports"
 )
 
+type sayRecorder struct {
+	idleChat
+	mu   sync.Mutex
+	said []string
+}
+
+func (r *sayRecorder) Say(_ context.Context, _, message string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.said = append(r.said, message)
+	return nil
+}
+
+func (r *sayRecorder) Said() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.said...)
+}
+
+func newCommandBot(t *testing.T, spec string) (*BotService, *sayRecorder) {
+	t.Helper()
+
+	policy, err := permissions.ParsePolicy(spec)
+	if err != nil {
+		t.Fatalf("ParsePolicy(%q): %v", spec, err)
+	}
+
+	bot := newChannelBot("foo", 1000)
+	bot.config = config.NewStaticStore(ports.BotConfig{Username: "testuser", Channel: "foo", Prefix: "!", StatusCommand: "status"})
+	bot.policy = &policy
+	bot.trustedUsers = []storage.TrustedUser{{Login: "friend"}}
+	bot.userCmdTimes = make(map[string]time.Time)
+	chat := &sayRecorder{}
+	bot.chat = chat
+	bot.cmdHandler = NewCommandHandler(bot, bot.config, bot.logger)
+	return bot, chat
+}
+
 func TestSplitCommand(t *testing.T) {
 	t.Parallel()
 
@@ -73,6 +123,14 @@ func TestSplitCommand(t *testing.T) {
 			wantArgs: nil,
 			wantOK:   true,
 		},
+		{
+			name:     "multi-char prefix ignores case",
+			fullMsg:  "BOT!Status",
+			prefix:   "bot!",
+			wantCmd:  "status",
+			wantArgs: nil,
+			wantOK:   true,
+		},
 		{
 			name:     "wrong prefix returns false",
 			fullMsg:  "!status",
@@ -143,3 +201,293 @@ func TestSplitCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleCommandEnforcesPolicy(t *testing.T) {
+	t.Parallel()
+
+	moderator := map[string]int{"moderator": 1}
+
+	tests := []struct {
+		name     string
+		spec     string
+		msg      ports.ChatMessage
+		command  string
+		wantSaid bool
+	}{
+		{"trusted runs status", "", ports.ChatMessage{UserName: "friend"}, "!status", true},
+		{"viewer cannot run status", "", ports.ChatMessage{UserName: "viewer"}, "!status", false},
+		{"moderator needs a rule", "", ports.ChatMessage{UserName: "mod", Badges: moderator}, "!autoslots", false},
+		{"moderator allowed by rule", "autoslots=moderator", ports.ChatMessage{UserName: "mod", Badges: moderator}, "!autoslots", true},
+		{"trusted cannot trust", "", ports.ChatMessage{UserName: "friend"}, "!trust other", false},
+		{"owner can trust", "", ports.ChatMessage{UserName: "TestUser"}, "!trust other", true},
+		{"owner bypasses rules", "*=broadcaster", ports.ChatMessage{UserName: "testuser"}, "!status", true},
+		{"everyone rule", "status=everyone", ports.ChatMessage{UserName: "viewer"}, "!status", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			bot, chat := newCommandBot(t, tt.spec)
+			bot.cmdHandler.HandleCommand(tt.msg, tt.command)
+
+			if tt.wantSaid {
+				assert.NotEmpty(t, chat.Said(), "%s should be answered", tt.command)
+			} else {
+				assert.Empty(t, chat.Said(), "%s should be denied", tt.command)
+			}
+		})
+	}
+}
+
+func TestModeratorCommandFromChat(t *testing.T) {
+	t.Parallel()
+
+	bot, chat := newCommandBot(t, "autoslots=moderator|trusted")
+	bot.msgHandler = NewMessageHandler(bot, bot.logger)
+
+	bot.msgHandler.HandleMessage(ports.ChatMessage{UserName: "mod", Channel: "foo", Text: "!autoslots on", Badges: map[string]int{"moderator": 1}})
+	bot.msgHandler.HandleMessage(ports.ChatMessage{UserName: "viewer", Channel: "foo", Text: "!autoslots off"})
+
+	assert.True(t, bot.IsAutoSlotsEnabled(), "moderator enabled auto slots, viewer was ignored")
+	assert.Len(t, chat.Said(), 1, "one reply")
+}
+
+func TestTrustedUsersIdentifiedByUserID(t *testing.T) {
+	t.Parallel()
+
+	bot, chat := newCommandBot(t, "")
+	bot.msgHandler = NewMessageHandler(bot, bot.logger)
+
+	bot.msgHandler.HandleMessage(ports.ChatMessage{UserName: "friend", UserID: "1", DisplayName: "Friend", Channel: "foo", Text: "hej"})
+	assert.Equal(t, []storage.TrustedUser{{ID: "1", Login: "friend", DisplayName: "Friend"}}, bot.trustedUsers, "ID pinned on first message")
+
+	bot.msgHandler.HandleMessage(ports.ChatMessage{UserName: "friend", UserID: "2", Channel: "foo", Text: "!status"})
+	assert.Empty(t, chat.Said(), "impersonator on the old name is not trusted")
+
+	bot.msgHandler.HandleMessage(ports.ChatMessage{UserName: "newfriend", UserID: "1", DisplayName: "NewFriend", Channel: "foo", Text: "!status"})
+	assert.Len(t, chat.Said(), 1, "renamed user keeps trust")
+	assert.Equal(t, []string{"NewFriend"}, bot.GetTrustedUsers(), "listed by the new display name")
+}
+
+func TestTrustStoresKnownUserID(t *testing.T) {
+	t.Parallel()
+
+	bot, chat := newCommandBot(t, "")
+	bot.msgHandler = NewMessageHandler(bot, bot.logger)
+
+	bot.msgHandler.HandleMessage(ports.ChatMessage{UserName: "viewer", UserID: "7", DisplayName: "Viewer", Channel: "foo", Text: "hej"})
+	bot.msgHandler.HandleMessage(ports.ChatMessage{UserName: "testuser", Channel: "foo", Text: "!trust Viewer"})
+	bot.msgHandler.HandleMessage(ports.ChatMessage{UserName: "testuser", Channel: "foo", Text: "!trust stranger"})
+
+	assert.Contains(t, bot.trustedUsers, storage.TrustedUser{ID: "7", Login: "viewer", DisplayName: "Viewer"}, "seen user stored with ID")
+	assert.Contains(t, bot.trustedUsers, storage.TrustedUser{Login: "stranger"}, "unseen user waits for their first message")
+	if said := chat.Said(); assert.Len(t, said, 2) {
+		assert.NotContains(t, said[0], "pierwszej wiadomości", "no pinning note for a known user")
+		assert.Contains(t, said[1], "pierwszej wiadomości", "pinning note for an unseen user")
+	}
+}
+
+func TestCommandAliasesAndUsageErrors(t *testing.T) {
+	t.Parallel()
+
+	bot, chat := newCommandBot(t, "")
+	owner := ports.ChatMessage{UserName: "testuser", Channel: "foo"}
+
+	bot.cmdHandler.HandleCommand(owner, "!autosloty on")
+	assert.True(t, bot.IsAutoSlotsEnabled(), "Polish alias runs autoslots")
+
+	bot.cmdHandler.HandleCommand(owner, "!autoslots maybe")
+	bot.cmdHandler.HandleCommand(owner, "!zaufaj")
+	bot.cmdHandler.HandleCommand(owner, "!gry poker")
+
+	said := chat.Said()
+	require.Len(t, said, 4)
+	assert.Equal(t, "@testuser, Użyj: !autoslots [on/off]", said[1], "invalid choice")
+	assert.Equal(t, "@testuser, Użyj: !trust <nick>", said[2], "missing argument, shown under the command's name")
+	assert.Equal(t, "@testuser, Użyj: !gry [heist/ffa/boss]", said[3], "unknown game")
+	assert.True(t, bot.IsAutoSlotsEnabled(), "invalid argument leaves auto slots on")
+}
+
+func TestCommandCooldownIsPerCommand(t *testing.T) {
+	t.Parallel()
+
+	bot, chat := newCommandBot(t, "")
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	bot.now = func() time.Time { return now }
+	friend := ports.ChatMessage{UserName: "friend", Channel: "foo"}
+
+	bot.cmdHandler.HandleCommand(friend, "!status")
+	bot.cmdHandler.HandleCommand(friend, "!status")
+	bot.cmdHandler.HandleCommand(friend, "!jakiheist")
+	assert.Len(t, chat.Said(), 2, "second !status is on cooldown, other commands are not")
+
+	now = now.Add(DefaultCommandCooldown + time.Millisecond)
+	bot.cmdHandler.HandleCommand(friend, "!status")
+	assert.Len(t, chat.Said(), 3, "cooldown over")
+
+	bot.cmdHandler.HandleCommand(ports.ChatMessage{UserName: "testuser", Channel: "foo"}, "!status")
+	bot.cmdHandler.HandleCommand(ports.ChatMessage{UserName: "testuser", Channel: "foo"}, "!status")
+	assert.Len(t, chat.Said(), 5, "owner has no cooldown")
+}
+
+func TestHelpIsGeneratedFromRegistry(t *testing.T) {
+	t.Parallel()
+
+	bot, chat := newCommandBot(t, "")
+
+	bot.cmdHandler.HandleCommand(ports.ChatMessage{UserName: "friend", Channel: "foo"}, "!pomoc")
+	bot.cmdHandler.HandleCommand(ports.ChatMessage{UserName: "testuser", Channel: "foo"}, "!help")
+	bot.cmdHandler.HandleCommand(ports.ChatMessage{UserName: "testuser", Channel: "foo"}, "!help !zaufaj")
+	bot.cmdHandler.HandleCommand(ports.ChatMessage{UserName: "testuser", Channel: "foo"}, "!help nope")
+
+	said := chat.Said()
+	require.Len(t, said, 6, "both lists are split in two messages")
+	assert.Contains(t, said[0], "!ustaw <kwota> - ustawia heist", "usage and help text")
+	assert.NotContains(t, said[0]+said[1], "!trust", "owner-only commands hidden from trusted users")
+	assert.Contains(t, said[2]+said[3], "!trust <nick>", "owner sees every command")
+	for _, message := range said[:4] {
+		assert.LessOrEqual(t, len(message), MaxChatMessageLen, "help message length")
+	}
+	assert.Equal(t, "@testuser, !trust <nick> - dodaje zaufanego | Aliasy: !zaufaj | Dostęp: owner | Odstęp: 2s", said[4])
+	assert.Equal(t, "@testuser, Nieznana komenda! Użyj: !help [komenda]", said[5])
+}
+
+func TestRepliesFollowBotLanguage(t *testing.T) {
+	t.Parallel()
+
+	english, _ := i18n.Builtin("en")
+	bot, chat := newCommandBot(t, "")
+	bot.messages = english
+	owner := ports.ChatMessage{UserName: "testuser", Channel: "foo"}
+
+	bot.cmdHandler.HandleCommand(owner, "!jakiheist")
+	bot.cmdHandler.HandleCommand(owner, "!trust")
+	bot.cmdHandler.HandleCommand(owner, "!help trust")
+
+	assert.Equal(t, []string{
+		"@testuser, The heist amount is 0 ;)",
+		"@testuser, Usage: !trust <user>",
+		"@testuser, !trust <user> - trusts a user | Aliases: !zaufaj | Access: owner | Cooldown: 2s",
+	}, chat.Said())
+}
+
+func TestAutoResponseCommands(t *testing.T) {
+	t.Parallel()
+
+	bot, chat := newCommandBot(t, "")
+	store := storage.NewAutoResponsesStore(filepath.Join(t.TempDir(), "auto_responses.json"))
+	WithAutoResponses([]autoresponse.Rule{{Trigger: "Type !ffa to start!", Response: "!ffa"}}, store)(bot)
+	owner := ports.ChatMessage{UserName: "testuser", Channel: "foo"}
+
+	bot.cmdHandler.HandleCommand(owner, "!autoresponse add Raffle! | !join | cooldown=1m chance=50 min=500")
+	bot.cmdHandler.HandleCommand(owner, "!autoresponse add Raffle! | !other")
+	bot.cmdHandler.HandleCommand(owner, "!autoresponse add Raffle!")
+	bot.cmdHandler.HandleCommand(owner, "!autoodpowiedz disable 1")
+	bot.cmdHandler.HandleCommand(owner, "!autoresponse list")
+	bot.cmdHandler.HandleCommand(owner, "!autoresponse remove 7")
+	bot.cmdHandler.HandleCommand(owner, "!autoresponse remove")
+
+	assert.Equal(t, []string{
+		"@testuser, Dodano auto-odpowiedź #2: Raffle! → !join",
+		`@testuser, Auto-odpowiedź na "Raffle!" już istnieje!`,
+		"@testuser, Nieprawidłowa auto-odpowiedź! Użyj: !autoresponse add <wyzwalacz> | <odpowiedź> [| cooldown=30s chance=50 min=1000]",
+		`@testuser, Wyłączono auto-odpowiedź na "Type !ffa to start!"!`,
+		"Auto-odpowiedzi: | 1. Type !ffa to start! → !ffa (wyłączona) | 2. Raffle! → !join (odstęp 1m0s, szansa 50%, min. 500 bombs)",
+		"@testuser, Nie ma auto-odpowiedzi 7. Sprawdź: !autoresponse list",
+		"@testuser, Użyj: !autoresponse <list/add/remove/enable/disable> [reguła]",
+	}, chat.Said())
+
+	saved, err := store.Load()
+	require.NoError(t, err, "Load()")
+	assert.Equal(t, []autoresponse.Rule{
+		{Trigger: "Type !ffa to start!", Response: "!ffa", Disabled: true},
+		{Trigger: "Raffle!", Response: "!join", CooldownSeconds: 60, Probability: 0.5, MinBalance: 500},
+	}, saved, "changes are saved")
+}
+
+func TestAutoResponseAddedFromChatKeepsTriggerCase(t *testing.T) {
+	t.Parallel()
+
+	bot, chat := newCommandBot(t, "")
+	WithAutoResponses(nil, nil)(bot)
+	h := NewMessageHandler(bot, bot.logger)
+
+	h.HandleMessage(ports.ChatMessage{UserName: "testuser", Channel: "foo", Text: "!AutoResponse add Type !FFA to start! | !ffa"})
+	h.HandleMessage(ports.ChatMessage{UserName: "friend", Channel: "foo", Text: "@TestUser, !autoresponse add Raffle! | !join"})
+	bot.respondTo("foo", "Type !FFA to start!")
+
+	assert.Equal(t, []autoresponse.Rule{
+		{Trigger: "Type !FFA to start!", Response: "!ffa"},
+		{Trigger: "Raffle!", Response: "!join"},
+	}, bot.AutoResponses().Rules(), "triggers keep their case")
+	assert.Contains(t, chat.Said(), "!ffa", "answered the boss bot's announcement")
+}
+
+func TestAutoResponsesAnswerFirstMatchingRule(t *testing.T) {
+	t.Parallel()
+
+	bot, chat := newCommandBot(t, "")
+	WithAutoResponses([]autoresponse.Rule{
+		{Trigger: "Raffle for rich people!", Response: "!rich", MinBalance: 5000},
+		{Trigger: "Raffle", Response: "!join", CooldownSeconds: 60},
+		{Trigger: "for", Response: "!fallback"},
+	}, nil)(bot)
+
+	bot.respondTo("foo", "Raffle for rich people! Type !join")
+	bot.respondTo("foo", "Raffle for rich people! Type !join")
+
+	assert.Equal(t, []string{"!join", "!fallback"}, chat.Said(), "first applicable rule wins, one on cooldown gives way")
+}
+
+func TestSetAndGetSettings(t *testing.T) {
+	t.Parallel()
+
+	bot, chat := newCommandBot(t, "")
+	owner := ports.ChatMessage{UserName: "testuser", Channel: "foo"}
+
+	bot.cmdHandler.HandleCommand(owner, "!set slots_cost 3000")
+	bot.cmdHandler.HandleCommand(owner, "!zmien band_message Do widzenia wszystkim!")
+	bot.cmdHandler.HandleCommand(owner, "!set points_as_delta maybe")
+	bot.cmdHandler.HandleCommand(owner, "!set twitch_oauth x")
+	bot.cmdHandler.HandleCommand(owner, "!get slots_cost")
+	bot.cmdHandler.HandleCommand(owner, "!pokaz")
+	bot.cmdHandler.HandleCommand(ports.ChatMessage{UserName: "stranger", Channel: "foo"}, "!set slots_cost 1")
+
+	said := chat.Said()
+	require.Len(t, said, 6, "replies")
+	assert.Equal(t, "@testuser, Ustawiono SLOTS_COST na 3000!", said[0])
+	assert.Equal(t, "@testuser, Ustawiono BAND_MESSAGE na Do widzenia wszystkim!!", said[1], "value with spaces")
+	assert.Equal(t, `@testuser, Nie zapisano: POINTS_AS_DELTA: invalid value: "maybe" is not true or false`, said[2])
+	assert.Equal(t, "@testuser, Nieznane ustawienie twitch_oauth! Sprawdź: !get", said[3])
+	assert.Equal(t, "@testuser, SLOTS_COST = 3000", said[4])
+	assert.Contains(t, said[5], "Ustawienia: | HEIST_AMOUNT=0 | SLOTS_COST=3000 |", "list")
+
+	cfg := bot.config.GetConfig()
+	assert.Equal(t, 3000, cfg.SlotsCost, "stranger may not change settings")
+	assert.Equal(t, "Do widzenia wszystkim!", cfg.BandMessage)
+	assert.False(t, cfg.PointsAsDelta, "invalid value not applied")
+}
+
+func TestSetFromChatKeepsValueCaseAndReschedulesSync(t *testing.T) {
+	t.Parallel()
+
+	bot, chat := newCommandBot(t, "")
+	bot.syncReschedule = make(chan struct{}, 1)
+	unsubscribe := bot.config.Subscribe(bot.onConfigChange)
+	defer unsubscribe()
+	h := NewMessageHandler(bot, bot.logger)
+
+	h.HandleMessage(ports.ChatMessage{UserName: "testuser", Channel: "foo", Text: "!SET band_message BAND"})
+	h.HandleMessage(ports.ChatMessage{UserName: "testuser", Channel: "foo", Text: "!set Balance_Sync_Interval 10"})
+	assert.Equal(t, "BAND", bot.config.GetConfig().BandMessage, "value keeps its case")
+	h.HandleMessage(ports.ChatMessage{UserName: "testuser", Channel: "foo", Text: "!set  band_message   Do  widzenia\twszystkim "})
+
+	assert.Equal(t, []string{
+		"@testuser, Ustawiono BAND_MESSAGE na BAND!",
+		"@testuser, Ustawiono BALANCE_SYNC_INTERVAL na 10!",
+		"@testuser, Ustawiono BAND_MESSAGE na Do  widzenia\twszystkim!",
+	}, chat.Said())
+	assert.Equal(t, "Do  widzenia\twszystkim", bot.config.GetConfig().BandMessage, "value keeps its spacing")
+	assert.Len(t, bot.syncReschedule, 1, "balance sync loop rescheduled")
+}
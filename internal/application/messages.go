@@ -3,70 +3,84 @@ package application
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 
 	"streamgogambler/internal/adapters/logging"
+	"streamgogambler/internal/domain/gambling"
 	"streamgogambler/internal/domain/parsing"
+	"streamgogambler/internal/domain/wallet"
 	"streamgogambler/internal/ports"
 )
 
 type MessageHandler struct {
-	bot    *BotService
-	logger *logging.Logger
+	bot         *BotService
+	logger      *logging.Logger
+	reassembler *parsing.Reassembler
 }
 
 func NewMessageHandler(bot *BotService, logger *logging.Logger) *MessageHandler {
-	return &MessageHandler{
+	h := &MessageHandler{
 		bot:    bot,
 		logger: logger,
 	}
+	h.reassembler = parsing.NewReassembler(FragmentTimeout, func(text string) bool {
+		return bot.Dialect().IsIncomplete(text)
+	}, h.handleReassembled)
+	h.reassembler.SetClock(bot.clock)
+	return h
 }
 
 func (h *MessageHandler) HandleMessage(msg ports.ChatMessage) {
 	cfg := h.bot.Config().GetConfig()
 
-	if strings.EqualFold(msg.UserName, cfg.BossBotName) {
+	if h.bot.IsBossBot(msg) {
 		h.handleTrustedBotMessage(msg, cfg)
 		return
 	}
-	message := strings.ToLower(msg.Text)
-	if h.isCommandFromOwner(msg.UserName, message, cfg) {
-		h.bot.cmdHandler.HandleCommand(msg.UserName, msg.Channel, message)
-	} else if h.bot.IsUserTrusted(msg.UserName) {
-		if cmd, ok := h.extractTrustedUserCommand(message, cfg); ok {
-			h.bot.cmdHandler.HandleCommand(msg.UserName, msg.Channel, cmd)
-		}
+	h.bot.noteChatter(msg)
+
+	if h.isCommandFromOwner(msg.UserName, msg.Text, cfg) {
+		h.bot.cmdHandler.HandleCommand(msg, msg.Text)
+	} else if cmd, ok := h.extractCommand(msg.Text, cfg); ok {
+		h.bot.cmdHandler.HandleCommand(msg, cmd)
 	}
 }
 
 func (h *MessageHandler) handleTrustedBotMessage(msg ports.ChatMessage, cfg ports.BotConfig) {
-	text := h.handleSplitMessage(msg.Text)
-	lower := strings.ToLower(text)
+	if h.bufferFragment(msg) {
+		return
+	}
+	h.processTrustedBotMessage(msg, cfg)
+}
 
-	if strings.Contains(lower, "bombs:") && strings.Contains(lower, strings.ToLower(cfg.Username)) {
+func (h *MessageHandler) processTrustedBotMessage(msg ports.ChatMessage, cfg ports.BotConfig) {
+	d := h.bot.Dialect()
+	text := msg.Text
+
+	if d.IsBalanceReply(text, cfg.Username) {
 		h.handleBombsResponse(text, cfg.Username)
 		return
 	}
 
-	if strings.HasPrefix(text, cfg.Username+" pulls the lever and waits for the roll") {
-		h.handleSlotsResponse(text, cfg.Username)
+	if d.IsSlotsReply(text, cfg.Username) {
+		bet, _ := h.settleBet(wallet.GameSlots)
+		h.handleSlotsResponse(text, cfg, bet)
 		return
 	}
 
-	if strings.Contains(text, cfg.Username+" (") {
-		if strings.Contains(text, "Results from the Heist:") {
-			h.handleHeistResult(text, cfg.Username)
-			return
-		}
-		if strings.Contains(text, "The dust finally settled") {
-			h.handleArenaResult(text, cfg.Username)
-			return
-		}
+	if game, result, ok := parseGameResult(d, text); ok {
+		h.handleGameResult(game, result, text, cfg.Username)
+		return
+	}
+
+	if d.IsPointsReply(text, cfg.Username) {
 		h.handlePointsResponse(text, cfg)
 		return
 	}
 
-	if h.checkSplitMessageStart(text, cfg.Username) {
+	if game, ok := d.ConfirmedEntry(text, cfg.Username); ok {
+		h.settleBet(wallet.Game(game))
 		return
 	}
 
@@ -79,93 +93,156 @@ func (h *MessageHandler) isCommandFromOwner(username string, message string, cfg
 		return false
 	}
 
-	return strings.HasPrefix(message, strings.ToLower(cfg.Prefix))
+	_, ok := cutPrefixFold(message, cfg.Prefix)
+	return ok
 }
 
-func (h *MessageHandler) extractTrustedUserCommand(message string, cfg ports.BotConfig) (string, bool) {
-	lower := strings.ToLower(message)
-	usernamePrefix := strings.ToLower(cfg.Username)
-	prefixLower := strings.ToLower(cfg.Prefix)
-
-	if strings.HasPrefix(lower, prefixLower) {
-		return lower, true
-	}
-
-	atMention := fmt.Sprintf("@%s", usernamePrefix)
-	if strings.HasPrefix(lower, atMention) {
-		rest := strings.TrimPrefix(lower, atMention)
-		rest = strings.TrimSpace(strings.TrimPrefix(rest, ","))
-		rest = strings.TrimSpace(rest)
-		if strings.HasPrefix(rest, prefixLower) {
-			return rest, true
-		}
+// extractCommand returns the command addressed to the bot, keeping the case
+// of its arguments.
+func (h *MessageHandler) extractCommand(message string, cfg ports.BotConfig) (string, bool) {
+	if _, ok := cutPrefixFold(message, cfg.Prefix); ok {
+		return message, true
 	}
 
-	if strings.HasPrefix(lower, usernamePrefix) {
-		rest := strings.TrimPrefix(lower, usernamePrefix)
-		rest = strings.TrimSpace(strings.TrimPrefix(rest, ","))
-		rest = strings.TrimSpace(rest)
-		if strings.HasPrefix(rest, prefixLower) {
-			return rest, true
+	for _, mention := range []string{fmt.Sprintf("@%s", cfg.Username), cfg.Username} {
+		if rest, ok := cutPrefixFold(message, mention); ok {
+			rest = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(rest), ","))
+			if _, ok := cutPrefixFold(rest, cfg.Prefix); ok {
+				return rest, true
+			}
 		}
 	}
 
 	return "", false
 }
 
+// cutPrefixFold is strings.CutPrefix ignoring case.
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) < len(prefix) || !strings.EqualFold(s[:len(prefix)], prefix) {
+		return s, false
+	}
+	return s[len(prefix):], true
+}
+
 func (h *MessageHandler) handleBombsResponse(text, username string) {
-	if count, ok := parsing.ParseBombs(text, username); ok {
-		h.bot.Wallet().SetBalance(count)
-		h.logger.Infof(h.bot.ctx, "Updated bombs for %s: %d", username, count)
+	if count, ok := h.bot.Dialect().ParseBalance(text, username); ok {
+		drift := h.bot.SyncBalance(count, text)
+		if drift != 0 {
+			h.logger.Warnf(h.bot.ctx, "Balance drift for %s: %+d (corrected to %d)", username, drift, count)
+		} else {
+			h.logger.Infof(h.bot.ctx, "Updated bombs for %s: %d", username, count)
+		}
 	} else {
 		h.logger.Debugf(h.bot.ctx, "Could not parse bombs from: %s", text)
+		h.bot.RecordParseFailure("balance")
+		h.bot.RequestBalanceSync("unparsed balance reply")
 	}
 }
 
-func (h *MessageHandler) handleSlotsResponse(text, username string) {
-	if result, ok := parsing.ParseSlotsDelta(text, username); ok {
+func (h *MessageHandler) handleSlotsResponse(text string, cfg ports.BotConfig, bet wallet.Bet) {
+	if result, ok := h.bot.Dialect().ParseSlots(text, cfg.Username, slotsPricing(cfg)); ok {
 		if result.Delta != 0 {
-			h.bot.Wallet().AddBalance(result.Delta)
+			h.bot.Wallet().AddBalanceFor(result.Delta, wallet.Reason{Game: wallet.GameSlots, Message: text})
 		}
+		h.bot.RecordResult(wallet.GameSlots, bet.Amount, result.Delta)
+		h.bot.RecordSlotsOutcome(result.Outcome)
 		h.bot.RecordSlotsPlayed()
-		h.logger.Infof(h.bot.ctx, "Slots result: %s | Bombs: %d", result.Outcome, h.bot.Wallet().GetBalance())
+		source := "inferred"
+		if result.Observed {
+			source = "observed"
+		}
+		h.logger.Infof(h.bot.ctx, "Slots result: %s (+%d, %s) | Bombs: %d", result.Outcome, result.Delta, source, h.bot.Wallet().GetBalance())
 	} else {
 		h.logger.Debugf(h.bot.ctx, "Unknown slots result: %s", text)
+		h.bot.RecordParseFailure("slots")
+		h.bot.RequestBalanceSync("unknown slots result")
 	}
 }
 
 func (h *MessageHandler) handlePointsResponse(text string, cfg ports.BotConfig) {
-	if points, ok := parsing.ParsePoints(text, cfg.Username); ok {
+	if points, ok := h.bot.Dialect().ParsePoints(text, cfg.Username); ok {
 		old := h.bot.Wallet().GetBalance()
 		if cfg.PointsAsDelta {
-			h.bot.Wallet().AddBalance(points)
+			h.bot.Wallet().AddBalanceFor(points, wallet.Reason{Game: wallet.GamePoints, Message: text})
 			h.logger.Infof(h.bot.ctx, "+%d points → Bombs: %d → %d", points, old, h.bot.Wallet().GetBalance())
 		} else {
-			h.bot.Wallet().SetBalance(points)
+			h.bot.Wallet().SetBalanceFor(points, wallet.Reason{Game: wallet.GameSync, Message: text})
 			h.logger.Infof(h.bot.ctx, "Set bombs to %d (from points)", points)
 		}
 	} else {
 		h.logger.Debugf(h.bot.ctx, "Could not parse points from: %s", text)
+		h.bot.RecordParseFailure("points")
+		h.bot.RequestBalanceSync("unparsed points")
 	}
 }
 
-func (h *MessageHandler) handleHeistResult(text, username string) {
-	if payout, ok := parsing.ParsePoints(text, username); ok {
-		old := h.bot.Wallet().GetBalance()
-		h.bot.Wallet().AddBalance(payout)
-		h.logger.Infof(h.bot.ctx, "Heist finished! Won: %d | Bombs: %d → %d", payout, old, h.bot.Wallet().GetBalance())
-	} else {
-		h.logger.Debugf(h.bot.ctx, "Could not parse heist payout from: %s", text)
+// parseGameResult recognizes the results of a heist, arena or boss fight.
+func parseGameResult(d *parsing.Dialect, text string) (wallet.Game, parsing.GameResult, bool) {
+	if result, ok := d.ParseHeistResult(text); ok {
+		return wallet.GameHeist, result, true
 	}
+	if result, ok := d.ParseArenaResult(text); ok {
+		return wallet.GameFFA, result, true
+	}
+	if result, ok := d.ParseBossResult(text); ok {
+		return wallet.GameBoss, result, true
+	}
+	return "", parsing.GameResult{}, false
 }
 
-func (h *MessageHandler) handleArenaResult(text, username string) {
-	if payout, ok := parsing.ParsePoints(text, username); ok {
+// handleGameResult settles our bet on a finished game, credits our payout and
+// records the game in the game log.
+func (h *MessageHandler) handleGameResult(game wallet.Game, result parsing.GameResult, text, username string) {
+	bet, joined := h.settleBet(game)
+	payout, survived := result.PayoutOf(username)
+
+	record := gambling.GameRecord{
+		Game:      string(game),
+		Crew:      result.Survivors(),
+		Total:     result.Total(),
+		Joined:    joined || survived,
+		Survived:  survived,
+		Placement: result.PlacementOf(username),
+		Stake:     bet.Amount,
+	}
+
+	switch {
+	case survived:
+		record.Return = payout
+		h.bot.RecordResult(game, bet.Amount, payout)
 		old := h.bot.Wallet().GetBalance()
-		h.bot.Wallet().AddBalance(payout)
-		h.logger.Infof(h.bot.ctx, "Arena finished! Won: %d | Bombs: %d → %d", payout, old, h.bot.Wallet().GetBalance())
-	} else {
-		h.logger.Debugf(h.bot.ctx, "Could not parse arena payout from: %s", text)
+		h.bot.Wallet().AddBalanceFor(payout, wallet.Reason{Game: game, Message: text})
+		h.logger.Infof(h.bot.ctx, "%s finished! Won: %d (#%d of %d) | Bombs: %d → %d",
+			gameTitle(game), payout, record.Placement, record.Crew, old, h.bot.Wallet().GetBalance())
+	case h.bot.Dialect().MentionsUser(text, username):
+		record.Unresolved = true
+		if joined {
+			h.bot.RecordUnresolved(game, bet.Amount)
+		}
+		h.logger.Warnf(h.bot.ctx, "Could not parse %s payout from: %s", game, text)
+		h.bot.RecordParseFailure(string(game))
+		h.bot.RequestBalanceSync("unparsed " + string(game) + " payout")
+	case joined:
+		record.Crew++
+		h.bot.RecordResult(game, bet.Amount, 0)
+		h.logger.Infof(h.bot.ctx, "%s finished, %s lost %d bombs", gameTitle(game), username, bet.Amount)
+	default:
+		h.logger.Infof(h.bot.ctx, "%s finished without payout for %s", gameTitle(game), username)
+	}
+
+	h.bot.RecordGame(record)
+}
+
+func gameTitle(game wallet.Game) string {
+	switch game {
+	case wallet.GameHeist:
+		return "Heist"
+	case wallet.GameFFA:
+		return "Arena"
+	case wallet.GameBoss:
+		return "Boss fight"
+	default:
+		return string(game)
 	}
 }
 
@@ -178,63 +255,114 @@ func (h *MessageHandler) handleBossBotPrompts(text, channel string, cfg ports.Bo
 		return
 	}
 
-	for trigger, response := range cfg.AutoResponses {
-		if strings.Contains(text, trigger) {
-			if response == "!heist" {
-				response = fmt.Sprintf("!heist %d", cfg.DefaultHeist)
-			}
-			h.bot.SafeSay(channel, response)
-			break
-		}
-	}
+	h.bot.respondTo(channel, text)
 }
 
 func (h *MessageHandler) detectCooldown(text, username string) bool {
-	lower := strings.ToLower(text)
-	userLower := strings.ToLower(username)
-
-	if strings.HasPrefix(lower, userLower) && strings.Contains(lower, "cooldown") {
+	if h.bot.Dialect().IsCooldown(text, username) {
 		h.logger.Warnf(h.bot.ctx, "Bot is on cooldown: %s", text)
+		h.refundRejectedBet(text)
 		return true
 	}
 	return false
 }
 
 func (h *MessageHandler) detectInsufficientBombs(text, username string) bool {
-	lower := strings.ToLower(text)
-	userLower := strings.ToLower(username)
-
-	if strings.Contains(lower, userLower) && strings.Contains(lower, "doesn't have") && strings.Contains(lower, "bombs") {
+	if h.bot.Dialect().IsInsufficientFunds(text, username) {
 		h.logger.Warnf(h.bot.ctx, "Not enough bombs! %s", text)
+		h.refundRejectedBet(text)
+		h.bot.RequestBalanceSync("insufficient bombs")
 		return true
 	}
 	return false
 }
 
-func (h *MessageHandler) handleSplitMessage(text string) string {
-	if len(text) > 0 && text[0] == '(' {
-		pending := h.bot.GetPendingArenaMsg()
-		if pending != "" {
-			combined := pending + " " + text
-			h.logger.Debugf(h.bot.ctx, "Combined split message: %s", combined)
-			return combined
+func (h *MessageHandler) settleBet(game wallet.Game) (wallet.Bet, bool) {
+	bet, ok := h.bot.Escrow().Commit(game)
+	switch {
+	case ok && bet.Late:
+		h.logger.Infof(h.bot.ctx, "Late reply to %s - took back the %d bombs refunded when it expired", bet.Message, bet.Amount)
+	case ok:
+		h.logger.Debugf(h.bot.ctx, "Confirmed %s bet of %d bombs", bet.Game, bet.Amount)
+	}
+	return bet, ok
+}
+
+// refundRejectedBet refunds the latest bet of the game a rejection names. A
+// rejection naming no game refunds the latest bet only when every pending bet
+// is for the same game; otherwise the balance sync corrects the wallet.
+func (h *MessageHandler) refundRejectedBet(text string) {
+	var games []wallet.Game
+	for _, game := range h.bot.Dialect().GamesIn(text) {
+		games = append(games, wallet.Game(game))
+	}
+	if len(games) == 0 {
+		for _, bet := range h.bot.Escrow().Pending() {
+			if !slices.Contains(games, bet.Game) {
+				games = append(games, bet.Game)
+			}
 		}
-	} else {
-		h.bot.ClearPendingArenaMsg()
+		if len(games) > 1 {
+			h.logger.Debugf(h.bot.ctx, "Rejection does not name one of the pending %v bets: %s", games, text)
+			h.bot.RequestBalanceSync("rejected bet of unknown game")
+			return
+		}
+	}
+
+	if bet, ok := h.bot.Escrow().RollbackLatest(text, games...); ok {
+		h.logger.Infof(h.bot.ctx, "Refunded %d bombs for rejected %s", bet.Amount, bet.Message)
 	}
-	return text
 }
 
-func (h *MessageHandler) checkSplitMessageStart(text, username string) bool {
-	if !strings.Contains(text, username+" (") && strings.HasSuffix(text, username) {
-		if strings.Contains(text, "Results from the Heist:") ||
-			strings.Contains(text, "The dust finally settled") {
-			h.bot.SetPendingArenaMsg(text)
-			h.logger.Debugf(h.bot.ctx, "Detected split message, buffering...")
-			return true
+// bufferFragment hands split boss bot messages to the reassembler: the start
+// of a game result that stops mid-list, and the fragments continuing it.
+func (h *MessageHandler) bufferFragment(msg ports.ChatMessage) bool {
+	if h.reassembler == nil {
+		return false
+	}
+
+	d := h.bot.Dialect()
+	f := parsing.Fragment{Sender: msg.UserName, Channel: msg.Channel, Text: msg.Text}
+
+	if game, _, ok := parseGameResult(d, msg.Text); ok {
+		if !d.IsIncomplete(msg.Text) {
+			return false
 		}
+		f.Kind = string(game)
+		h.reassembler.Start(f)
+		h.logger.Debugf(h.bot.ctx, "Detected split %s result, buffering...", game)
+		return true
 	}
-	return false
+
+	return d.IsContinuation(msg.Text) && h.reassembler.Continue(f)
+}
+
+func (h *MessageHandler) handleReassembled(m parsing.Reassembled) {
+	if m.Complete {
+		h.logger.Debugf(h.bot.ctx, "Combined %d-part message: %s", m.Parts, m.Text)
+	} else {
+		h.logger.Warnf(h.bot.ctx, "Incomplete %s result after %d parts: %s", m.Kind, m.Parts, m.Text)
+		h.bot.RecordParseFailure(m.Kind)
+		h.bot.RequestBalanceSync("incomplete " + m.Kind + " result")
+	}
+	msg := ports.ChatMessage{Channel: m.Channel, UserName: m.Sender, Text: m.Text}
+	h.processTrustedBotMessage(msg, h.bot.Config().GetConfig())
+}
+
+// ExpireFragments flushes split messages whose remaining fragments never
+// arrived.
+func (h *MessageHandler) ExpireFragments() {
+	if h.reassembler != nil {
+		h.reassembler.Expire()
+	}
+}
+
+func slotsPricing(cfg ports.BotConfig) parsing.SlotsPricing {
+	pricing := parsing.DefaultSlotsPricing(cfg.SlotsCost)
+	for outcome, mult := range cfg.SlotsPayouts {
+		pricing.Payouts[parsing.SlotsOutcome(outcome)] = mult
+	}
+	return pricing
 }
 
 var _ = context.Background
@@ -3,22 +3,28 @@ package application
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"streamgogambler/internal/adapters/config"
 	"streamgogambler/internal/adapters/logging"
+	"streamgogambler/internal/domain/gambling"
+	"streamgogambler/internal/domain/parsing"
+	"streamgogambler/internal/domain/wallet"
 	"streamgogambler/internal/ports"
 )
 
 func newTestMessageHandler() *MessageHandler {
 	logger := logging.New(logging.LevelDebug)
+	w := wallet.New(0)
 	bot := &BotService{
-		ctx: context.Background(),
-	}
-	return &MessageHandler{
-		bot:    bot,
-		logger: logger,
+		ctx:    context.Background(),
+		wallet: w,
+		escrow: wallet.NewEscrow(w),
 	}
+	return NewMessageHandler(bot, logger)
 }
 
 func testConfig(username, prefix string) ports.BotConfig {
@@ -109,7 +115,7 @@ func TestIsCommandFromOwner(t *testing.T) {
 	}
 }
 
-func TestExtractTrustedUserCommand(t *testing.T) {
+func TestExtractCommand(t *testing.T) {
 	t.Parallel()
 
 	logger := logging.New(logging.LevelDebug)
@@ -133,7 +139,7 @@ func TestExtractTrustedUserCommand(t *testing.T) {
 			name:    "direct prefix case insensitive",
 			message: "!STATUS",
 			cfg:     testConfig("botuser", "!"),
-			wantCmd: "!status",
+			wantCmd: "!STATUS",
 			wantOK:  true,
 		},
 		{
@@ -171,6 +177,13 @@ func TestExtractTrustedUserCommand(t *testing.T) {
 			wantCmd: "!ustaw 2000",
 			wantOK:  true,
 		},
+		{
+			name:    "@mention keeps the case of args",
+			message: "@BotUser !addresponse Type !FFA",
+			cfg:     testConfig("botuser", "!"),
+			wantCmd: "!addresponse Type !FFA",
+			wantOK:  true,
+		},
 		{
 			name:    "username with prefix",
 			message: "botuser !status",
@@ -233,9 +246,9 @@ func TestExtractTrustedUserCommand(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			gotCmd, gotOK := h.extractTrustedUserCommand(tt.message, tt.cfg)
-			assert.Equal(t, tt.wantOK, gotOK, "extractTrustedUserCommand(%q) ok mismatch", tt.message)
-			assert.Equal(t, tt.wantCmd, gotCmd, "extractTrustedUserCommand(%q) cmd mismatch", tt.message)
+			gotCmd, gotOK := h.extractCommand(tt.message, tt.cfg)
+			assert.Equal(t, tt.wantOK, gotOK, "extractCommand(%q) ok mismatch", tt.message)
+			assert.Equal(t, tt.wantCmd, gotCmd, "extractCommand(%q) cmd mismatch", tt.message)
 		})
 	}
 }
@@ -363,3 +376,370 @@ func TestDetectInsufficientBombs(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleTrustedBotMessageTagsWallet(t *testing.T) {
+	t.Parallel()
+
+	h := newTestMessageHandler()
+	h.bot.wallet.SetBalance(1000)
+	var entries []wallet.Entry
+	h.bot.wallet.SetRecorder(func(e wallet.Entry) {
+		entries = append(entries, e)
+	})
+
+	cfg := testConfig("testuser", "!")
+	cfg.SlotsCost = 2000
+	cfg.PointsAsDelta = true
+
+	messages := []string{
+		"testuser bombs: 5000",
+		"testuser pulls the lever and waits for the roll... testuser hit the jackpot!",
+		"Results from the Heist: testuser (3 000), otheruser (100)",
+		"The dust finally settled, testuser (700)",
+	}
+	for _, text := range messages {
+		h.handleTrustedBotMessage(ports.ChatMessage{UserName: "bossbot", Text: text}, cfg)
+	}
+
+	require.Len(t, entries, 4, "every mutation should be recorded")
+	assert.Equal(t, wallet.GameSync, entries[0].Game, "bombs reply")
+	assert.Equal(t, wallet.GameSlots, entries[1].Game, "slots reply")
+	assert.Equal(t, 15000, entries[1].Amount, "slots payout")
+	assert.Equal(t, wallet.GameHeist, entries[2].Game, "heist result")
+	assert.Equal(t, 3000, entries[2].Amount, "heist payout")
+	assert.Equal(t, wallet.GameFFA, entries[3].Game, "arena result")
+	assert.Equal(t, messages[3], entries[3].Message, "raw message kept")
+	assert.Equal(t, 5000+15000+3000+700, h.bot.wallet.GetBalance(), "final balance")
+}
+
+func TestBossBotRepliesResolvePendingBets(t *testing.T) {
+	t.Parallel()
+
+	cfg := testConfig("testuser", "!")
+	cfg.SlotsCost = 2000
+
+	tests := []struct {
+		name        string
+		game        wallet.Game
+		reply       string
+		wantBalance int
+		wantPending int
+	}{
+		{"insufficient bombs refunds", wallet.GameSlots, "testuser doesn't have enough bombs", 10000, 0},
+		{"cooldown refunds", wallet.GameHeist, "testuser, the heist is on cooldown", 10000, 0},
+		{"slots reply confirms", wallet.GameSlots, "testuser pulls the lever and waits for the roll... testuser you lost", 8000, 0},
+		{"heist without us confirms", wallet.GameHeist, "Results from the Heist: otheruser (500)", 8000, 0},
+		{"arena with payout confirms", wallet.GameFFA, "The dust finally settled, testuser (1500)", 9500, 0},
+		{"unrelated message keeps bet", wallet.GameHeist, "hello chat", 8000, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := newTestMessageHandler()
+			h.bot.wallet.SetBalance(10000)
+			_, ok := h.bot.escrow.Reserve(tt.game, 2000, "!"+string(tt.game), time.Minute)
+			require.True(t, ok, "Reserve()")
+
+			h.handleTrustedBotMessage(ports.ChatMessage{UserName: "bossbot", Text: tt.reply}, cfg)
+
+			assert.Equal(t, tt.wantBalance, h.bot.wallet.GetBalance(), "balance")
+			assert.Len(t, h.bot.escrow.Pending(), tt.wantPending, "pending bets")
+		})
+	}
+}
+
+func TestRejectionRefundsTheGameItNames(t *testing.T) {
+	t.Parallel()
+
+	h := newTestMessageHandler()
+	h.bot.wallet.SetBalance(10000)
+	h.bot.syncRequests = make(chan string, 1)
+	cfg := testConfig("testuser", "!")
+
+	_, _ = h.bot.escrow.Reserve(wallet.GameHeist, 3000, "!heist 3000", time.Minute)
+	_, _ = h.bot.escrow.Reserve(wallet.GameSlots, 2000, "!slots", time.Minute)
+
+	h.handleTrustedBotMessage(ports.ChatMessage{UserName: "bossbot", Text: "testuser, the heist is on cooldown"}, cfg)
+	assert.Equal(t, 8000, h.bot.wallet.GetBalance(), "heist refunded, not the newer slots bet")
+	require.Len(t, h.bot.escrow.Pending(), 1, "slots bet still pending")
+
+	_, _ = h.bot.escrow.Reserve(wallet.GameFFA, 1000, "!ffa", time.Minute)
+	h.handleTrustedBotMessage(ports.ChatMessage{UserName: "bossbot", Text: "testuser, wait for the cooldown"}, cfg)
+	assert.Equal(t, 7000, h.bot.wallet.GetBalance(), "ambiguous rejection refunds nothing")
+	assert.Len(t, h.bot.escrow.Pending(), 2, "both bets still pending")
+	assert.Len(t, h.bot.syncRequests, 1, "balance sync requested instead")
+}
+
+func TestLateGameResultTakesRefundBack(t *testing.T) {
+	t.Parallel()
+
+	h := newTestMessageHandler()
+	h.bot.wallet.SetBalance(10000)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	h.bot.escrow.SetClock(func() time.Time { return now })
+
+	_, _ = h.bot.escrow.Reserve(wallet.GameHeist, 1000, "!heist 1000", time.Minute)
+	now = now.Add(2 * time.Minute)
+	h.bot.escrow.Expire()
+	require.Equal(t, 10000, h.bot.wallet.GetBalance(), "expired heist refunded")
+
+	h.handleTrustedBotMessage(ports.ChatMessage{UserName: "bossbot", Text: "Results from the Heist: testuser (2500)"}, testConfig("testuser", "!"))
+
+	assert.Equal(t, 11500, h.bot.wallet.GetBalance(), "payout credited once, refund taken back")
+}
+
+func TestBalanceReplyRecordsDrift(t *testing.T) {
+	t.Parallel()
+
+	h := newTestMessageHandler()
+	h.bot.wallet.SetBalance(5000)
+	cfg := testConfig("testuser", "!")
+
+	h.handleTrustedBotMessage(ports.ChatMessage{UserName: "bossbot", Text: "testuser bombs: 4200"}, cfg)
+
+	assert.Equal(t, 4200, h.bot.wallet.GetBalance(), "balance corrected")
+	assert.Equal(t, -800, h.bot.lastDrift, "drift recorded")
+	assert.Equal(t, 1, h.bot.driftCorrections, "drift correction counted")
+	assert.False(t, h.bot.lastSync.IsZero(), "sync time recorded")
+
+	h.handleTrustedBotMessage(ports.ChatMessage{UserName: "bossbot", Text: "testuser bombs: 4200"}, cfg)
+	assert.Equal(t, 0, h.bot.lastDrift, "no drift on matching balance")
+	assert.Equal(t, 1, h.bot.driftCorrections, "matching balance is not a correction")
+}
+
+func TestBossBotRepliesFeedCounters(t *testing.T) {
+	t.Parallel()
+
+	h := newTestMessageHandler()
+	h.bot.wallet.SetBalance(10000)
+	cfg := testConfig("testuser", "!")
+	cfg.SlotsCost = 2000
+
+	_, _ = h.bot.escrow.Reserve(wallet.GameSlots, 2000, "!slots", time.Minute)
+	h.handleTrustedBotMessage(ports.ChatMessage{UserName: "bossbot", Text: "testuser pulls the lever and waits for the roll... testuser you lost"}, cfg)
+	_, _ = h.bot.escrow.Reserve(wallet.GameHeist, 1000, "!heist 1000", time.Minute)
+	h.handleTrustedBotMessage(ports.ChatMessage{UserName: "bossbot", Text: "Results from the Heist: testuser (2500)"}, cfg)
+	h.handleTrustedBotMessage(ports.ChatMessage{UserName: "bossbot", Text: "testuser bombs: lots"}, cfg)
+
+	counters := h.bot.countersSnapshot()
+	assert.Equal(t, 1, counters.SlotsOutcomes["lost"], "slots outcome")
+	assert.Equal(t, 0, counters.SlotsOutcomes["jackpot"], "outcomes are listed before they happen")
+	assert.Equal(t, 2000, counters.Spent["slots"], "slots stake")
+	assert.Equal(t, 1000, counters.Spent["heist"], "heist stake")
+	assert.Equal(t, 2500, counters.Won["heist"], "heist payout")
+	assert.Equal(t, 1, counters.ParseFailures["balance"], "unparsed balance reply")
+}
+
+func TestBalanceReplyCoversPendingBets(t *testing.T) {
+	t.Parallel()
+
+	h := newTestMessageHandler()
+	h.bot.wallet.SetBalance(10000)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	h.bot.escrow.SetClock(func() time.Time { return now })
+	_, _ = h.bot.escrow.Reserve(wallet.GameHeist, 2000, "!heist 2000", time.Minute)
+
+	h.handleTrustedBotMessage(ports.ChatMessage{UserName: "bossbot", Text: "testuser bombs: 8000"}, testConfig("testuser", "!"))
+	assert.Zero(t, h.bot.lastDrift, "pending stake is not drift")
+
+	now = now.Add(2 * time.Minute)
+	h.bot.escrow.Expire()
+	assert.Equal(t, 8000, h.bot.wallet.GetBalance(), "expiry does not refund on top of the synced balance")
+}
+
+func TestBalanceSyncRequestsAreDeferred(t *testing.T) {
+	t.Parallel()
+
+	h := newTestMessageHandler()
+
+	assert.Zero(t, h.bot.balanceSyncWait(), "first request is sent")
+	wait := h.bot.balanceSyncWait()
+	assert.Greater(t, wait, MinBalanceSyncGap-time.Second, "next request waits for the gap")
+	assert.LessOrEqual(t, wait, MinBalanceSyncGap, "wait is at most the gap")
+}
+
+func TestInsufficientBombsRequestsSync(t *testing.T) {
+	t.Parallel()
+
+	h := newTestMessageHandler()
+	h.bot.syncRequests = make(chan string, 1)
+
+	h.handleTrustedBotMessage(ports.ChatMessage{UserName: "bossbot", Text: "testuser doesn't have enough bombs"}, testConfig("testuser", "!"))
+
+	select {
+	case reason := <-h.bot.syncRequests:
+		assert.Equal(t, "insufficient bombs", reason, "sync reason")
+	default:
+		t.Fatal("expected a balance sync request")
+	}
+}
+
+func TestSettledGamesFeedStrategyHistory(t *testing.T) {
+	t.Parallel()
+
+	h := newTestMessageHandler()
+	h.bot.history = gambling.NewHistory(10)
+	h.bot.wallet.SetBalance(10000)
+	cfg := testConfig("testuser", "!")
+	cfg.SlotsCost = 2000
+
+	_, _ = h.bot.escrow.Reserve(wallet.GameHeist, 1000, "!heist 1000", time.Minute)
+	h.handleTrustedBotMessage(ports.ChatMessage{UserName: "bossbot", Text: "Results from the Heist: testuser (2500)"}, cfg)
+	_, _ = h.bot.escrow.Reserve(wallet.GameSlots, 2000, "!slots", time.Minute)
+	h.handleTrustedBotMessage(ports.ChatMessage{UserName: "bossbot", Text: "testuser pulls the lever and waits for the roll... testuser you lost"}, cfg)
+
+	results := h.bot.history.Results()
+	require.Len(t, results, 2, "both settled games recorded")
+	assert.Equal(t, gambling.Result{Game: "heist", Stake: 1000, Return: 2500}, results[0], "heist result")
+	assert.Equal(t, gambling.Result{Game: "slots", Stake: 2000, Return: 0}, results[1], "slots result")
+}
+
+func TestHeistResultsFeedGameLog(t *testing.T) {
+	t.Parallel()
+
+	h := newTestMessageHandler()
+	h.bot.games = gambling.NewGameLog(10)
+	h.bot.wallet.SetBalance(10000)
+	cfg := testConfig("testuser", "!")
+
+	_, _ = h.bot.escrow.Reserve(wallet.GameHeist, 1000, "!heist 1000", time.Minute)
+	h.handleTrustedBotMessage(ports.ChatMessage{UserName: "bossbot", Text: "Results from the Heist: otheruser (1 500), testuser (2 500)"}, cfg)
+	_, _ = h.bot.escrow.Reserve(wallet.GameHeist, 1000, "!heist 1000", time.Minute)
+	h.handleTrustedBotMessage(ports.ChatMessage{UserName: "bossbot", Text: "Results from the Heist: otheruser (900)"}, cfg)
+	h.handleTrustedBotMessage(ports.ChatMessage{UserName: "bossbot", Text: "Results from the Heist: otheruser (400), third (600)"}, cfg)
+
+	records := h.bot.games.Records()
+	require.Len(t, records, 3, "every heist recorded")
+
+	assert.True(t, records[0].Survived, "survived heist")
+	assert.Equal(t, 2, records[0].Crew, "survived crew")
+	assert.Equal(t, 4000, records[0].Total, "survived total payout")
+	assert.Equal(t, 2500, records[0].Return, "survived return")
+
+	assert.True(t, records[1].Joined, "caught heist joined")
+	assert.False(t, records[1].Survived, "caught heist")
+	assert.Equal(t, 2, records[1].Crew, "caught crew includes us")
+	assert.Equal(t, 1000, records[1].Stake, "caught stake")
+	assert.Zero(t, records[1].Return, "caught return")
+
+	assert.False(t, records[2].Joined, "heist without us")
+	assert.Equal(t, 1000, records[2].Total, "watched total payout")
+
+	summary := h.bot.GameSummary(wallet.GameHeist)
+	assert.Equal(t, 3, summary.Seen, "heists seen")
+	assert.Equal(t, 2, summary.Joined, "heists joined")
+	assert.InDelta(t, 0.5, summary.WinRate(), 1e-9, "win rate")
+	assert.Equal(t, 10000-2000+2500, h.bot.wallet.GetBalance(), "balance")
+}
+
+func TestUnreadablePayoutIsRecordedUnresolved(t *testing.T) {
+	t.Parallel()
+
+	h := newTestMessageHandler()
+	h.bot.games = gambling.NewGameLog(10)
+	h.bot.wallet.SetBalance(10000)
+	h.bot.syncRequests = make(chan string, 1)
+
+	_, _ = h.bot.escrow.Reserve(wallet.GameHeist, 1000, "!heist 1000", time.Minute)
+	h.handleTrustedBotMessage(ports.ChatMessage{UserName: "bossbot", Text: "Results from the Heist: otheruser (900), TestUser (a lot)"}, testConfig("testuser", "!"))
+
+	records := h.bot.games.Records()
+	require.Len(t, records, 1, "heist recorded")
+	assert.True(t, records[0].Unresolved, "our payout could not be read")
+	assert.Equal(t, 1000, records[0].Stake, "stake kept")
+	assert.Equal(t, 1000, h.bot.GameSummary(wallet.GameHeist).Staked, "stake in the summary")
+	assert.Equal(t, 1000, h.bot.countersSnapshot().Spent["heist"], "stake in the counters")
+	assert.Equal(t, 1, h.bot.countersSnapshot().ParseFailures["heist"], "parse failure counted")
+	assert.Len(t, h.bot.syncRequests, 1, "balance sync requested")
+}
+
+func TestArenaAndBossResultsFeedGameLog(t *testing.T) {
+	t.Parallel()
+
+	h := newTestMessageHandler()
+	h.bot.games = gambling.NewGameLog(10)
+	h.bot.wallet.SetBalance(10000)
+	cfg := testConfig("testuser", "!")
+	h.bot.config = config.NewStaticStore(cfg)
+	h.bot.dialect = parsing.DefaultDialect()
+	h.bot.dialect.BossResult = []string{"The boss has been defeated", "The boss fight is over"}
+	require.NoError(t, h.bot.dialect.Compile(), "Compile()")
+
+	_, _ = h.bot.escrow.Reserve(wallet.GameFFA, 1000, "!ffa", time.Minute)
+	h.handleTrustedBotMessage(ports.ChatMessage{UserName: "bossbot", Text: "The dust finally settled, winners: otheruser (900), testuser"}, cfg)
+	h.handleTrustedBotMessage(ports.ChatMessage{UserName: "bossbot", Text: "(4 500)"}, cfg)
+	_, _ = h.bot.escrow.Reserve(wallet.GameBoss, 0, "!boss", time.Minute)
+	h.handleTrustedBotMessage(ports.ChatMessage{UserName: "bossbot", Text: "The boss has been defeated! Rewards: testuser (2 000), otheruser (2 000)"}, cfg)
+	_, _ = h.bot.escrow.Reserve(wallet.GameBoss, 0, "!boss", time.Minute)
+	h.handleTrustedBotMessage(ports.ChatMessage{UserName: "bossbot", Text: "The boss fight is over, the boss wiped out everyone"}, cfg)
+
+	records := h.bot.games.Records()
+	require.Len(t, records, 3, "every result recorded")
+
+	assert.Equal(t, "ffa", records[0].Game, "arena game")
+	assert.Equal(t, 2, records[0].Placement, "arena placement")
+	assert.Equal(t, 4500, records[0].Return, "arena payout from split message")
+
+	assert.Equal(t, "boss", records[1].Game, "boss game")
+	assert.True(t, records[1].Survived, "boss defeated")
+	assert.Equal(t, 2000, records[1].Return, "boss payout")
+
+	assert.True(t, records[2].Joined, "lost boss fight joined")
+	assert.False(t, records[2].Survived, "lost boss fight")
+
+	boss := h.bot.GameSummary(wallet.GameBoss)
+	assert.Equal(t, 2, boss.Joined, "boss fights joined")
+	assert.Equal(t, 2000, boss.Profit(), "boss profit")
+	assert.Equal(t, 10000-1000+4500+2000, h.bot.wallet.GetBalance(), "wallet reconciled with payouts")
+	assert.Empty(t, h.bot.escrow.Pending(), "every bet settled")
+}
+
+func TestCompleteResultEndingInWordIsNotBuffered(t *testing.T) {
+	t.Parallel()
+
+	h := newTestMessageHandler()
+	h.bot.games = gambling.NewGameLog(10)
+	h.bot.wallet.SetBalance(10000)
+	cfg := testConfig("testuser", "!")
+	cfg.BossBotName = "bossbot"
+	h.bot.config = config.NewStaticStore(cfg)
+	h.bot.bossBotIDs = map[string]string{"bossbot": "9"}
+
+	_, _ = h.bot.escrow.Reserve(wallet.GameHeist, 1000, "!heist 1000", time.Minute)
+	h.HandleMessage(ports.ChatMessage{UserName: "bossbot", UserID: "9", Text: "Results from the Heist: otheruser (900), testuser (2 500) gg"})
+
+	assert.Zero(t, h.reassembler.Pending(), "nothing buffered")
+	require.Len(t, h.bot.games.Records(), 1, "heist settled right away")
+	assert.Equal(t, 10000-1000+2500, h.bot.wallet.GetBalance(), "balance")
+}
+
+func TestSplitHeistResultWithInterleavedChat(t *testing.T) {
+	t.Parallel()
+
+	h := newTestMessageHandler()
+	h.bot.games = gambling.NewGameLog(10)
+	h.bot.wallet.SetBalance(10000)
+	cfg := testConfig("testuser", "!")
+	cfg.BossBotName = "bossbot"
+	h.bot.config = config.NewStaticStore(cfg)
+	h.bot.bossBotIDs = map[string]string{"bossbot": "9"}
+
+	_, _ = h.bot.escrow.Reserve(wallet.GameHeist, 1000, "!heist 1000", time.Minute)
+	for _, msg := range []ports.ChatMessage{
+		{UserName: "bossbot", UserID: "9", Text: "Results from the Heist: a (100), b"},
+		{UserName: "viewer", UserID: "3", Text: "(gg)"},
+		{UserName: "bossbot", UserID: "9", Text: "(200), testuser"},
+		{UserName: "bossbot", UserID: "9", Text: "otheruser bombs: 300"},
+		{UserName: "bossbot", UserID: "9", Text: "(2 500), c (400)"},
+	} {
+		h.HandleMessage(msg)
+	}
+
+	records := h.bot.games.Records()
+	require.Len(t, records, 1, "one heist recorded")
+	assert.Equal(t, 4, records[0].Crew, "every participant of the three parts")
+	assert.Equal(t, 2500, records[0].Return, "our payout from the last part")
+	assert.Equal(t, 10000-1000+2500, h.bot.wallet.GetBalance(), "balance")
+}
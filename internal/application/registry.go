@@ -0,0 +1,184 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"streamgogambler/internal/domain/i18n"
+	"streamgogambler/internal/domain/permissions"
+)
+
+const (
+	// DefaultCommandCooldown is how long a user waits between two runs of a
+	// command that does not set its own cooldown.
+	DefaultCommandCooldown = 2 * time.Second
+
+	// MaxChatMessageLen is the longest message Twitch accepts; longer
+	// generated replies such as !help are split.
+	MaxChatMessageLen = 500
+)
+
+var ErrCommandExists = errors.New("command name already registered")
+
+// Arg describes a command argument for usage messages and validation.
+type Arg struct {
+	// Name is shown in usage messages, translated by the "arg.<name>"
+	// message when the catalog has one.
+	Name     string
+	Optional bool
+	// Choices lists the accepted values, matched case-insensitively; empty
+	// accepts any value.
+	Choices []string
+}
+
+// CommandCall is one run of a command.
+type CommandCall struct {
+	User    string
+	Channel string
+	Args    []string
+	Roles   permissions.Roles
+	Command *Command
+
+	// text is the message after the command name, as typed.
+	text string
+}
+
+// ArgsFrom returns the arguments from the i-th on as typed, keeping the
+// spacing that Args loses, e.g. for free-text values.
+func (c CommandCall) ArgsFrom(i int) string {
+	return skipFields(c.text, i)
+}
+
+// skipFields drops the first n whitespace-separated fields of s and trims
+// the rest.
+func skipFields(s string, n int) string {
+	for range n {
+		s = strings.TrimLeftFunc(s, unicode.IsSpace)
+		end := strings.IndexFunc(s, unicode.IsSpace)
+		if end < 0 {
+			return ""
+		}
+		s = s[end:]
+	}
+	return strings.TrimSpace(s)
+}
+
+type CommandFunc func(c CommandCall)
+
+// Command is a chat command. Roles may run it unless COMMAND_PERMISSIONS says
+// otherwise; a command without roles is left to the owner. Arguments are
+// checked against Args before Run is called, and each user waits Cooldown
+// (DefaultCommandCooldown when zero) between runs. Help is the message ID of
+// the command's description.
+type Command struct {
+	Name     string
+	Aliases  []string
+	Roles    permissions.Roles
+	Args     []Arg
+	Cooldown time.Duration
+	Help     string
+	Run      CommandFunc
+}
+
+// Names returns the command's name followed by its aliases.
+func (c *Command) Names() []string {
+	return append([]string{c.Name}, c.Aliases...)
+}
+
+// Usage returns the command's syntax, e.g. "!slotsoff <czas/duration>".
+func (c *Command) Usage(prefix string, messages *i18n.Catalog) string {
+	parts := []string{prefix + c.Name}
+	for _, a := range c.Args {
+		name := messages.Text("arg."+a.Name, a.Name)
+		if a.Optional {
+			parts = append(parts, "["+name+"]")
+		} else {
+			parts = append(parts, "<"+name+">")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func (c *Command) cooldown() time.Duration {
+	if c.Cooldown > 0 {
+		return c.Cooldown
+	}
+	return DefaultCommandCooldown
+}
+
+// acceptsArgs reports whether args match the command's argument schema.
+// Extra arguments are ignored.
+func (c *Command) acceptsArgs(args []string) bool {
+	for i, a := range c.Args {
+		if i >= len(args) {
+			return a.Optional
+		}
+		if len(a.Choices) > 0 && !slices.ContainsFunc(a.Choices, func(choice string) bool {
+			return strings.EqualFold(choice, args[i])
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// Register adds a command under its name and aliases.
+func (h *CommandHandler) Register(c Command) error {
+	c.Name = strings.ToLower(c.Name)
+	for i, alias := range c.Aliases {
+		c.Aliases[i] = strings.ToLower(alias)
+	}
+	for _, name := range c.Names() {
+		if _, exists := h.byName[name]; exists {
+			return fmt.Errorf("%w: %s", ErrCommandExists, name)
+		}
+	}
+
+	cmd := &c
+	h.commands = append(h.commands, cmd)
+	for _, name := range cmd.Names() {
+		h.byName[name] = cmd
+	}
+	return nil
+}
+
+// Lookup returns the command registered under a name or alias.
+func (h *CommandHandler) Lookup(name string) (*Command, bool) {
+	c, ok := h.byName[strings.ToLower(name)]
+	return c, ok
+}
+
+// Commands returns the registered commands in registration order.
+func (h *CommandHandler) Commands() []*Command {
+	return slices.Clone(h.commands)
+}
+
+func (h *CommandHandler) replyUsage(c CommandCall) {
+	h.reply(c, "usage", i18n.Vars{"usage": c.Command.Usage(h.config.GetConfig().Prefix, h.bot.Messages())})
+}
+
+// splitMessages joins parts with sep into as few messages as possible, each
+// at most MaxChatMessageLen long.
+func splitMessages(parts []string, sep string) []string {
+	var messages []string
+	current := ""
+	for _, part := range parts {
+		switch {
+		case current == "":
+			current = part
+		case len(current)+len(sep)+len(part) > MaxChatMessageLen:
+			messages = append(messages, current)
+			current = part
+		default:
+			current += sep + part
+		}
+	}
+	if current != "" {
+		messages = append(messages, current)
+	}
+	return messages
+}
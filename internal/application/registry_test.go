@@ -0,0 +1,84 @@
+package application
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"streamgogambler/internal/domain/i18n"
+)
+
+func TestCommandUsage(t *testing.T) {
+	t.Parallel()
+
+	c := Command{Name: "slotsoff", Args: []Arg{{Name: "time/duration"}, {Name: "reason", Optional: true}}}
+	assert.Equal(t, "!slotsoff <time/duration> [reason]", c.Usage("!", nil), "names as declared without a catalog")
+	assert.Equal(t, "!slotsoff <czas/duration> [reason]", c.Usage("!", i18n.Default()), "names translated by the catalog")
+	assert.Equal(t, "?help", (&Command{Name: "help"}).Usage("?", nil))
+}
+
+func TestCommandAcceptsArgs(t *testing.T) {
+	t.Parallel()
+
+	c := Command{Name: "autoslots", Args: []Arg{{Name: "on/off", Choices: []string{"on", "off"}}, {Name: "x", Optional: true}}}
+
+	tests := []struct {
+		name string
+		args []string
+		want bool
+	}{
+		{"missing required", nil, false},
+		{"valid choice", []string{"on"}, true},
+		{"choice ignores case", []string{"OFF"}, true},
+		{"invalid choice", []string{"maybe"}, false},
+		{"optional given", []string{"on", "later"}, true},
+		{"extra args ignored", []string{"on", "a", "b"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, c.acceptsArgs(tt.args), "acceptsArgs(%v)", tt.args)
+		})
+	}
+}
+
+func TestCommandCooldownDefault(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, DefaultCommandCooldown, (&Command{}).cooldown())
+	assert.Equal(t, time.Minute, (&Command{Cooldown: time.Minute}).cooldown())
+}
+
+func TestRegisterRejectsTakenNames(t *testing.T) {
+	t.Parallel()
+
+	h := &CommandHandler{byName: make(map[string]*Command)}
+	require.NoError(t, h.Register(Command{Name: "Trust", Aliases: []string{"Zaufaj"}}))
+
+	c, ok := h.Lookup("ZAUFAJ")
+	require.True(t, ok, "alias registered case-insensitively")
+	assert.Equal(t, "trust", c.Name)
+
+	require.ErrorIs(t, h.Register(Command{Name: "zaufaj"}), ErrCommandExists, "name taken by an alias")
+	require.ErrorIs(t, h.Register(Command{Name: "other", Aliases: []string{"trust"}}), ErrCommandExists, "alias taken by a name")
+	assert.Len(t, h.Commands(), 1, "rejected commands are not added")
+}
+
+func TestSplitMessages(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"a | b"}, splitMessages([]string{"a", "b"}, " | "))
+	assert.Empty(t, splitMessages(nil, " | "))
+
+	long := strings.Repeat("x", 300)
+	messages := splitMessages([]string{long, long, "y"}, " | ")
+	assert.Equal(t, []string{long, long + " | y"}, messages)
+	for _, m := range messages {
+		assert.LessOrEqual(t, len(m), MaxChatMessageLen)
+	}
+}
@@ -0,0 +1,107 @@
+package application
+
+import (
+	"time"
+
+	"streamgogambler/internal/domain/gambling"
+	"streamgogambler/internal/domain/permissions"
+	"streamgogambler/internal/ports"
+)
+
+// AutoResponsesReloadInterval is how often the auto-responses file is checked
+// for changes made by hand.
+const AutoResponsesReloadInterval = 5 * time.Second
+
+// onConfigChange applies a reloaded configuration. Costs, greetings and the
+// other settings read from the store when used take effect by themselves;
+// the state built from the configuration on startup is updated here.
+func (s *BotService) onConfigChange(old, updated ports.BotConfig) {
+	if updated.BettingStrategy != old.BettingStrategy {
+		strategy, err := gambling.ParseStrategy(updated.BettingStrategy)
+		if err != nil {
+			s.logger.Warnf(s.ctx, "Invalid betting strategy: %v, keeping %s", err, s.Strategy().Name())
+		} else {
+			s.SetStrategy(strategy)
+			s.logger.Infof(s.ctx, "Betting strategy changed to %s", strategy.Name())
+		}
+	}
+
+	var policy *permissions.Policy
+	if updated.CommandPermissions != old.CommandPermissions {
+		p, err := permissions.ParsePolicy(updated.CommandPermissions)
+		if err != nil {
+			s.logger.Warnf(s.ctx, "Invalid command permissions: %v, keeping the previous ones", err)
+		} else {
+			policy = &p
+		}
+	}
+
+	interval := time.Duration(updated.AutoSlotsInterval) * time.Minute
+	guardrails := gambling.Guardrails{
+		Reserve:    updated.ReserveFloor,
+		StopLoss:   updated.StopLoss,
+		TakeProfit: updated.TakeProfit,
+	}
+
+	s.mu.Lock()
+	if policy != nil {
+		s.policy = policy
+	}
+	// New limits are checked against the running session, so a tripped
+	// guardrail is checked again.
+	if guardrails != s.guardrails {
+		s.guardrails = guardrails
+		s.guardrailState = gambling.GuardrailOK
+	}
+	if updated.AutoSlotsEnabled != old.AutoSlotsEnabled {
+		s.autoSlotsEnabled = updated.AutoSlotsEnabled
+	}
+	rescheduled := interval != s.slotsInterval
+	s.slotsInterval = interval
+	s.mu.Unlock()
+
+	if rescheduled {
+		select {
+		case s.slotsReschedule <- struct{}{}:
+		default:
+		}
+	}
+	if updated.BalanceSyncInterval != old.BalanceSyncInterval {
+		select {
+		case s.syncReschedule <- struct{}{}:
+		default:
+		}
+	}
+	s.logger.Infof(s.ctx, "Configuration of #%s updated", updated.Channel)
+}
+
+// runAutoResponsesReload picks up changes made to the auto-responses file by
+// hand.
+func (s *BotService) runAutoResponsesReload() {
+	if s.autoResponseStore == nil {
+		return
+	}
+	ticker := time.NewTicker(AutoResponsesReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.reloadAutoResponses()
+		}
+	}
+}
+
+func (s *BotService) reloadAutoResponses() {
+	rules, changed, err := s.autoResponseStore.Reload()
+	if err != nil {
+		s.logger.Warnf(s.ctx, "Auto-responses not reloaded, keeping the previous ones: %v", err)
+		return
+	}
+	if changed {
+		s.AutoResponses().Replace(rules)
+		s.logger.Infof(s.ctx, "Auto-responses reloaded (%d rules)", len(rules))
+	}
+}
@@ -0,0 +1,53 @@
+package application
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"streamgogambler/internal/adapters/config"
+	"streamgogambler/internal/domain/gambling"
+	"streamgogambler/internal/ports"
+)
+
+func TestConfigChangesApplyToRunningBot(t *testing.T) {
+	t.Parallel()
+
+	cfg := ports.BotConfig{Username: "testuser", Channel: "foo", AutoSlotsInterval: 15, BettingStrategy: "fixed", StopLoss: 100}
+	store := config.NewStaticStore(cfg)
+	bot := newChannelBot("foo", 1000)
+	bot.config = store
+	bot.slotsInterval = 15 * time.Minute
+	bot.slotsReschedule = make(chan struct{}, 1)
+	bot.syncReschedule = make(chan struct{}, 1)
+	bot.guardrails = gambling.Guardrails{StopLoss: 100}
+	bot.guardrailState = gambling.GuardrailStopLoss
+	unsubscribe := store.Subscribe(bot.onConfigChange)
+	defer unsubscribe()
+
+	updated := cfg
+	updated.AutoSlotsInterval = 5
+	updated.AutoSlotsEnabled = true
+	updated.BettingStrategy = "percent:5"
+	updated.CommandPermissions = "autoslots=moderator"
+	updated.StopLoss = 500
+	updated.SlotsCost = 3000
+	updated.BalanceSyncInterval = 10
+	store.Update(updated)
+
+	assert.Equal(t, 5*time.Minute, bot.SlotsInterval(), "slots interval")
+	assert.Len(t, bot.slotsReschedule, 1, "slots loop rescheduled")
+	assert.Len(t, bot.syncReschedule, 1, "balance sync loop rescheduled")
+	assert.True(t, bot.IsAutoSlotsEnabled(), "auto slots enabled")
+	assert.Equal(t, "percent:5", bot.Strategy().Name(), "strategy")
+	assert.Contains(t, bot.Permissions().Commands, "autoslots", "permissions")
+	assert.Equal(t, 500, bot.guardrails.StopLoss, "guardrails")
+	assert.Equal(t, gambling.GuardrailOK, bot.GuardrailState(), "tripped guardrail checked again")
+	assert.Equal(t, 3000, bot.config.GetConfig().SlotsCost, "costs read from the store")
+
+	invalid := updated
+	invalid.BettingStrategy = "double"
+	store.Update(invalid)
+	assert.Equal(t, "percent:5", bot.Strategy().Name(), "invalid strategy ignored")
+}
@@ -0,0 +1,227 @@
+package application
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	"streamgogambler/internal/domain/gambling"
+	"streamgogambler/internal/ports"
+)
+
+// Supervisor runs one BotService per joined channel and aggregates their
+// stats. Controls like auto slots and commands act on the selected channel.
+type Supervisor struct {
+	bots  []*BotService
+	token func() ports.TokenStatus
+	say   func() ports.SayStats
+
+	mu       sync.Mutex
+	selected int
+}
+
+func NewSupervisor(bots ...*BotService) *Supervisor {
+	return &Supervisor{bots: bots}
+}
+
+// Start starts every bot and blocks until all of them stop, returning the
+// first error.
+func (s *Supervisor) Start(ctx context.Context) error {
+	s.Attach(ctx)
+	return s.Run()
+}
+
+// Attach attaches every bot, so Stop is safe to call once it returns.
+func (s *Supervisor) Attach(ctx context.Context) {
+	for _, bot := range s.bots {
+		bot.Attach(ctx)
+	}
+}
+
+// Run runs the attached bots until all of them stop, returning the first
+// error.
+func (s *Supervisor) Run() error {
+	errs := make(chan error, len(s.bots))
+	for _, bot := range s.bots {
+		go func() {
+			errs <- bot.Run()
+		}()
+	}
+
+	var first error
+	for range s.bots {
+		if err := <-errs; err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+func (s *Supervisor) Stop() {
+	for _, bot := range s.bots {
+		bot.Stop()
+	}
+}
+
+// ReportToken adds the status of the account's OAuth token, shared by every
+// channel, to the stats.
+func (s *Supervisor) ReportToken(status func() ports.TokenStatus) {
+	s.token = status
+}
+
+// ReportSay adds the rate limiting stats of the account's chat connection,
+// shared by every channel, to the stats.
+func (s *Supervisor) ReportSay(stats func() ports.SayStats) {
+	s.say = stats
+}
+
+func (s *Supervisor) Bots() []*BotService {
+	return s.bots
+}
+
+func (s *Supervisor) Channels() []string {
+	channels := make([]string, 0, len(s.bots))
+	for _, bot := range s.bots {
+		channels = append(channels, bot.Config().GetConfig().Channel)
+	}
+	return channels
+}
+
+// SelectChannel makes channel the target of the control methods.
+func (s *Supervisor) SelectChannel(channel string) bool {
+	for i, bot := range s.bots {
+		if strings.EqualFold(bot.Config().GetConfig().Channel, channel) {
+			s.mu.Lock()
+			s.selected = i
+			s.mu.Unlock()
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Supervisor) SelectedChannel() string {
+	return s.current().Config().GetConfig().Channel
+}
+
+func (s *Supervisor) current() *BotService {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.bots[s.selected]
+}
+
+// GetStats returns the stats of the only bot, or totals over all channels
+// with the per-channel stats in Channels.
+func (s *Supervisor) GetStats() ports.BotStats {
+	var stats ports.BotStats
+	if len(s.bots) == 1 {
+		stats = s.bots[0].GetStats()
+	} else {
+		channels := make([]ports.BotStats, 0, len(s.bots))
+		for _, bot := range s.bots {
+			channels = append(channels, bot.GetStats())
+		}
+		stats = sumStats(channels)
+		stats.Channels = channels
+	}
+
+	if s.token != nil {
+		token := s.token()
+		stats.Token = &token
+	}
+	if s.say != nil {
+		say := s.say()
+		stats.Say = &say
+	}
+	return stats
+}
+
+// sumStats adds up the counters of several bots or accounts; fields that
+// only make sense per bot, like the drift or strategy, are left empty.
+func sumStats(parts []ports.BotStats) ports.BotStats {
+	first := parts[0]
+	total := ports.BotStats{
+		Status:        first.Status,
+		Uptime:        first.Uptime,
+		UptimeSeconds: first.UptimeSeconds,
+		Guardrail:     gambling.GuardrailOK,
+	}
+
+	var channels, usernames []string
+	for _, stats := range parts {
+		for _, channel := range strings.Split(stats.Channel, ",") {
+			channels = appendUnique(channels, channel)
+		}
+		usernames = appendUnique(usernames, stats.Username)
+		total.Balance += stats.Balance
+		total.MessagesSent += stats.MessagesSent
+		total.MessagesRecv += stats.MessagesRecv
+		total.ReconnectCount = max(total.ReconnectCount, stats.ReconnectCount)
+		total.DriftCorrections += stats.DriftCorrections
+		total.SessionProfit += stats.SessionProfit
+		if total.Guardrail == gambling.GuardrailOK {
+			total.Guardrail = stats.Guardrail
+		}
+		if stats.LastSync > total.LastSync {
+			total.LastSync = stats.LastSync
+		}
+		for game, g := range stats.Games {
+			if total.Games == nil {
+				total.Games = make(map[string]ports.GameStats)
+			}
+			total.Games[game] = addGameStats(total.Games[game], g)
+		}
+	}
+	total.Channel = strings.Join(channels, ",")
+	total.Username = strings.Join(usernames, ",")
+
+	return total
+}
+
+// addGameStats merges two game summaries, weighting the averages by the
+// number of games behind them.
+func addGameStats(a, b ports.GameStats) ports.GameStats {
+	sum := ports.GameStats{
+		Seen:     a.Seen + b.Seen,
+		Joined:   a.Joined + b.Joined,
+		Survived: a.Survived + b.Survived,
+		Staked:   a.Staked + b.Staked,
+		Returned: a.Returned + b.Returned,
+
+		Unresolved: a.Unresolved + b.Unresolved,
+	}
+	if sum.Joined > 0 {
+		sum.WinRate = float64(sum.Survived) / float64(sum.Joined)
+	}
+	if sum.Seen > 0 {
+		sum.AvgCrew = (a.AvgCrew*float64(a.Seen) + b.AvgCrew*float64(b.Seen)) / float64(sum.Seen)
+	}
+	if sum.Survived > 0 {
+		sum.AvgPlacement = (a.AvgPlacement*float64(a.Survived) + b.AvgPlacement*float64(b.Survived)) / float64(sum.Survived)
+	}
+	return sum
+}
+
+func appendUnique(list []string, value string) []string {
+	if value == "" || slices.Contains(list, value) {
+		return list
+	}
+	return append(list, value)
+}
+
+func (s *Supervisor) IsAutoSlotsEnabled() bool {
+	return s.current().IsAutoSlotsEnabled()
+}
+
+func (s *Supervisor) SetAutoSlots(enabled bool) {
+	s.current().SetAutoSlots(enabled)
+}
+
+func (s *Supervisor) ExecuteCommand(command string) {
+	s.current().ExecuteCommand(command)
+}
+
+func (s *Supervisor) ResetSession() {
+	s.current().ResetSession()
+}
@@ -0,0 +1,107 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"streamgogambler/internal/adapters/config"
+	"streamgogambler/internal/adapters/logging"
+	"streamgogambler/internal/domain/gambling"
+	"streamgogambler/internal/domain/wallet"
+	"streamgogambler/internal/ports"
+)
+
+func newChannelBot(channel string, balance int) *BotService {
+	w := wallet.New(balance)
+	return &BotService{
+		ctx:    context.Background(),
+		config: config.NewStaticStore(ports.BotConfig{Username: "testuser", Channel: channel}),
+		wallet: w,
+		escrow: wallet.NewEscrow(w),
+		logger: logging.New(logging.LevelError),
+	}
+}
+
+func TestSupervisorAggregatesChannelStats(t *testing.T) {
+	t.Parallel()
+
+	foo := newChannelBot("foo", 10000)
+	bar := newChannelBot("bar", 5000)
+	foo.messagesSent, bar.messagesSent = 3, 4
+	bar.SyncBalance(5000, "testuser bombs: 5000")
+	bar.wallet.AddBalance(-1000)
+
+	stats := NewSupervisor(foo, bar).GetStats()
+
+	assert.Equal(t, "foo,bar", stats.Channel)
+	assert.Equal(t, 14000, stats.Balance, "total balance")
+	assert.Equal(t, 7, stats.MessagesSent, "total messages sent")
+	assert.Equal(t, -1000, stats.SessionProfit, "total session profit")
+	assert.Equal(t, gambling.GuardrailOK, stats.Guardrail)
+	require.Len(t, stats.Channels, 2)
+	assert.Equal(t, "foo", stats.Channels[0].Channel)
+	assert.Equal(t, 10000, stats.Channels[0].Balance)
+	assert.Equal(t, "bar", stats.Channels[1].Channel)
+	assert.Equal(t, 4000, stats.Channels[1].Balance)
+	assert.NotEmpty(t, stats.Channels[1].LastSync)
+}
+
+func TestSupervisorAggregatesGameStats(t *testing.T) {
+	t.Parallel()
+
+	foo := newChannelBot("foo", 0)
+	bar := newChannelBot("bar", 0)
+	foo.games, bar.games = gambling.NewGameLog(10), gambling.NewGameLog(10)
+	foo.RecordGame(gambling.GameRecord{Game: "heist", Crew: 2, Joined: true, Survived: true, Stake: 1000, Return: 3000})
+	bar.RecordGame(gambling.GameRecord{Game: "heist", Crew: 4, Joined: true, Stake: 1000})
+	bar.RecordGame(gambling.GameRecord{Game: "heist", Crew: 6})
+
+	stats := NewSupervisor(foo, bar).GetStats()
+
+	heist := stats.Games["heist"]
+	assert.Equal(t, 3, heist.Seen, "heists seen")
+	assert.Equal(t, 2, heist.Joined, "heists joined")
+	assert.Equal(t, 2000, heist.Staked, "total staked")
+	assert.Equal(t, 3000, heist.Returned, "total returned")
+	assert.InDelta(t, 0.5, heist.WinRate, 1e-9, "win rate")
+	assert.InDelta(t, 4.0, heist.AvgCrew, 1e-9, "average crew")
+}
+
+func TestSupervisorSingleChannelStatsUnchanged(t *testing.T) {
+	t.Parallel()
+
+	foo := newChannelBot("foo", 10000)
+
+	stats := NewSupervisor(foo).GetStats()
+
+	assert.Equal(t, "foo", stats.Channel)
+	assert.Equal(t, 10000, stats.Balance)
+	assert.Empty(t, stats.Channels, "no per-channel breakdown for one channel")
+}
+
+func TestSupervisorControlsSelectedChannel(t *testing.T) {
+	t.Parallel()
+
+	foo := newChannelBot("foo", 10000)
+	bar := newChannelBot("bar", 5000)
+	s := NewSupervisor(foo, bar)
+
+	assert.Equal(t, []string{"foo", "bar"}, s.Channels())
+	assert.Equal(t, "foo", s.SelectedChannel(), "first channel selected by default")
+
+	s.SetAutoSlots(true)
+	assert.True(t, foo.IsAutoSlotsEnabled())
+
+	require.True(t, s.SelectChannel("BAR"))
+	assert.Equal(t, "bar", s.SelectedChannel())
+	assert.False(t, s.IsAutoSlotsEnabled(), "auto slots are per channel")
+
+	s.SetAutoSlots(true)
+	assert.True(t, bar.IsAutoSlotsEnabled())
+
+	assert.False(t, s.SelectChannel("baz"), "unknown channel")
+	assert.Equal(t, "bar", s.SelectedChannel(), "selection kept")
+}
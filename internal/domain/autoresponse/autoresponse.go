@@ -0,0 +1,229 @@
+package autoresponse
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxLength is the longest trigger or response accepted, the length of a
+// Twitch chat message.
+const MaxLength = 500
+
+var (
+	ErrInvalidRule      = errors.New("invalid auto-response")
+	ErrDuplicateTrigger = errors.New("auto-response trigger already exists")
+	ErrNotFound         = errors.New("auto-response not found")
+)
+
+// Rule answers a boss bot message containing Trigger with Response. A rule
+// fires at most once per CooldownSeconds, with the given Probability (zero
+// means always) and only while the balance is at least MinBalance.
+type Rule struct {
+	Trigger         string  `json:"trigger" yaml:"trigger"`
+	Response        string  `json:"response" yaml:"response"`
+	Disabled        bool    `json:"disabled,omitempty" yaml:"disabled,omitempty"`
+	CooldownSeconds int     `json:"cooldown_seconds,omitempty" yaml:"cooldown_seconds,omitempty"`
+	Probability     float64 `json:"probability,omitempty" yaml:"probability,omitempty"`
+	MinBalance      int     `json:"min_balance,omitempty" yaml:"min_balance,omitempty"`
+}
+
+func (r Rule) Cooldown() time.Duration {
+	return time.Duration(r.CooldownSeconds) * time.Second
+}
+
+func (r Rule) Validate() error {
+	switch {
+	case strings.TrimSpace(r.Trigger) == "":
+		return fmt.Errorf("%w: empty trigger", ErrInvalidRule)
+	case strings.TrimSpace(r.Response) == "":
+		return fmt.Errorf("%w: empty response for %q", ErrInvalidRule, r.Trigger)
+	case len(r.Trigger) > MaxLength || len(r.Response) > MaxLength:
+		return fmt.Errorf("%w: %q longer than %d characters", ErrInvalidRule, r.Trigger, MaxLength)
+	case r.CooldownSeconds < 0:
+		return fmt.Errorf("%w: negative cooldown for %q", ErrInvalidRule, r.Trigger)
+	case r.Probability < 0 || r.Probability > 1:
+		return fmt.Errorf("%w: probability of %q must be between 0 and 1", ErrInvalidRule, r.Trigger)
+	case r.MinBalance < 0:
+		return fmt.Errorf("%w: negative minimum balance for %q", ErrInvalidRule, r.Trigger)
+	}
+	return nil
+}
+
+// Defaults are the auto-responses of a new installation.
+func Defaults() []Rule {
+	return []Rule{
+		{Trigger: "Type !boss to start!", Response: "!boss"},
+		{Trigger: "Type !boss to join!", Response: "!boss"},
+		{Trigger: "Type !ffa to start!", Response: "!ffa"},
+		{Trigger: "!los", Response: "!los"},
+		{Trigger: "The cops have given up! If you want to get a team together type !heist", Response: "!heist"},
+	}
+}
+
+// Parse reads a rule written as "trigger | response" optionally followed by
+// "| cooldown=30s chance=50 min=1000", chance being a percentage.
+func Parse(spec string) (Rule, error) {
+	fields := strings.Split(spec, "|")
+	if len(fields) < 2 || len(fields) > 3 {
+		return Rule{}, fmt.Errorf("%w: expected trigger | response [| options]", ErrInvalidRule)
+	}
+
+	r := Rule{Trigger: strings.TrimSpace(fields[0]), Response: strings.TrimSpace(fields[1])}
+	if len(fields) == 3 {
+		for _, option := range strings.Fields(fields[2]) {
+			key, value, ok := strings.Cut(option, "=")
+			if !ok {
+				return Rule{}, fmt.Errorf("%w: option %q is not key=value", ErrInvalidRule, option)
+			}
+			if err := r.setOption(strings.ToLower(key), value); err != nil {
+				return Rule{}, err
+			}
+		}
+	}
+	return r, r.Validate()
+}
+
+func (r *Rule) setOption(key, value string) error {
+	switch key {
+	case "cooldown":
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return fmt.Errorf("%w: invalid cooldown %q", ErrInvalidRule, value)
+		}
+		r.CooldownSeconds = int(d.Round(time.Second) / time.Second)
+	case "chance":
+		percent, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+		if err != nil || percent <= 0 || percent > 100 {
+			return fmt.Errorf("%w: chance must be between 0 and 100, got %q", ErrInvalidRule, value)
+		}
+		r.Probability = percent / 100
+	case "min":
+		balance, err := strconv.Atoi(value)
+		if err != nil || balance < 0 {
+			return fmt.Errorf("%w: invalid minimum balance %q", ErrInvalidRule, value)
+		}
+		r.MinBalance = balance
+	default:
+		return fmt.Errorf("%w: unknown option %q", ErrInvalidRule, key)
+	}
+	return nil
+}
+
+// Set is an ordered list of rules. Rules are checked in order and the first
+// one that applies wins, so matching does not depend on map iteration.
+type Set struct {
+	mu    sync.Mutex
+	rules []Rule
+	fired map[string]time.Time
+}
+
+func NewSet(rules []Rule) *Set {
+	return &Set{rules: slices.Clone(rules), fired: make(map[string]time.Time)}
+}
+
+func (s *Set) Rules() []Rule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.rules)
+}
+
+// Add appends a rule; triggers must be unique.
+func (s *Set) Add(r Rule) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if slices.ContainsFunc(s.rules, func(existing Rule) bool { return existing.Trigger == r.Trigger }) {
+		return fmt.Errorf("%w: %q", ErrDuplicateTrigger, r.Trigger)
+	}
+	s.rules = append(s.rules, r)
+	return nil
+}
+
+// Replace swaps in new rules, keeping the cooldowns of the triggers that
+// remain.
+func (s *Set) Replace(rules []Rule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rules = slices.Clone(rules)
+	for trigger := range s.fired {
+		if !slices.ContainsFunc(s.rules, func(r Rule) bool { return r.Trigger == trigger }) {
+			delete(s.fired, trigger)
+		}
+	}
+}
+
+// Remove deletes the rule referenced by its 1-based position or its trigger.
+func (s *Set) Remove(ref string) (Rule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, err := s.find(ref)
+	if err != nil {
+		return Rule{}, err
+	}
+	r := s.rules[i]
+	s.rules = slices.Delete(s.rules, i, i+1)
+	delete(s.fired, r.Trigger)
+	return r, nil
+}
+
+// SetEnabled enables or disables the rule referenced by its 1-based position
+// or its trigger.
+func (s *Set) SetEnabled(ref string, enabled bool) (Rule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, err := s.find(ref)
+	if err != nil {
+		return Rule{}, err
+	}
+	s.rules[i].Disabled = !enabled
+	return s.rules[i], nil
+}
+
+func (s *Set) find(ref string) (int, error) {
+	ref = strings.TrimSpace(ref)
+	if n, err := strconv.Atoi(ref); err == nil && n >= 1 && n <= len(s.rules) {
+		return n - 1, nil
+	}
+	if i := slices.IndexFunc(s.rules, func(r Rule) bool { return r.Trigger == ref }); i >= 0 {
+		return i, nil
+	}
+	return 0, fmt.Errorf("%w: %q", ErrNotFound, ref)
+}
+
+// Match returns the response to text: the first enabled rule whose trigger
+// text contains, whose cooldown has passed and whose minimum balance is met
+// is picked, and answers if roll() falls below its probability. A rule that
+// answers starts its cooldown.
+func (s *Set) Match(text string, balance int, now time.Time, roll func() float64) (Rule, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, r := range s.rules {
+		if r.Disabled || !strings.Contains(text, r.Trigger) {
+			continue
+		}
+		if last, ok := s.fired[r.Trigger]; ok && now.Sub(last) < r.Cooldown() {
+			continue
+		}
+		if balance < r.MinBalance {
+			continue
+		}
+		if r.Probability > 0 && roll() >= r.Probability {
+			return Rule{}, false
+		}
+		s.fired[r.Trigger] = now
+		return r, true
+	}
+	return Rule{}, false
+}
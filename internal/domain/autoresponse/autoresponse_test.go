@@ -0,0 +1,175 @@
+package autoresponse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func always() float64 { return 0 }
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		spec    string
+		want    Rule
+		wantErr bool
+	}{
+		{"trigger and response", "Type !ffa to start! | !ffa", Rule{Trigger: "Type !ffa to start!", Response: "!ffa"}, false},
+		{"options", "Raffle! | !join | cooldown=1m30s chance=25% min=5000",
+			Rule{Trigger: "Raffle!", Response: "!join", CooldownSeconds: 90, Probability: 0.25, MinBalance: 5000}, false},
+		{"no response", "Raffle!", Rule{}, true},
+		{"empty response", "Raffle! | ", Rule{}, true},
+		{"too many fields", "a | b | c | d", Rule{}, true},
+		{"unknown option", "a | b | foo=1", Rule{}, true},
+		{"option without value", "a | b | cooldown", Rule{}, true},
+		{"bad cooldown", "a | b | cooldown=soon", Rule{}, true},
+		{"zero chance", "a | b | chance=0", Rule{}, true},
+		{"chance above 100", "a | b | chance=150", Rule{}, true},
+		{"negative balance", "a | b | min=-1", Rule{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Parse(tt.spec)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidRule, "Parse(%q)", tt.spec)
+				return
+			}
+			require.NoError(t, err, "Parse(%q)", tt.spec)
+			assert.Equal(t, tt.want, got, "Parse(%q)", tt.spec)
+		})
+	}
+}
+
+func TestDefaultsAreValid(t *testing.T) {
+	t.Parallel()
+
+	s := NewSet(nil)
+	for _, r := range Defaults() {
+		require.NoError(t, s.Add(r), "Add(%q)", r.Trigger)
+	}
+}
+
+func TestSetMatchesInOrder(t *testing.T) {
+	t.Parallel()
+
+	s := NewSet([]Rule{
+		{Trigger: "Type !boss to start!", Response: "!boss"},
+		{Trigger: "!boss", Response: "!wrong"},
+	})
+	now := time.Now()
+
+	for range 20 {
+		r, ok := s.Match("Type !boss to start!", 0, now, always)
+		require.True(t, ok, "Match()")
+		assert.Equal(t, "!boss", r.Response, "first matching rule wins")
+	}
+
+	_, ok := s.Match("nothing here", 0, now, always)
+	assert.False(t, ok, "Match() without trigger")
+}
+
+func TestSetMatchConditions(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+
+		s := NewSet([]Rule{{Trigger: "go", Response: "!a", Disabled: true}, {Trigger: "go", Response: "!b"}})
+		r, ok := s.Match("go", 0, now, always)
+		require.True(t, ok)
+		assert.Equal(t, "!b", r.Response, "disabled rule is skipped")
+	})
+
+	t.Run("cooldown", func(t *testing.T) {
+		t.Parallel()
+
+		s := NewSet([]Rule{{Trigger: "go", Response: "!a", CooldownSeconds: 60}})
+		_, ok := s.Match("go", 0, now, always)
+		require.True(t, ok, "first match")
+		_, ok = s.Match("go", 0, now.Add(30*time.Second), always)
+		assert.False(t, ok, "match during cooldown")
+		_, ok = s.Match("go", 0, now.Add(time.Minute), always)
+		assert.True(t, ok, "match after cooldown")
+	})
+
+	t.Run("min balance", func(t *testing.T) {
+		t.Parallel()
+
+		s := NewSet([]Rule{{Trigger: "go", Response: "!a", MinBalance: 1000}})
+		_, ok := s.Match("go", 999, now, always)
+		assert.False(t, ok, "match below minimum balance")
+		_, ok = s.Match("go", 1000, now, always)
+		assert.True(t, ok, "match at minimum balance")
+	})
+
+	t.Run("probability", func(t *testing.T) {
+		t.Parallel()
+
+		s := NewSet([]Rule{{Trigger: "go", Response: "!a", Probability: 0.5, CooldownSeconds: 60}, {Trigger: "go", Response: "!b"}})
+		_, ok := s.Match("go", 0, now, func() float64 { return 0.5 })
+		assert.False(t, ok, "lost roll answers nothing")
+		r, ok := s.Match("go", 0, now, func() float64 { return 0.49 })
+		require.True(t, ok, "won roll")
+		assert.Equal(t, "!a", r.Response)
+	})
+}
+
+func TestSetEdit(t *testing.T) {
+	t.Parallel()
+
+	s := NewSet(Defaults())
+
+	require.ErrorIs(t, s.Add(Rule{Trigger: "!los", Response: "!los"}), ErrDuplicateTrigger, "Add() duplicate")
+	require.ErrorIs(t, s.Add(Rule{Trigger: "x"}), ErrInvalidRule, "Add() without response")
+	require.NoError(t, s.Add(Rule{Trigger: "Raffle!", Response: "!join"}), "Add()")
+	assert.Len(t, s.Rules(), len(Defaults())+1)
+
+	r, err := s.SetEnabled("4", false)
+	require.NoError(t, err, "SetEnabled() by position")
+	assert.Equal(t, "!los", r.Trigger)
+	assert.True(t, s.Rules()[3].Disabled, "rule disabled")
+
+	_, err = s.SetEnabled("!los", true)
+	require.NoError(t, err, "SetEnabled() by trigger")
+	assert.False(t, s.Rules()[3].Disabled, "rule enabled")
+
+	r, err = s.Remove("Raffle!")
+	require.NoError(t, err, "Remove()")
+	assert.Equal(t, "!join", r.Response)
+
+	_, err = s.Remove("99")
+	require.ErrorIs(t, err, ErrNotFound, "Remove() out of range")
+	assert.Equal(t, Defaults(), s.Rules(), "rules after edits")
+}
+
+func TestSetReplaceKeepsCooldowns(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	s := NewSet([]Rule{
+		{Trigger: "a", Response: "!a", CooldownSeconds: 60},
+		{Trigger: "b", Response: "!b", CooldownSeconds: 60},
+	})
+	_, ok := s.Match("a", 0, now, always)
+	require.True(t, ok, "first match of a")
+	_, ok = s.Match("b", 0, now, always)
+	require.True(t, ok, "first match of b")
+
+	s.Replace([]Rule{{Trigger: "a", Response: "!a2", CooldownSeconds: 60}, {Trigger: "b", Response: "!b"}})
+
+	_, ok = s.Match("a", 0, now, always)
+	assert.False(t, ok, "kept trigger stays on cooldown")
+	r, ok := s.Match("b", 0, now, always)
+	assert.True(t, ok, "rule without cooldown answers")
+	assert.Equal(t, "!b", r.Response)
+}
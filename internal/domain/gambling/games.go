@@ -0,0 +1,123 @@
+package gambling
+
+import (
+	"sync"
+	"time"
+)
+
+const DefaultGameLogSize = 200
+
+// GameRecord describes one finished game as seen in chat, whether or not we
+// took part in it. Crew counts the participants the results reveal: the boss
+// bot lists only the paid ones, so it is the paid participants plus us when we
+// were caught, a lower bound of the real crew. Total is everything the boss bot
+// paid out and Placement is our 1-based position among the paid participants.
+// Unresolved marks a game whose result named us without a payout we could
+// read; its stake is known but not its outcome.
+type GameRecord struct {
+	Game       string
+	Time       time.Time
+	Crew       int
+	Total      int
+	Joined     bool
+	Survived   bool
+	Unresolved bool
+	Placement  int
+	Stake      int
+	Return     int
+}
+
+// GameSummary aggregates the records of one game.
+type GameSummary struct {
+	Seen     int
+	Joined   int
+	Survived int
+	// Unresolved games are counted in Staked but not in Joined.
+	Unresolved int
+	Staked     int
+	Returned   int
+	// AvgCrew averages GameRecord.Crew, so it undercounts caught players.
+	AvgCrew float64
+	// AvgPlacement is our average position in the games we survived.
+	AvgPlacement float64
+}
+
+// Profit is what we got back minus what we staked.
+func (s GameSummary) Profit() int {
+	return s.Returned - s.Staked
+}
+
+// WinRate is the share of joined games we survived.
+func (s GameSummary) WinRate() float64 {
+	if s.Joined == 0 {
+		return 0
+	}
+	return float64(s.Survived) / float64(s.Joined)
+}
+
+// GameLog keeps the most recent finished games.
+type GameLog struct {
+	mu      sync.Mutex
+	size    int
+	records []GameRecord
+}
+
+func NewGameLog(size int) *GameLog {
+	if size <= 0 {
+		size = DefaultGameLogSize
+	}
+	return &GameLog{size: size}
+}
+
+func (l *GameLog) Add(r GameRecord) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.records = append(l.records, r)
+	if len(l.records) > l.size {
+		l.records = l.records[len(l.records)-l.size:]
+	}
+}
+
+func (l *GameLog) Records() []GameRecord {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	out := make([]GameRecord, len(l.records))
+	copy(out, l.records)
+	return out
+}
+
+func (l *GameLog) Summary(game string) GameSummary {
+	var s GameSummary
+	crew, placement := 0, 0
+	for _, r := range l.Records() {
+		if r.Game != game {
+			continue
+		}
+		s.Seen++
+		crew += r.Crew
+		if r.Unresolved {
+			s.Unresolved++
+			s.Staked += r.Stake
+			continue
+		}
+		if !r.Joined {
+			continue
+		}
+		s.Joined++
+		s.Staked += r.Stake
+		s.Returned += r.Return
+		if r.Survived {
+			s.Survived++
+			placement += r.Placement
+		}
+	}
+	if s.Seen > 0 {
+		s.AvgCrew = float64(crew) / float64(s.Seen)
+	}
+	if s.Survived > 0 {
+		s.AvgPlacement = float64(placement) / float64(s.Survived)
+	}
+	return s
+}
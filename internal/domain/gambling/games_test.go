@@ -0,0 +1,47 @@
+package gambling
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGameLogBounded(t *testing.T) {
+	t.Parallel()
+
+	l := NewGameLog(2)
+	for i := 1; i <= 3; i++ {
+		l.Add(GameRecord{Game: "heist", Crew: i})
+	}
+
+	records := l.Records()
+	require.Len(t, records, 2, "game log is bounded")
+	assert.Equal(t, 2, records[0].Crew, "oldest kept record")
+	assert.Equal(t, 3, records[1].Crew, "newest record")
+}
+
+func TestGameLogSummary(t *testing.T) {
+	t.Parallel()
+
+	l := NewGameLog(0)
+	l.Add(GameRecord{Game: "heist", Crew: 4, Total: 9000, Joined: true, Survived: true, Placement: 2, Stake: 1000, Return: 3000})
+	l.Add(GameRecord{Game: "heist", Crew: 2, Total: 4000, Joined: true, Stake: 1000})
+	l.Add(GameRecord{Game: "heist", Crew: 3, Total: 5000})
+	l.Add(GameRecord{Game: "heist", Crew: 2, Total: 3000, Joined: true, Unresolved: true, Stake: 500})
+	l.Add(GameRecord{Game: "ffa", Crew: 1, Joined: true, Survived: true, Stake: 500, Return: 1000})
+
+	s := l.Summary("heist")
+	assert.Equal(t, 4, s.Seen, "Seen")
+	assert.Equal(t, 2, s.Joined, "Joined")
+	assert.Equal(t, 1, s.Survived, "Survived")
+	assert.Equal(t, 1, s.Unresolved, "Unresolved")
+	assert.Equal(t, 2500, s.Staked, "Staked")
+	assert.Equal(t, 3000, s.Returned, "Returned")
+	assert.InDelta(t, 2.75, s.AvgCrew, 1e-9, "AvgCrew")
+	assert.InDelta(t, 0.5, s.WinRate(), 1e-9, "WinRate()")
+	assert.InDelta(t, 2.0, s.AvgPlacement, 1e-9, "AvgPlacement")
+	assert.Equal(t, 500, s.Profit(), "Profit()")
+
+	assert.Zero(t, l.Summary("slots").WinRate(), "WinRate() without joined games")
+}
@@ -0,0 +1,33 @@
+package gambling
+
+const (
+	GuardrailOK         = "ok"
+	GuardrailStopLoss   = "stop_loss"
+	GuardrailTakeProfit = "take_profit"
+)
+
+// Guardrails limit how much of the bankroll a session may risk. A zero value
+// disables the corresponding limit.
+type Guardrails struct {
+	Reserve    int
+	StopLoss   int
+	TakeProfit int
+}
+
+// CanSpend reports whether spending amount keeps the balance at or above the
+// reserve floor.
+func (g Guardrails) CanSpend(balance, amount int) bool {
+	return g.Reserve <= 0 || balance-amount >= g.Reserve
+}
+
+// Check compares the balance with the one at session start and returns the
+// tripped guardrail, or GuardrailOK.
+func (g Guardrails) Check(start, balance int) string {
+	if g.StopLoss > 0 && start-balance >= g.StopLoss {
+		return GuardrailStopLoss
+	}
+	if g.TakeProfit > 0 && balance-start >= g.TakeProfit {
+		return GuardrailTakeProfit
+	}
+	return GuardrailOK
+}
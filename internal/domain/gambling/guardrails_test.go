@@ -0,0 +1,62 @@
+package gambling
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuardrailsCanSpend(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		reserve int
+		balance int
+		amount  int
+		want    bool
+	}{
+		{"no reserve", 0, 1000, 1000, true},
+		{"above floor", 5000, 10000, 2000, true},
+		{"exactly at floor", 5000, 7000, 2000, true},
+		{"below floor", 5000, 6000, 2000, false},
+		{"already below floor", 5000, 4000, 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			g := Guardrails{Reserve: tt.reserve}
+			assert.Equal(t, tt.want, g.CanSpend(tt.balance, tt.amount), "CanSpend(%d, %d)", tt.balance, tt.amount)
+		})
+	}
+}
+
+func TestGuardrailsCheck(t *testing.T) {
+	t.Parallel()
+
+	g := Guardrails{StopLoss: 5000, TakeProfit: 20000}
+
+	tests := []struct {
+		name    string
+		balance int
+		want    string
+	}{
+		{"unchanged", 10000, GuardrailOK},
+		{"small loss", 6000, GuardrailOK},
+		{"stop loss", 5000, GuardrailStopLoss},
+		{"small gain", 29999, GuardrailOK},
+		{"take profit", 30000, GuardrailTakeProfit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, g.Check(10000, tt.balance), "Check(10000, %d)", tt.balance)
+		})
+	}
+
+	assert.Equal(t, GuardrailOK, Guardrails{}.Check(10000, 0), "disabled guardrails never trip")
+}
@@ -10,6 +10,8 @@ const (
 	DefaultSlotsCost = 2000
 
 	DefaultArenaCost = 1000
+
+	DefaultBossCost = 0
 )
 
 var ErrInvalidAmount = errors.New("invalid heist amount")
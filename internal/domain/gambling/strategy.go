@@ -0,0 +1,223 @@
+package gambling
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	StrategyFixed      = "fixed"
+	StrategyPercentage = "percent"
+	StrategyMartingale = "martingale"
+	StrategyKelly      = "kelly"
+
+	DefaultPercentage    = 5.0
+	DefaultMaxDoublings  = 3
+	DefaultKellyFraction = 0.25
+	KellyMinSamples      = 10
+
+	DefaultHistorySize = 200
+)
+
+var ErrInvalidStrategy = errors.New("invalid strategy")
+
+// Result is the outcome of one played game: the stake we paid and the amount
+// the boss bot paid back (0 when lost).
+type Result struct {
+	Game   string
+	Stake  int
+	Return int
+}
+
+func (r Result) Won() bool {
+	return r.Return > r.Stake
+}
+
+// Opportunity describes a game the bot is about to join. BaseStake is the
+// configured heist amount or the fixed cost of slots/ffa.
+type Opportunity struct {
+	Game      string
+	Balance   int
+	BaseStake int
+	History   []Result
+}
+
+// Strategy decides how much to stake. For games with a fixed cost a stake
+// below BaseStake means the opportunity is skipped.
+type Strategy interface {
+	Name() string
+	Stake(o Opportunity) int
+}
+
+type FixedStrategy struct{}
+
+func (FixedStrategy) Name() string { return StrategyFixed }
+
+func (FixedStrategy) Stake(o Opportunity) int {
+	return o.BaseStake
+}
+
+type PercentageStrategy struct {
+	Percent float64
+}
+
+func (s PercentageStrategy) Name() string {
+	return fmt.Sprintf("%s:%g", StrategyPercentage, s.Percent)
+}
+
+func (s PercentageStrategy) Stake(o Opportunity) int {
+	return int(math.Floor(float64(o.Balance) * s.Percent / 100))
+}
+
+// MartingaleStrategy doubles the base stake after every consecutive loss of
+// the same game, at most MaxDoublings times.
+type MartingaleStrategy struct {
+	MaxDoublings int
+}
+
+func (s MartingaleStrategy) Name() string {
+	return fmt.Sprintf("%s:%d", StrategyMartingale, s.MaxDoublings)
+}
+
+func (s MartingaleStrategy) Stake(o Opportunity) int {
+	losses := 0
+	for i := len(o.History) - 1; i >= 0; i-- {
+		r := o.History[i]
+		if r.Game != o.Game {
+			continue
+		}
+		if r.Won() {
+			break
+		}
+		losses++
+	}
+	if losses > s.MaxDoublings {
+		losses = s.MaxDoublings
+	}
+	return o.BaseStake << losses
+}
+
+// KellyStrategy stakes a fraction of the Kelly criterion computed from the
+// observed win rate and average win of the game. Until KellyMinSamples games
+// are known it stakes BaseStake.
+type KellyStrategy struct {
+	Fraction float64
+}
+
+func (s KellyStrategy) Name() string {
+	return fmt.Sprintf("%s:%g", StrategyKelly, s.Fraction)
+}
+
+func (s KellyStrategy) Stake(o Opportunity) int {
+	played, won := 0, 0
+	var gainRatio float64
+	for _, r := range o.History {
+		if r.Game != o.Game || r.Stake <= 0 {
+			continue
+		}
+		played++
+		if r.Won() {
+			won++
+			gainRatio += float64(r.Return-r.Stake) / float64(r.Stake)
+		}
+	}
+
+	if played < KellyMinSamples {
+		return o.BaseStake
+	}
+	if won == 0 {
+		return 0
+	}
+
+	p := float64(won) / float64(played)
+	b := gainRatio / float64(won)
+	f := p - (1-p)/b
+	if f <= 0 {
+		return 0
+	}
+	return int(math.Floor(float64(o.Balance) * f * s.Fraction))
+}
+
+// ParseStrategy builds a strategy from "name" or "name:parameter", e.g.
+// "fixed", "percent:5", "martingale:3" or "kelly:0.25".
+func ParseStrategy(spec string) (Strategy, error) {
+	name, param, hasParam := strings.Cut(strings.ToLower(strings.TrimSpace(spec)), ":")
+
+	switch name {
+	case "", StrategyFixed:
+		return FixedStrategy{}, nil
+
+	case StrategyPercentage:
+		pct := DefaultPercentage
+		if hasParam {
+			v, err := strconv.ParseFloat(param, 64)
+			if err != nil || v <= 0 || v > 100 {
+				return nil, fmt.Errorf("%w: percent must be between 0 and 100, got %q", ErrInvalidStrategy, param)
+			}
+			pct = v
+		}
+		return PercentageStrategy{Percent: pct}, nil
+
+	case StrategyMartingale:
+		doublings := DefaultMaxDoublings
+		if hasParam {
+			v, err := strconv.Atoi(param)
+			if err != nil || v < 0 || v > 10 {
+				return nil, fmt.Errorf("%w: martingale doublings must be between 0 and 10, got %q", ErrInvalidStrategy, param)
+			}
+			doublings = v
+		}
+		return MartingaleStrategy{MaxDoublings: doublings}, nil
+
+	case StrategyKelly:
+		fraction := DefaultKellyFraction
+		if hasParam {
+			v, err := strconv.ParseFloat(param, 64)
+			if err != nil || v <= 0 || v > 1 {
+				return nil, fmt.Errorf("%w: kelly fraction must be between 0 and 1, got %q", ErrInvalidStrategy, param)
+			}
+			fraction = v
+		}
+		return KellyStrategy{Fraction: fraction}, nil
+
+	default:
+		return nil, fmt.Errorf("%w: unknown strategy %q", ErrInvalidStrategy, name)
+	}
+}
+
+// History keeps the most recent game results for strategies to learn from.
+type History struct {
+	mu      sync.Mutex
+	size    int
+	results []Result
+}
+
+func NewHistory(size int) *History {
+	if size <= 0 {
+		size = DefaultHistorySize
+	}
+	return &History{size: size}
+}
+
+func (h *History) Add(r Result) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.results = append(h.results, r)
+	if len(h.results) > h.size {
+		h.results = h.results[len(h.results)-h.size:]
+	}
+}
+
+func (h *History) Results() []Result {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	out := make([]Result, len(h.results))
+	copy(out, h.results)
+	return out
+}
@@ -0,0 +1,131 @@
+package gambling
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func repeatResults(r Result, n int) []Result {
+	out := make([]Result, n)
+	for i := range out {
+		out[i] = r
+	}
+	return out
+}
+
+func TestParseStrategy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		spec     string
+		wantName string
+		wantErr  bool
+	}{
+		{"", "fixed", false},
+		{"fixed", "fixed", false},
+		{"percent", "percent:5", false},
+		{"Percent:2.5", "percent:2.5", false},
+		{"martingale", "martingale:3", false},
+		{"martingale:5", "martingale:5", false},
+		{"kelly", "kelly:0.25", false},
+		{"kelly:0.5", "kelly:0.5", false},
+		{"percent:0", "", true},
+		{"percent:101", "", true},
+		{"martingale:-1", "", true},
+		{"martingale:x", "", true},
+		{"kelly:2", "", true},
+		{"yolo", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			t.Parallel()
+
+			s, err := ParseStrategy(tt.spec)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidStrategy, "ParseStrategy(%q)", tt.spec)
+				return
+			}
+			require.NoError(t, err, "ParseStrategy(%q)", tt.spec)
+			assert.Equal(t, tt.wantName, s.Name(), "ParseStrategy(%q).Name()", tt.spec)
+		})
+	}
+}
+
+func TestFixedAndPercentageStrategy(t *testing.T) {
+	t.Parallel()
+
+	o := Opportunity{Game: "heist", Balance: 50000, BaseStake: 1000}
+
+	assert.Equal(t, 1000, FixedStrategy{}.Stake(o), "fixed stake")
+	assert.Equal(t, 2500, PercentageStrategy{Percent: 5}.Stake(o), "percentage stake")
+	assert.Equal(t, 0, PercentageStrategy{Percent: 5}.Stake(Opportunity{Balance: 10}), "percentage of tiny balance")
+}
+
+func TestMartingaleStrategy(t *testing.T) {
+	t.Parallel()
+
+	loss := Result{Game: "heist", Stake: 1000, Return: 0}
+	win := Result{Game: "heist", Stake: 1000, Return: 2000}
+	other := Result{Game: "slots", Stake: 2000, Return: 0}
+	s := MartingaleStrategy{MaxDoublings: 3}
+
+	tests := []struct {
+		name    string
+		history []Result
+		want    int
+	}{
+		{"no history", nil, 1000},
+		{"one loss", []Result{loss}, 2000},
+		{"two losses ignoring other games", []Result{loss, other, loss}, 4000},
+		{"win resets", []Result{loss, loss, win}, 1000},
+		{"capped", repeatResults(loss, 8), 8000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := s.Stake(Opportunity{Game: "heist", Balance: 100000, BaseStake: 1000, History: tt.history})
+			assert.Equal(t, tt.want, got, "martingale stake")
+		})
+	}
+}
+
+func TestKellyStrategy(t *testing.T) {
+	t.Parallel()
+
+	s := KellyStrategy{Fraction: 0.5}
+	win := Result{Game: "heist", Stake: 1000, Return: 3000}
+	loss := Result{Game: "heist", Stake: 1000, Return: 0}
+
+	few := Opportunity{Game: "heist", Balance: 10000, BaseStake: 1000, History: []Result{win}}
+	assert.Equal(t, 1000, s.Stake(few), "base stake until enough samples")
+
+	// p = 0.5, b = 2 -> f = 0.25, half Kelly of 10000 = 1250
+	history := append(repeatResults(win, 5), repeatResults(loss, 5)...)
+	o := Opportunity{Game: "heist", Balance: 10000, BaseStake: 1000, History: history}
+	assert.Equal(t, 1250, s.Stake(o), "half Kelly stake")
+
+	losing := Opportunity{Game: "heist", Balance: 10000, BaseStake: 1000, History: append(repeatResults(loss, 9), win)}
+	assert.Equal(t, 0, s.Stake(losing), "negative edge skips")
+
+	allLost := Opportunity{Game: "heist", Balance: 10000, BaseStake: 1000, History: repeatResults(loss, 10)}
+	assert.Equal(t, 0, s.Stake(allLost), "no wins skips")
+}
+
+func TestHistory(t *testing.T) {
+	t.Parallel()
+
+	h := NewHistory(3)
+	for i := 1; i <= 5; i++ {
+		h.Add(Result{Game: "slots", Stake: i})
+	}
+
+	results := h.Results()
+	require.Len(t, results, 3, "history is bounded")
+	assert.Equal(t, 3, results[0].Stake, "oldest kept result")
+	assert.Equal(t, 5, results[2].Stake, "newest result")
+}
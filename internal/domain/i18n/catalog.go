@@ -0,0 +1,133 @@
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const DefaultLanguage = "pl"
+
+var (
+	ErrInvalidCatalog  = errors.New("invalid message catalog")
+	ErrUnknownLanguage = errors.New("unknown language")
+)
+
+var placeholderRe = regexp.MustCompile(`\{(\w+)\}`)
+
+// Vars are the values substituted for {name} placeholders of a template.
+type Vars map[string]any
+
+// Catalog holds the chat reply templates of one language keyed by message
+// ID. Templates use {name} placeholders, e.g. "@{user}, Heist: {heist}".
+type Catalog struct {
+	Language string
+	Messages map[string]string
+}
+
+var builtins = map[string]map[string]string{
+	"pl": polish,
+	"en": english,
+}
+
+// Builtin returns a copy of a built-in catalog.
+func Builtin(language string) (*Catalog, bool) {
+	language = strings.ToLower(strings.TrimSpace(language))
+	messages, ok := builtins[language]
+	if !ok {
+		return nil, false
+	}
+	return &Catalog{Language: language, Messages: maps.Clone(messages)}, true
+}
+
+// CheckLanguage returns an error wrapping ErrUnknownLanguage unless language
+// has a built-in catalog.
+func CheckLanguage(language string) error {
+	if _, ok := Builtin(language); !ok {
+		return fmt.Errorf("%w %q, built in: %s", ErrUnknownLanguage, language, strings.Join(Languages(), ", "))
+	}
+	return nil
+}
+
+// Default returns the built-in catalog of DefaultLanguage.
+func Default() *Catalog {
+	c, _ := Builtin(DefaultLanguage)
+	return c
+}
+
+// Languages returns the built-in languages.
+func Languages() []string {
+	languages := slices.Collect(maps.Keys(builtins))
+	sort.Strings(languages)
+	return languages
+}
+
+// Override replaces single templates. Message IDs the bot does not use and
+// placeholders the message does not provide are rejected.
+func (c *Catalog) Override(templates map[string]string) error {
+	var problems []string
+	for _, id := range slices.Sorted(maps.Keys(templates)) {
+		builtin, ok := polish[id]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("unknown message %q", id))
+			continue
+		}
+		known := placeholders(builtin)
+		for _, name := range placeholders(templates[id]) {
+			if !slices.Contains(known, name) {
+				problems = append(problems, fmt.Sprintf("message %q has no placeholder {%s}", id, name))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w %q: %s", ErrInvalidCatalog, c.Language, strings.Join(problems, "; "))
+	}
+
+	if c.Messages == nil {
+		c.Messages = make(map[string]string, len(templates))
+	}
+	maps.Copy(c.Messages, templates)
+	return nil
+}
+
+// Format returns the template of a message with vars substituted. A missing
+// message yields its ID, a missing var leaves its placeholder as is.
+func (c *Catalog) Format(id string, vars Vars) string {
+	template := id
+	if c != nil {
+		if t, ok := c.Messages[id]; ok {
+			template = t
+		}
+	}
+	return placeholderRe.ReplaceAllStringFunc(template, func(placeholder string) string {
+		if v, ok := vars[placeholder[1:len(placeholder)-1]]; ok {
+			return fmt.Sprint(v)
+		}
+		return placeholder
+	})
+}
+
+// Text returns the template of a message without placeholders, or fallback
+// when the catalog has none.
+func (c *Catalog) Text(id, fallback string) string {
+	if c != nil {
+		if t, ok := c.Messages[id]; ok {
+			return t
+		}
+	}
+	return fallback
+}
+
+func placeholders(template string) []string {
+	var names []string
+	for _, m := range placeholderRe.FindAllStringSubmatch(template, -1) {
+		if !slices.Contains(names, m[1]) {
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
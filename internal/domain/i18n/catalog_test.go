@@ -0,0 +1,82 @@
+package i18n
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltinCatalogsMatch(t *testing.T) {
+	t.Parallel()
+
+	ids := slices.Sorted(maps.Keys(polish))
+	for _, language := range Languages() {
+		c, ok := Builtin(language)
+		require.True(t, ok, "Builtin(%q)", language)
+		assert.Equal(t, ids, slices.Sorted(maps.Keys(c.Messages)), "%s has every message", language)
+		for _, id := range ids {
+			assert.ElementsMatch(t, placeholders(polish[id]), placeholders(c.Messages[id]), "%s %q placeholders", language, id)
+		}
+	}
+}
+
+func TestBuiltinReturnsCopy(t *testing.T) {
+	t.Parallel()
+
+	c, ok := Builtin("EN")
+	require.True(t, ok, "language is case-insensitive")
+	c.Messages["status"] = "changed"
+
+	again, _ := Builtin("en")
+	assert.NotEqual(t, "changed", again.Messages["status"], "built-in catalog not modified")
+}
+
+func TestCheckLanguage(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, CheckLanguage("pl"))
+	require.NoError(t, CheckLanguage("en"))
+	require.ErrorIs(t, CheckLanguage("xx"), ErrUnknownLanguage)
+}
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+
+	c := &Catalog{Messages: map[string]string{"hi": "@{user}, {count} bombs {missing}"}}
+
+	assert.Equal(t, "@bob, 5 bombs {missing}", c.Format("hi", Vars{"user": "bob", "count": 5}), "missing vars keep their placeholder")
+	assert.Equal(t, "nope", c.Format("nope", nil), "missing message yields its ID")
+	assert.Equal(t, "fallback", c.Text("nope", "fallback"))
+
+	var none *Catalog
+	assert.Equal(t, "x", none.Text("hi", "x"), "nil catalog falls back")
+}
+
+func TestOverride(t *testing.T) {
+	t.Parallel()
+
+	c := Default()
+	require.NoError(t, c.Override(map[string]string{"status": "{user} ok {balance}"}))
+	assert.Equal(t, "bob ok 7", c.Format("status", Vars{"user": "bob", "balance": 7}))
+
+	tests := []struct {
+		name      string
+		templates map[string]string
+	}{
+		{"unknown message", map[string]string{"statsu": "x"}},
+		{"unknown placeholder", map[string]string{"status": "{user} {nope}"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := Default()
+			err := c.Override(tt.templates)
+			require.ErrorIs(t, err, ErrInvalidCatalog)
+			assert.Equal(t, polish["status"], c.Messages["status"], "rejected overrides change nothing")
+		})
+	}
+}
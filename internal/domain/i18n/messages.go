@@ -0,0 +1,197 @@
+package i18n
+
+// polish is the reference catalog: every message the bot sends has an entry
+// here, and overrides may only use the placeholders listed here.
+var polish = map[string]string{
+	"usage": "@{user}, Użyj: {usage}",
+
+	"status": "@{user}, Bot działa prawidłowo ;) | Bombs: {balance} | Heist: {heist}",
+
+	"heist.invalid":        "@{user}, Podaj liczbę od 1 do max {max}!",
+	"heist.update_failed":  "@{user}, Wystąpił błąd podczas aktualizacji wartości heist!",
+	"heist.updated":        "@{user}, Pomyślnie zmieniono ilość heista na {heist}!",
+	"heist.current":        "@{user}, Masz aktualnie ustawione {heist} heista ;)",
+	"autoslots.status":     "@{user}, Auto slots jest {state}. Użyj: {usage}",
+	"autoslots.state_on":   "włączone",
+	"autoslots.state_off":  "wyłączone",
+	"autoslots.enabled":    "@{user}, Auto slots włączone!",
+	"autoslots.disabled":   "@{user}, Auto slots wyłączone!",
+	"strategy.current":     "@{user}, Aktualna strategia: {strategy}. Użyj: {command} fixed/percent:N/martingale:N/kelly:F",
+	"strategy.invalid":     "@{user}, Nieprawidłowa strategia! Użyj: {command} fixed/percent:N/martingale:N/kelly:F",
+	"strategy.set":         "@{user}, Ustawiono strategię {strategy}!",
+	"session.reset":        "@{user}, Nowa sesja od {balance} bombs, gry wznowione!",
+	"session.status":       "@{user}, Sesja: {profit} bombs | Stan: {state} | Użyj: {command} reset",
+	"heists.none":          "@{user}, Brak zapisanych heistów.",
+	"heists.stats":         "@{user}, Heisty: {seen} (udział: {joined}, przeżyte: {survived}, {win_rate}%) | Postawione: {staked} | Wygrane: {returned} | Śr. ekipa: {avg_crew}",
+	"games.none":           "@{user}, Brak zapisanych gier.",
+	"games.game":           "{game}: {survived}/{joined} wygranych ({win_rate}%), bilans {profit}, śr. miejsce {avg_placement}",
+	"games.stats":          "@{user}, {games}",
+	"slotsoff.none":        "@{user}, Brak zaplanowanego wyłączenia. Użyj: {command} <czas> lub {command} <duration>",
+	"slotsoff.scheduled":   "@{user}, Auto slots wyłączy się o {time} (za {remaining})",
+	"slotsoff.canceled":    "@{user}, Anulowano zaplanowane wyłączenie.",
+	"slotsoff.not_planned": "@{user}, Brak zaplanowanego wyłączenia.",
+	"slotsoff.bad_time":    "@{user}, Nieprawidłowy czas. Użyj formatu HH:MM (np. 22:00)",
+	"slotsoff.set_at":      "@{user}, Auto slots wyłączy się o {time}",
+	"slotsoff.invalid":     "@{user}, Użyj: {command} <HH:MM> lub {command} <duration> (np. 2h, 30m, 1h30m)",
+	"slotsoff.set_in":      "@{user}, Auto slots wyłączy się za {duration} (o {time})",
+	"trust.self":           "@{user}, Nie możesz dodać siebie do listy!",
+	"trust.already":        "@{user}, {target} już jest zaufanym użytkownikiem",
+	"trust.added":          "@{user}, Dodano {target} do zaufanych użytkowników!",
+	"trust.added_pending":  "@{user}, Dodano {target} do zaufanych użytkowników! Zaufanie zostanie przypisane do konta przy pierwszej wiadomości {target}.",
+	"untrust.not_trusted":  "@{user}, {target} nie jest zaufanym użytkownikiem",
+	"untrust.removed":      "@{user}, Usunięto {target} z zaufanych użytkowników!",
+	"trustlist.empty":      "@{user}, Lista zaufanych jest pusta.",
+	"trustlist.users":      "@{user}, Zaufani: {users}",
+
+	"autoresponse.list":      "Auto-odpowiedzi:",
+	"autoresponse.item":      "{n}. {trigger} → {response}",
+	"autoresponse.off":       "wyłączona",
+	"autoresponse.cooldown":  "odstęp {cooldown}",
+	"autoresponse.chance":    "szansa {chance}%",
+	"autoresponse.min":       "min. {min} bombs",
+	"autoresponse.empty":     "@{user}, Brak auto-odpowiedzi.",
+	"autoresponse.added":     "@{user}, Dodano auto-odpowiedź #{n}: {trigger} → {response}",
+	"autoresponse.exists":    "@{user}, Auto-odpowiedź na \"{trigger}\" już istnieje!",
+	"autoresponse.invalid":   "@{user}, Nieprawidłowa auto-odpowiedź! Użyj: {command} add <wyzwalacz> | <odpowiedź> [| cooldown=30s chance=50 min=1000]",
+	"autoresponse.not_found": "@{user}, Nie ma auto-odpowiedzi {ref}. Sprawdź: {command} list",
+	"autoresponse.removed":   "@{user}, Usunięto auto-odpowiedź na \"{trigger}\"!",
+	"autoresponse.enabled":   "@{user}, Włączono auto-odpowiedź na \"{trigger}\"!",
+	"autoresponse.disabled":  "@{user}, Wyłączono auto-odpowiedź na \"{trigger}\"!",
+
+	"setting.list":          "Ustawienia:",
+	"setting.value":         "@{user}, {key} = {value}",
+	"setting.updated":       "@{user}, Ustawiono {key} na {value}!",
+	"setting.unknown":       "@{user}, Nieznane ustawienie {key}! Sprawdź: {command}",
+	"setting.invalid":       "@{user}, Nie zapisano: {error}",
+	"setting.update_failed": "@{user}, Nie udało się zapisać {key}!",
+
+	"help.list":     "Komendy:",
+	"help.unknown":  "@{user}, Nieznana komenda! Użyj: {usage}",
+	"help.command":  "@{user}, {usage} - {help}",
+	"help.aliases":  "Aliasy: {aliases}",
+	"help.roles":    "Dostęp: {roles}",
+	"help.cooldown": "Odstęp: {cooldown}",
+
+	"help.status":       "status bota",
+	"help.ustaw":        "ustawia heist",
+	"help.jakiheist":    "pokazuje heist",
+	"help.autoslots":    "auto slots",
+	"help.strategia":    "strategia stawek",
+	"help.sesja":        "wynik sesji i limity",
+	"help.heisty":       "statystyki heistów",
+	"help.gry":          "wyniki gier",
+	"help.slotsoff":     "planuje wyłączenie auto slots",
+	"help.trust":        "dodaje zaufanego",
+	"help.untrust":      "usuwa zaufanego",
+	"help.trustlist":    "lista zaufanych",
+	"help.autoresponse": "zarządza auto-odpowiedziami",
+	"help.set":          "zmienia ustawienie",
+	"help.get":          "pokazuje ustawienia",
+	"help.help":         "ta pomoc",
+
+	"arg.amount":        "kwota",
+	"arg.strategy":      "nazwa",
+	"arg.time/duration": "czas/duration",
+	"arg.user":          "nick",
+	"arg.command":       "komenda",
+	"arg.rule":          "reguła",
+	"arg.key":           "ustawienie",
+	"arg.value":         "wartość",
+}
+
+var english = map[string]string{
+	"usage": "@{user}, Usage: {usage}",
+
+	"status": "@{user}, Bot is up and running ;) | Bombs: {balance} | Heist: {heist}",
+
+	"heist.invalid":        "@{user}, Give a number from 1 to {max}!",
+	"heist.update_failed":  "@{user}, Could not update the heist amount!",
+	"heist.updated":        "@{user}, Heist amount changed to {heist}!",
+	"heist.current":        "@{user}, The heist amount is {heist} ;)",
+	"autoslots.status":     "@{user}, Auto slots is {state}. Usage: {usage}",
+	"autoslots.state_on":   "on",
+	"autoslots.state_off":  "off",
+	"autoslots.enabled":    "@{user}, Auto slots enabled!",
+	"autoslots.disabled":   "@{user}, Auto slots disabled!",
+	"strategy.current":     "@{user}, Current strategy: {strategy}. Usage: {command} fixed/percent:N/martingale:N/kelly:F",
+	"strategy.invalid":     "@{user}, Invalid strategy! Usage: {command} fixed/percent:N/martingale:N/kelly:F",
+	"strategy.set":         "@{user}, Strategy set to {strategy}!",
+	"session.reset":        "@{user}, New session from {balance} bombs, games resumed!",
+	"session.status":       "@{user}, Session: {profit} bombs | State: {state} | Usage: {command} reset",
+	"heists.none":          "@{user}, No heists recorded yet.",
+	"heists.stats":         "@{user}, Heists: {seen} (joined: {joined}, survived: {survived}, {win_rate}%) | Staked: {staked} | Won: {returned} | Avg. crew: {avg_crew}",
+	"games.none":           "@{user}, No games recorded yet.",
+	"games.game":           "{game}: {survived}/{joined} won ({win_rate}%), profit {profit}, avg. place {avg_placement}",
+	"games.stats":          "@{user}, {games}",
+	"slotsoff.none":        "@{user}, Nothing scheduled. Usage: {command} <time> or {command} <duration>",
+	"slotsoff.scheduled":   "@{user}, Auto slots turns off at {time} (in {remaining})",
+	"slotsoff.canceled":    "@{user}, Scheduled turn off canceled.",
+	"slotsoff.not_planned": "@{user}, Nothing scheduled.",
+	"slotsoff.bad_time":    "@{user}, Invalid time. Use HH:MM (e.g. 22:00)",
+	"slotsoff.set_at":      "@{user}, Auto slots turns off at {time}",
+	"slotsoff.invalid":     "@{user}, Usage: {command} <HH:MM> or {command} <duration> (e.g. 2h, 30m, 1h30m)",
+	"slotsoff.set_in":      "@{user}, Auto slots turns off in {duration} (at {time})",
+	"trust.self":           "@{user}, You cannot add yourself!",
+	"trust.already":        "@{user}, {target} is already trusted",
+	"trust.added":          "@{user}, {target} is now trusted!",
+	"trust.added_pending":  "@{user}, {target} is now trusted! Trust is tied to the account on {target}'s first message.",
+	"untrust.not_trusted":  "@{user}, {target} is not trusted",
+	"untrust.removed":      "@{user}, {target} is no longer trusted!",
+	"trustlist.empty":      "@{user}, Nobody is trusted.",
+	"trustlist.users":      "@{user}, Trusted: {users}",
+
+	"autoresponse.list":      "Auto-responses:",
+	"autoresponse.item":      "{n}. {trigger} → {response}",
+	"autoresponse.off":       "disabled",
+	"autoresponse.cooldown":  "cooldown {cooldown}",
+	"autoresponse.chance":    "chance {chance}%",
+	"autoresponse.min":       "min. {min} bombs",
+	"autoresponse.empty":     "@{user}, No auto-responses.",
+	"autoresponse.added":     "@{user}, Added auto-response #{n}: {trigger} → {response}",
+	"autoresponse.exists":    "@{user}, An auto-response to \"{trigger}\" already exists!",
+	"autoresponse.invalid":   "@{user}, Invalid auto-response! Usage: {command} add <trigger> | <response> [| cooldown=30s chance=50 min=1000]",
+	"autoresponse.not_found": "@{user}, No auto-response {ref}. See: {command} list",
+	"autoresponse.removed":   "@{user}, Removed the auto-response to \"{trigger}\"!",
+	"autoresponse.enabled":   "@{user}, Enabled the auto-response to \"{trigger}\"!",
+	"autoresponse.disabled":  "@{user}, Disabled the auto-response to \"{trigger}\"!",
+
+	"setting.list":          "Settings:",
+	"setting.value":         "@{user}, {key} = {value}",
+	"setting.updated":       "@{user}, {key} set to {value}!",
+	"setting.unknown":       "@{user}, Unknown setting {key}! See: {command}",
+	"setting.invalid":       "@{user}, Not saved: {error}",
+	"setting.update_failed": "@{user}, Could not save {key}!",
+
+	"help.list":     "Commands:",
+	"help.unknown":  "@{user}, Unknown command! Usage: {usage}",
+	"help.command":  "@{user}, {usage} - {help}",
+	"help.aliases":  "Aliases: {aliases}",
+	"help.roles":    "Access: {roles}",
+	"help.cooldown": "Cooldown: {cooldown}",
+
+	"help.status":       "bot status",
+	"help.ustaw":        "sets the heist amount",
+	"help.jakiheist":    "shows the heist amount",
+	"help.autoslots":    "auto slots",
+	"help.strategia":    "betting strategy",
+	"help.sesja":        "session result and limits",
+	"help.heisty":       "heist statistics",
+	"help.gry":          "game results",
+	"help.slotsoff":     "schedules auto slots turn off",
+	"help.trust":        "trusts a user",
+	"help.untrust":      "untrusts a user",
+	"help.trustlist":    "trusted users",
+	"help.autoresponse": "manages auto-responses",
+	"help.set":          "changes a setting",
+	"help.get":          "shows settings",
+	"help.help":         "this help",
+
+	"arg.amount":        "amount",
+	"arg.strategy":      "name",
+	"arg.time/duration": "time/duration",
+	"arg.user":          "user",
+	"arg.command":       "command",
+	"arg.rule":          "rule",
+	"arg.key":           "key",
+	"arg.value":         "value",
+}
@@ -0,0 +1,402 @@
+package parsing
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const UserPlaceholder = "{user}"
+
+const DefaultDialectName = "default"
+
+// DefaultPayoutPattern matches "user (1 234)" participants of a game result.
+const DefaultPayoutPattern = `(\w+)\s*\(\s*(\d[\d ]*)\)`
+
+const (
+	DefaultPointsMarker  = "{user} ("
+	DefaultPointsPattern = `(?i)\b{user}\s*\(\s*(\d[\d ]*?)\s*\)`
+)
+
+var ErrInvalidDialect = errors.New("invalid dialect")
+
+// PhraseRule matches a boss bot message when every phrase in Contains is
+// present (case-insensitive). {user} in a phrase is replaced with our username.
+type PhraseRule struct {
+	UserAtStart bool     `json:"user_at_start,omitempty" yaml:"user_at_start,omitempty"`
+	Contains    []string `json:"contains" yaml:"contains"`
+}
+
+type OutcomeRule struct {
+	Outcome  SlotsOutcome `json:"outcome" yaml:"outcome"`
+	Contains []string     `json:"contains" yaml:"contains"`
+}
+
+type BalanceDialect struct {
+	Command string `json:"command" yaml:"command"`
+	Marker  string `json:"marker" yaml:"marker"`
+	Pattern string `json:"pattern" yaml:"pattern"`
+}
+
+// PointsDialect recognizes a "user (amount)" reply with our points. Replies
+// containing one of Ignore, such as leaderboards, are not points replies.
+// Empty Marker and Pattern mean DefaultPointsMarker and DefaultPointsPattern.
+type PointsDialect struct {
+	Marker  string   `json:"marker" yaml:"marker"`
+	Pattern string   `json:"pattern" yaml:"pattern"`
+	Ignore  []string `json:"ignore,omitempty" yaml:"ignore,omitempty"`
+}
+
+type SlotsDialect struct {
+	Trigger string `json:"trigger" yaml:"trigger"`
+	// AmountPattern, when set, extracts the amount actually paid out from
+	// the slots reply; its first capture group is the amount.
+	AmountPattern string        `json:"amount_pattern,omitempty" yaml:"amount_pattern,omitempty"`
+	Outcomes      []OutcomeRule `json:"outcomes" yaml:"outcomes"`
+}
+
+type Dialect struct {
+	Name        string         `json:"name" yaml:"name"`
+	Balance     BalanceDialect `json:"balance" yaml:"balance"`
+	Slots       SlotsDialect   `json:"slots" yaml:"slots"`
+	Points      PointsDialect  `json:"points" yaml:"points"`
+	HeistResult []string       `json:"heist_result" yaml:"heist_result"`
+	ArenaResult []string       `json:"arena_result" yaml:"arena_result"`
+	// BossResult marks the end of a boss fight; survivors are listed with
+	// PayoutPattern. Empty disables boss fight tracking; the default dialect
+	// has no markers, as no boss fight output of the original boss bot has
+	// been captured yet.
+	BossResult []string `json:"boss_result,omitempty" yaml:"boss_result,omitempty"`
+	// PayoutPattern matches one participant of a game result; the first
+	// capture group is the user and the second the amount paid out. Empty
+	// means DefaultPayoutPattern.
+	PayoutPattern     string       `json:"payout_pattern" yaml:"payout_pattern"`
+	Cooldown          []PhraseRule `json:"cooldown" yaml:"cooldown"`
+	InsufficientFunds []PhraseRule `json:"insufficient_funds" yaml:"insufficient_funds"`
+	// EntryConfirmed recognizes the boss bot acknowledging that we joined a
+	// game, keyed by game name (heist, ffa, boss).
+	EntryConfirmed map[string][]PhraseRule `json:"entry_confirmed,omitempty" yaml:"entry_confirmed,omitempty"`
+	// GameNames are the words naming each game in cooldown and insufficient
+	// funds replies, keyed by game name (slots, heist, ffa, boss).
+	GameNames map[string][]string `json:"game_names,omitempty" yaml:"game_names,omitempty"`
+
+	balanceRe     *regexp.Regexp
+	slotsAmountRe *regexp.Regexp
+	payoutRe      *regexp.Regexp
+	gameNames     []gameNamePattern
+	resultMarkers []*regexp.Regexp
+}
+
+// gameNamePattern matches any of the names of a game as whole words.
+type gameNamePattern struct {
+	game string
+	re   *regexp.Regexp
+}
+
+func DefaultDialect() *Dialect {
+	d := &Dialect{
+		Name: DefaultDialectName,
+		Balance: BalanceDialect{
+			Command: "!bombs",
+			Marker:  "bombs:",
+			Pattern: `(?i)\bbombs:\s*(\d+)`,
+		},
+		Slots: SlotsDialect{
+			Trigger:       "{user} pulls the lever and waits for the roll",
+			AmountPattern: `(?i)\b(?:won|wins)\s+(\d[\d ]*?)\s*bombs`,
+			Outcomes: []OutcomeRule{
+				{Outcome: OutcomeLost, Contains: []string{"you lost"}},
+				{Outcome: OutcomeSuperJackpot, Contains: []string{"super jackpot"}},
+				{Outcome: OutcomeJackpot, Contains: []string{"jackpot"}},
+				{Outcome: OutcomeSmallWin, Contains: []string{"even a small win is a win"}},
+				{Outcome: OutcomeRefund, Contains: []string{"you at least got your points back"}},
+				{Outcome: OutcomeRefund, Contains: []string{"he command is still on user cooldown for"}},
+			},
+		},
+		Points: PointsDialect{
+			Marker:  DefaultPointsMarker,
+			Pattern: DefaultPointsPattern,
+			Ignore:  []string{"top 5 users based on points"},
+		},
+		HeistResult:   []string{"Results from the Heist:"},
+		ArenaResult:   []string{"The dust finally settled"},
+		PayoutPattern: DefaultPayoutPattern,
+		Cooldown: []PhraseRule{
+			{UserAtStart: true, Contains: []string{"cooldown"}},
+		},
+		InsufficientFunds: []PhraseRule{
+			{Contains: []string{"{user}", "doesn't have", "bombs"}},
+		},
+		GameNames: map[string][]string{
+			"slots": {"slots", "slot"},
+			"heist": {"heist"},
+			"ffa":   {"ffa", "arena"},
+			"boss":  {"boss"},
+		},
+	}
+	_ = d.Compile()
+	return d
+}
+
+func BuiltinDialects() map[string]*Dialect {
+	return map[string]*Dialect{
+		DefaultDialectName: DefaultDialect(),
+	}
+}
+
+func (d *Dialect) Compile() error {
+	var problems []string
+
+	if d.Balance.Command == "" {
+		problems = append(problems, "balance.command is required")
+	}
+	if d.Balance.Marker == "" {
+		problems = append(problems, "balance.marker is required")
+	}
+	if d.Balance.Pattern == "" {
+		problems = append(problems, "balance.pattern is required")
+	} else {
+		re, err := regexp.Compile(d.Balance.Pattern)
+		switch {
+		case err != nil:
+			problems = append(problems, fmt.Sprintf("balance.pattern: %v", err))
+		case re.NumSubexp() < 1:
+			problems = append(problems, "balance.pattern must have a capture group for the amount")
+		default:
+			d.balanceRe = re
+		}
+	}
+
+	if d.Slots.Trigger == "" {
+		problems = append(problems, "slots.trigger is required")
+	}
+	d.slotsAmountRe = nil
+	if d.Slots.AmountPattern != "" {
+		re, err := regexp.Compile(d.Slots.AmountPattern)
+		switch {
+		case err != nil:
+			problems = append(problems, fmt.Sprintf("slots.amount_pattern: %v", err))
+		case re.NumSubexp() < 1:
+			problems = append(problems, "slots.amount_pattern must have a capture group for the amount")
+		default:
+			d.slotsAmountRe = re
+		}
+	}
+	for i, rule := range d.Slots.Outcomes {
+		if !rule.Outcome.Valid() {
+			problems = append(problems, fmt.Sprintf("slots.outcomes[%d]: unknown outcome %q", i, rule.Outcome))
+		}
+		if len(rule.Contains) == 0 {
+			problems = append(problems, fmt.Sprintf("slots.outcomes[%d]: contains is empty", i))
+		}
+	}
+	if d.Points.Marker == "" {
+		d.Points.Marker = DefaultPointsMarker
+	}
+	if d.Points.Pattern == "" {
+		d.Points.Pattern = DefaultPointsPattern
+	}
+	if re, err := regexp.Compile(expandUser(d.Points.Pattern, "user")); err != nil {
+		problems = append(problems, fmt.Sprintf("points.pattern: %v", err))
+	} else if re.NumSubexp() < 1 {
+		problems = append(problems, "points.pattern must have a capture group for the amount")
+	}
+	if len(d.HeistResult) == 0 {
+		problems = append(problems, "heist_result needs at least one marker")
+	}
+	if len(d.ArenaResult) == 0 {
+		problems = append(problems, "arena_result needs at least one marker")
+	}
+	payoutPattern := d.PayoutPattern
+	if payoutPattern == "" {
+		payoutPattern = DefaultPayoutPattern
+	}
+	re, err := regexp.Compile(payoutPattern)
+	switch {
+	case err != nil:
+		problems = append(problems, fmt.Sprintf("payout_pattern: %v", err))
+	case re.NumSubexp() < 2:
+		problems = append(problems, "payout_pattern must have capture groups for the user and the amount")
+	default:
+		d.payoutRe = re
+	}
+
+	d.resultMarkers = nil
+	for _, markers := range [][]string{d.HeistResult, d.ArenaResult, d.BossResult} {
+		for _, m := range markers {
+			if m != "" {
+				d.resultMarkers = append(d.resultMarkers, regexp.MustCompile("(?i)"+regexp.QuoteMeta(m)))
+			}
+		}
+	}
+
+	d.gameNames = nil
+	for _, game := range sortedKeys(d.GameNames) {
+		var quoted []string
+		for _, name := range d.GameNames[game] {
+			if name != "" {
+				quoted = append(quoted, regexp.QuoteMeta(name))
+			}
+		}
+		if len(quoted) > 0 {
+			re := regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`)
+			d.gameNames = append(d.gameNames, gameNamePattern{game: game, re: re})
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w %q: %s", ErrInvalidDialect, d.Name, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func (d *Dialect) IsBalanceReply(message, username string) bool {
+	lower := strings.ToLower(message)
+	return strings.Contains(lower, strings.ToLower(d.Balance.Marker)) &&
+		strings.Contains(lower, strings.ToLower(username))
+}
+
+func (d *Dialect) ParseBalance(message, username string) (int, bool) {
+	lower := strings.ToLower(message)
+	if !strings.Contains(lower, strings.ToLower(username)) {
+		return 0, false
+	}
+
+	re := d.balanceRe
+	if re == nil {
+		return 0, false
+	}
+
+	m := re.FindStringSubmatch(message)
+	if len(m) < 2 {
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(m[1])
+	if err != nil {
+		return 0, false
+	}
+
+	return n, true
+}
+
+func (d *Dialect) IsSlotsReply(message, username string) bool {
+	trigger := expandUser(d.Slots.Trigger, username)
+	return strings.HasPrefix(strings.ToLower(message), strings.ToLower(trigger))
+}
+
+func (d *Dialect) SlotsOutcomeOf(message, username string) (SlotsOutcome, bool) {
+	lower := strings.ToLower(message)
+	if !strings.Contains(lower, strings.ToLower(username)) {
+		return "", false
+	}
+
+	for _, rule := range d.Slots.Outcomes {
+		if containsAll(lower, rule.Contains, username) {
+			return rule.Outcome, true
+		}
+	}
+	return "", false
+}
+
+func (d *Dialect) IsHeistResult(message string) bool {
+	return containsAny(message, d.HeistResult)
+}
+
+func (d *Dialect) IsArenaResult(message string) bool {
+	return containsAny(message, d.ArenaResult)
+}
+
+func (d *Dialect) IsBossResult(message string) bool {
+	return containsAny(message, d.BossResult)
+}
+
+func (d *Dialect) IsCooldown(message, username string) bool {
+	return matchesAnyRule(message, username, d.Cooldown)
+}
+
+func (d *Dialect) IsInsufficientFunds(message, username string) bool {
+	return matchesAnyRule(message, username, d.InsufficientFunds)
+}
+
+func (d *Dialect) ConfirmedEntry(message, username string) (string, bool) {
+	for _, game := range sortedKeys(d.EntryConfirmed) {
+		if matchesAnyRule(message, username, d.EntryConfirmed[game]) {
+			return game, true
+		}
+	}
+	return "", false
+}
+
+// GamesIn returns the games named in message, in name order.
+func (d *Dialect) GamesIn(message string) []string {
+	var games []string
+	for _, p := range d.gameNames {
+		if p.re.MatchString(message) {
+			games = append(games, p.game)
+		}
+	}
+	return games
+}
+
+func (o SlotsOutcome) Valid() bool {
+	switch o {
+	case OutcomeLost, OutcomeRefund, OutcomeSmallWin, OutcomeJackpot, OutcomeSuperJackpot:
+		return true
+	default:
+		return false
+	}
+}
+
+func matchesAnyRule(message, username string, rules []PhraseRule) bool {
+	lower := strings.ToLower(message)
+	userLower := strings.ToLower(username)
+
+	for _, rule := range rules {
+		if rule.UserAtStart && !strings.HasPrefix(lower, userLower) {
+			continue
+		}
+		if len(rule.Contains) == 0 {
+			continue
+		}
+		if containsAll(lower, rule.Contains, username) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAll(lower string, phrases []string, username string) bool {
+	for _, p := range phrases {
+		if !strings.Contains(lower, strings.ToLower(expandUser(p, username))) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsAny(message string, markers []string) bool {
+	lower := strings.ToLower(message)
+	for _, m := range markers {
+		if m != "" && strings.Contains(lower, strings.ToLower(m)) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func expandUser(s, username string) string {
+	return strings.ReplaceAll(s, UserPlaceholder, username)
+}
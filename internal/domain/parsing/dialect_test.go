@@ -0,0 +1,155 @@
+package parsing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultDialectCompiles(t *testing.T) {
+	t.Parallel()
+
+	d := DefaultDialect()
+	require.NoError(t, d.Compile(), "default dialect should compile")
+	assert.Equal(t, DefaultDialectName, d.Name, "default dialect name")
+}
+
+func TestDialectCompileErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		mutate func(d *Dialect)
+	}{
+		{"missing balance command", func(d *Dialect) { d.Balance.Command = "" }},
+		{"missing balance marker", func(d *Dialect) { d.Balance.Marker = "" }},
+		{"missing balance pattern", func(d *Dialect) { d.Balance.Pattern = "" }},
+		{"invalid balance pattern", func(d *Dialect) { d.Balance.Pattern = "(" }},
+		{"balance pattern without group", func(d *Dialect) { d.Balance.Pattern = `bombs:\s*\d+` }},
+		{"missing slots trigger", func(d *Dialect) { d.Slots.Trigger = "" }},
+		{"unknown outcome", func(d *Dialect) { d.Slots.Outcomes = []OutcomeRule{{Outcome: "mega", Contains: []string{"x"}}} }},
+		{"empty outcome phrases", func(d *Dialect) { d.Slots.Outcomes = []OutcomeRule{{Outcome: OutcomeLost}} }},
+		{"no heist markers", func(d *Dialect) { d.HeistResult = nil }},
+		{"no arena markers", func(d *Dialect) { d.ArenaResult = nil }},
+		{"invalid payout pattern", func(d *Dialect) { d.PayoutPattern = "(" }},
+		{"payout pattern without amount", func(d *Dialect) { d.PayoutPattern = `(\w+) won` }},
+		{"invalid points pattern", func(d *Dialect) { d.Points.Pattern = "{user} (" }},
+		{"points pattern without amount", func(d *Dialect) { d.Points.Pattern = `{user} \(\d+\)` }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := DefaultDialect()
+			tt.mutate(d)
+			require.ErrorIs(t, d.Compile(), ErrInvalidDialect, "Compile() should reject dialect")
+		})
+	}
+}
+
+func TestDialectMatchers(t *testing.T) {
+	t.Parallel()
+
+	d := DefaultDialect()
+
+	assert.True(t, d.IsBalanceReply("testuser bombs: 100", "testuser"), "balance reply")
+	assert.False(t, d.IsBalanceReply("otheruser bombs: 100", "testuser"), "balance reply for other user")
+	assert.True(t, d.IsSlotsReply("TestUser pulls the lever and waits for the roll...", "testuser"), "slots reply")
+	assert.False(t, d.IsSlotsReply("otheruser pulls the lever and waits for the roll", "testuser"), "slots reply for other user")
+	assert.True(t, d.IsHeistResult("Results from the Heist: testuser (100)"), "heist result")
+	assert.True(t, d.IsArenaResult("The dust finally settled, testuser (100)"), "arena result")
+	assert.False(t, d.IsBossResult("The boss has been defeated! testuser (100)"), "no boss result markers by default")
+	assert.True(t, d.IsHeistResult("RESULTS FROM THE HEIST: testuser (100)"), "result markers ignore case")
+	assert.True(t, d.IsPointsReply("TestUser (2 000)", "testuser"), "points reply")
+	assert.False(t, d.IsPointsReply("top 5 users based on points: testuser (1000)", "testuser"), "leaderboard is not a points reply")
+	assert.True(t, d.IsCooldown("testuser is on cooldown", "testuser"), "cooldown")
+	assert.True(t, d.IsInsufficientFunds("testuser doesn't have enough bombs", "testuser"), "insufficient funds")
+}
+
+func TestCustomDialect(t *testing.T) {
+	t.Parallel()
+
+	d := &Dialect{
+		Name:    "custom",
+		Balance: BalanceDialect{Command: "!punkty", Marker: "punkty:", Pattern: `punkty:\s*(\d+)`},
+		Slots: SlotsDialect{
+			Trigger: "{user} kręci bębnami",
+			Outcomes: []OutcomeRule{
+				{Outcome: OutcomeLost, Contains: []string{"przegrałeś"}},
+				{Outcome: OutcomeJackpot, Contains: []string{"{user}", "wielka wygrana"}},
+			},
+		},
+		HeistResult: []string{"Wyniki skoku:"},
+		ArenaResult: []string{"Arena zakończona"},
+		Cooldown:    []PhraseRule{{UserAtStart: true, Contains: []string{"poczekaj"}}},
+		InsufficientFunds: []PhraseRule{
+			{Contains: []string{"{user}", "za mało punktów"}},
+		},
+	}
+	require.NoError(t, d.Compile(), "custom dialect should compile")
+
+	n, ok := d.ParseBalance("gracz punkty: 4200", "gracz")
+	assert.True(t, ok, "ParseBalance ok")
+	assert.Equal(t, 4200, n, "ParseBalance value")
+
+	assert.True(t, d.IsSlotsReply("gracz kręci bębnami: wielka wygrana", "gracz"), "slots reply")
+	outcome, ok := d.SlotsOutcomeOf("gracz kręci bębnami: wielka wygrana", "gracz")
+	assert.True(t, ok, "SlotsOutcomeOf ok")
+	assert.Equal(t, OutcomeJackpot, outcome, "SlotsOutcomeOf outcome")
+
+	assert.True(t, d.IsCooldown("gracz, poczekaj chwilę", "gracz"), "cooldown")
+	assert.False(t, d.IsCooldown("inny, poczekaj chwilę", "gracz"), "cooldown for other user")
+	assert.True(t, d.IsInsufficientFunds("gracz ma za mało punktów", "gracz"), "insufficient funds")
+	assert.False(t, d.IsHeistResult("Results from the Heist: gracz (10)"), "default heist marker not used")
+
+	assert.True(t, d.IsPointsReply("gracz (100)", "gracz"), "empty points section uses the default format")
+	d.Points = PointsDialect{Marker: "{user} ma", Pattern: `(?i){user} ma (\d+) punktów`}
+	require.NoError(t, d.Compile(), "custom points format")
+	assert.True(t, d.IsPointsReply("Gracz ma 300 punktów", "gracz"), "custom points reply")
+	n, ok = d.ParsePoints("Gracz ma 300 punktów", "gracz")
+	assert.True(t, ok, "ParsePoints ok")
+	assert.Equal(t, 300, n, "ParsePoints value")
+}
+
+func TestDialectConfirmedEntry(t *testing.T) {
+	t.Parallel()
+
+	d := DefaultDialect()
+	_, ok := d.ConfirmedEntry("testuser joined the heist", "testuser")
+	assert.False(t, ok, "default dialect has no entry confirmations")
+
+	d.EntryConfirmed = map[string][]PhraseRule{
+		"heist": {{UserAtStart: true, Contains: []string{"joined the heist"}}},
+		"ffa":   {{Contains: []string{"{user}", "enters the arena"}}},
+	}
+
+	game, ok := d.ConfirmedEntry("testuser joined the heist with 1000 bombs", "testuser")
+	assert.True(t, ok, "heist entry confirmed")
+	assert.Equal(t, "heist", game, "heist game")
+
+	game, ok = d.ConfirmedEntry("Look! testuser enters the arena", "testuser")
+	assert.True(t, ok, "ffa entry confirmed")
+	assert.Equal(t, "ffa", game, "ffa game")
+
+	_, ok = d.ConfirmedEntry("otheruser joined the heist", "testuser")
+	assert.False(t, ok, "other user's entry")
+}
+
+func TestDialectGamesIn(t *testing.T) {
+	t.Parallel()
+
+	d := DefaultDialect()
+
+	assert.Equal(t, []string{"heist"}, d.GamesIn("testuser, the Heist is on cooldown"), "heist named")
+	assert.Equal(t, []string{"ffa"}, d.GamesIn("testuser, the arena is on cooldown"), "arena is the ffa")
+	assert.Empty(t, d.GamesIn("testuser doesn't have enough bombs"), "no game named")
+	assert.Empty(t, d.GamesIn("ask bossbot later"), "names match whole words")
+
+	d.GameNames = map[string][]string{"heist": {"napad"}, "ffa": {"arena.pl"}}
+	require.NoError(t, d.Compile(), "Compile()")
+	assert.Equal(t, []string{"heist"}, d.GamesIn("Napad jeszcze trwa"), "custom names compiled")
+	assert.Empty(t, d.GamesIn("the heist is on cooldown"), "replaced names")
+	assert.Empty(t, d.GamesIn("arena-pl"), "names are matched literally")
+}
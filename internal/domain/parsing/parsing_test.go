@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestParseBombs(t *testing.T) {
@@ -39,34 +40,73 @@ func TestParseSlotsDelta(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
-		name        string
-		message     string
-		username    string
-		wantDelta   int
-		wantOutcome SlotsOutcome
-		wantOK      bool
+		name         string
+		message      string
+		username     string
+		cost         int
+		wantDelta    int
+		wantOutcome  SlotsOutcome
+		wantObserved bool
+		wantOK       bool
 	}{
-		{"lost", "testuser you lost everything", "testuser", 0, OutcomeLost, true},
-		{"refund", "testuser you at least got your points back", "testuser", 2000, OutcomeRefund, true},
-		{"small win", "testuser even a small win is a win", "testuser", 4000, OutcomeSmallWin, true},
-		{"jackpot", "testuser hit the jackpot!", "testuser", 15000, OutcomeJackpot, true},
-		{"super jackpot", "testuser hit the SUPER JACKPOT!", "testuser", 60000, OutcomeSuperJackpot, true},
-		{"wrong user", "otheruser you lost", "testuser", 0, "", false},
-		{"unknown", "testuser something random", "testuser", 0, "", false},
+		{"lost", "testuser you lost everything", "testuser", 2000, 0, OutcomeLost, false, true},
+		{"refund", "testuser you at least got your points back", "testuser", 2000, 2000, OutcomeRefund, false, true},
+		{"small win", "testuser even a small win is a win", "testuser", 2000, 4000, OutcomeSmallWin, false, true},
+		{"jackpot", "testuser hit the jackpot!", "testuser", 2000, 15000, OutcomeJackpot, false, true},
+		{"super jackpot", "testuser hit the SUPER JACKPOT!", "testuser", 2000, 60000, OutcomeSuperJackpot, false, true},
+		{"cost scales payout", "testuser hit the jackpot!", "testuser", 1000, 7500, OutcomeJackpot, false, true},
+		{"observed amount", "testuser hit the jackpot and won 12 345 bombs!", "testuser", 2000, 12345, OutcomeJackpot, true, true},
+		{"observed wins", "testuser even a small win is a win, wins 3000 bombs", "testuser", 2000, 3000, OutcomeSmallWin, true, true},
+		{"wrong user", "otheruser you lost", "testuser", 2000, 0, "", false, false},
+		{"unknown", "testuser something random", "testuser", 2000, 0, "", false, false},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			result, ok := ParseSlotsDelta(tt.message, tt.username)
+			result, ok := ParseSlotsDelta(tt.message, tt.username, DefaultSlotsPricing(tt.cost))
 			assert.Equal(t, tt.wantOK, ok, "ParseSlotsDelta() ok")
 			assert.Equal(t, tt.wantDelta, result.Delta, "ParseSlotsDelta() delta")
 			assert.Equal(t, tt.wantOutcome, result.Outcome, "ParseSlotsDelta() outcome")
+			assert.Equal(t, tt.wantObserved, result.Observed, "ParseSlotsDelta() observed")
 		})
 	}
 }
 
+func TestParseSlotsDeltaCustomPayouts(t *testing.T) {
+	t.Parallel()
+
+	pricing := SlotsPricing{Cost: 500, Payouts: PayoutTable{OutcomeJackpot: 10}}
+
+	result, ok := ParseSlotsDelta("testuser hit the jackpot!", "testuser", pricing)
+	assert.True(t, ok, "ParseSlotsDelta() ok")
+	assert.Equal(t, 5000, result.Delta, "custom multiplier")
+
+	result, ok = ParseSlotsDelta("testuser even a small win is a win", "testuser", pricing)
+	assert.True(t, ok, "ParseSlotsDelta() ok")
+	assert.Equal(t, 1000, result.Delta, "missing outcome falls back to default multiplier")
+}
+
+func TestParsePayoutTable(t *testing.T) {
+	t.Parallel()
+
+	table, err := ParsePayoutTable("")
+	require.NoError(t, err, "empty spec")
+	assert.Equal(t, DefaultPayoutTable(), table, "empty spec returns defaults")
+
+	table, err = ParsePayoutTable("jackpot=10, SUPER_JACKPOT = 50")
+	require.NoError(t, err, "valid spec")
+	assert.InDelta(t, 10.0, table[OutcomeJackpot], 0.0001, "jackpot multiplier")
+	assert.InDelta(t, 50.0, table[OutcomeSuperJackpot], 0.0001, "super jackpot multiplier")
+	assert.InDelta(t, 1.0, table[OutcomeRefund], 0.0001, "refund keeps default")
+
+	for _, spec := range []string{"jackpot", "mega=2", "jackpot=abc", "jackpot=-1"} {
+		_, err := ParsePayoutTable(spec)
+		assert.Error(t, err, "ParsePayoutTable(%q) should fail", spec)
+	}
+}
+
 func TestParsePoints(t *testing.T) {
 	t.Parallel()
 
@@ -83,6 +123,8 @@ func TestParsePoints(t *testing.T) {
 		{"in context", "Results: testuser (500) won", "testuser", 500, true},
 		{"wrong user", "otheruser (1234)", "testuser", 0, false},
 		{"no parens", "testuser 1234", "testuser", 0, false},
+		{"case-insensitive user", "TestUser (1 000)", "testuser", 1000, true},
+		{"name inside another name", "xtestuser (1234)", "testuser", 0, false},
 		{"top 5 filtered", "top 5 users based on points: testuser (1000)", "testuser", 0, false},
 	}
 
@@ -128,7 +170,7 @@ func FuzzParseSlotsDelta(f *testing.F) {
 	f.Add("", "")
 
 	f.Fuzz(func(t *testing.T, message, username string) {
-		ParseSlotsDelta(message, username)
+		ParseSlotsDelta(message, username, DefaultSlotsPricing(2000))
 	})
 }
 
@@ -11,8 +11,27 @@ const maxPointsStringLen = 20
 
 const maxMessageLen = 1000
 
+// ParsePoints parses a points reply of the default dialect.
 func ParsePoints(message, username string) (int, bool) {
-	if strings.Contains(message, "top 5 users based on points") {
+	return defaultDialect.ParsePoints(message, username)
+}
+
+// IsPointsReply reports whether message is a points reply for username: it
+// has the points marker and none of the ignored phrases.
+func (d *Dialect) IsPointsReply(message, username string) bool {
+	marker := d.Points.Marker
+	if marker == "" {
+		marker = DefaultPointsMarker
+	}
+	lower := strings.ToLower(message)
+	if !strings.Contains(lower, strings.ToLower(expandUser(marker, username))) {
+		return false
+	}
+	return !containsAny(message, d.Points.Ignore)
+}
+
+func (d *Dialect) ParsePoints(message, username string) (int, bool) {
+	if containsAny(message, d.Points.Ignore) {
 		return 0, false
 	}
 
@@ -24,14 +43,17 @@ func ParsePoints(message, username string) (int, bool) {
 		return 0, false
 	}
 
-	pattern := `^.*` + regexp.QuoteMeta(username) + `\s*\(\s*([\d\s]+)\).*`
-	re, err := regexp.Compile(pattern)
+	pattern := d.Points.Pattern
+	if pattern == "" {
+		pattern = DefaultPointsPattern
+	}
+	re, err := regexp.Compile(expandUser(pattern, regexp.QuoteMeta(username)))
 	if err != nil {
 		return 0, false
 	}
 
 	matches := re.FindStringSubmatch(message)
-	if len(matches) != 2 {
+	if len(matches) < 2 {
 		return 0, false
 	}
 
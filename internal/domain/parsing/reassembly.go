@@ -0,0 +1,224 @@
+package parsing
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// danglingNameRe is a name after the last participant's separator,
+	// firstNameRe the first participant right after the result marker.
+	danglingNameRe = regexp.MustCompile(`^\s*,\s*\w+\s*,?\s*$`)
+	firstNameRe    = regexp.MustCompile(`^\s*\w+\s*,?\s*$`)
+	bareNameRe     = regexp.MustCompile(`^\w+\s*,?$`)
+)
+
+// IsIncomplete reports whether a game result stops in the middle of its
+// participant list: it ends with a separator or with a name whose amount was
+// pushed into the next message. A word closing a complete list without a
+// separator, e.g. "a (100) gg", does not count as a name.
+func (d *Dialect) IsIncomplete(message string) bool {
+	tail, afterPayout := message, false
+	if d.payoutRe != nil {
+		if all := d.payoutRe.FindAllStringIndex(message, -1); len(all) > 0 {
+			tail, afterPayout = message[all[len(all)-1][1]:], true
+		} else {
+			tail = d.afterResultMarker(message)
+		}
+	}
+	if strings.TrimSpace(tail) == "," {
+		return true
+	}
+	if afterPayout {
+		return danglingNameRe.MatchString(tail)
+	}
+	return firstNameRe.MatchString(tail)
+}
+
+// IsContinuation reports whether a message looks like the next fragment of a
+// split participant list.
+func (d *Dialect) IsContinuation(message string) bool {
+	trimmed := strings.TrimLeft(message, " ,")
+	if trimmed == "" {
+		return false
+	}
+	if trimmed[0] == '(' || bareNameRe.MatchString(trimmed) {
+		return true
+	}
+	if d.payoutRe == nil {
+		return false
+	}
+	loc := d.payoutRe.FindStringIndex(trimmed)
+	return loc != nil && loc[0] == 0
+}
+
+func (d *Dialect) afterResultMarker(message string) string {
+	for _, re := range d.resultMarkers {
+		if loc := re.FindStringIndex(message); loc != nil {
+			message = message[loc[1]:]
+		}
+	}
+	if i := strings.LastIndex(message, ":"); i >= 0 {
+		message = message[i+1:]
+	}
+	return message
+}
+
+// Fragment is one chat message that is part of a longer boss bot message.
+type Fragment struct {
+	Sender  string
+	Channel string
+	Kind    string
+	Text    string
+}
+
+// Reassembled is a message joined from its fragments. Complete is false when
+// the message timed out before its last fragment arrived.
+type Reassembled struct {
+	Fragment
+	Parts    int
+	Complete bool
+}
+
+type partial struct {
+	first Fragment
+	parts []string
+	last  time.Time
+}
+
+// Reassembler joins messages the boss bot splits over several chat lines. It
+// keeps one open message per sender and kind; lines that do not continue an
+// open message are left to the caller, so unrelated chat may interleave.
+type Reassembler struct {
+	mu         sync.Mutex
+	timeout    time.Duration
+	now        func() time.Time
+	incomplete func(text string) bool
+	onComplete func(Reassembled)
+	open       []*partial
+}
+
+// NewReassembler returns a reassembler that calls onComplete once incomplete
+// no longer holds for the joined text, or when no fragment arrived for timeout.
+func NewReassembler(timeout time.Duration, incomplete func(text string) bool, onComplete func(Reassembled)) *Reassembler {
+	return &Reassembler{
+		timeout:    timeout,
+		now:        time.Now,
+		incomplete: incomplete,
+		onComplete: onComplete,
+	}
+}
+
+func (r *Reassembler) SetClock(now func() time.Time) {
+	r.mu.Lock()
+	r.now = now
+	r.mu.Unlock()
+}
+
+// Start opens a new message with its first fragment. An open message of the
+// same sender and kind is flushed as incomplete.
+func (r *Reassembler) Start(f Fragment) {
+	done := r.expired()
+
+	r.mu.Lock()
+	for i, p := range r.open {
+		if strings.EqualFold(p.first.Sender, f.Sender) && p.first.Kind == f.Kind {
+			done = append(done, p.result(false))
+			r.removeAt(i)
+			break
+		}
+	}
+	r.open = append(r.open, &partial{first: f, parts: []string{f.Text}, last: r.now()})
+	r.mu.Unlock()
+
+	r.emit(done)
+}
+
+// Continue appends a fragment to the open message of its sender and kind and
+// reports whether there was one. A fragment without a kind only continues the
+// sender's single open message: with messages of several kinds open it could
+// belong to any of them and is left to the caller.
+func (r *Reassembler) Continue(f Fragment) bool {
+	done := r.expired()
+
+	r.mu.Lock()
+	found := -1
+	for i, p := range r.open {
+		if !strings.EqualFold(p.first.Sender, f.Sender) || (f.Kind != "" && p.first.Kind != f.Kind) {
+			continue
+		}
+		if found >= 0 {
+			found = -1
+			break
+		}
+		found = i
+	}
+	if found >= 0 {
+		p := r.open[found]
+		p.parts = append(p.parts, f.Text)
+		p.last = r.now()
+		if !r.incomplete(p.text()) {
+			done = append(done, p.result(true))
+			r.removeAt(found)
+		}
+	}
+	r.mu.Unlock()
+
+	r.emit(done)
+	return found >= 0
+}
+
+// Expire flushes every open message that received no fragment within the
+// timeout.
+func (r *Reassembler) Expire() {
+	r.emit(r.expired())
+}
+
+func (r *Reassembler) Pending() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.open)
+}
+
+func (r *Reassembler) expired() []Reassembled {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	var done []Reassembled
+	kept := r.open[:0]
+	for _, p := range r.open {
+		if now.Sub(p.last) > r.timeout {
+			done = append(done, p.result(false))
+			continue
+		}
+		kept = append(kept, p)
+	}
+	r.open = kept
+	return done
+}
+
+func (r *Reassembler) emit(done []Reassembled) {
+	if r.onComplete == nil {
+		return
+	}
+	for _, m := range done {
+		r.onComplete(m)
+	}
+}
+
+func (r *Reassembler) removeAt(i int) {
+	r.open = append(r.open[:i], r.open[i+1:]...)
+}
+
+func (p *partial) text() string {
+	return strings.Join(p.parts, " ")
+}
+
+func (p *partial) result(complete bool) Reassembled {
+	f := p.first
+	f.Text = p.text()
+	return Reassembled{Fragment: f, Parts: len(p.parts), Complete: complete}
+}
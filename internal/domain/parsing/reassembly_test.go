@@ -0,0 +1,147 @@
+package parsing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsIncomplete(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		message string
+		want    bool
+	}{
+		{"complete list", "Results from the Heist: testuser (100), otheruser (200)", false},
+		{"dangling name", "The dust finally settled, winners: otheruser (900), testuser", true},
+		{"trailing separator", "Results from the Heist: testuser (100),", true},
+		{"first name dangling", "The dust finally settled, winners: testuser", true},
+		{"marker in another case", "THE DUST FINALLY SETTLED testuser", true},
+		{"nobody paid", "The dust finally settled, nobody made it out", false},
+		{"closing sentence", "Results from the Heist: testuser (100). Well done!", false},
+		{"continuation still dangling", "(4 500), third (100), fourth", true},
+		{"complete list closed by a word", "Results from the Heist: testuser (100), otheruser (200) gg", false},
+		{"name without a separator", "The dust finally settled, winners: testuser (900) congrats", false},
+	}
+
+	d := DefaultDialect()
+	require.NoError(t, d.Compile(), "Compile()")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, d.IsIncomplete(tt.message), "IsIncomplete(%q)", tt.message)
+		})
+	}
+}
+
+func TestIsContinuation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		message string
+		want    bool
+	}{
+		{"(4 500)", true},
+		{"(4 500), third (100), fourth", true},
+		{", third (100)", true},
+		{"third (100)", true},
+		{"fourth", true},
+		{"testuser bombs: 100", false},
+		{"Type !ffa to start!", false},
+		{"", false},
+	}
+
+	d := DefaultDialect()
+	require.NoError(t, d.Compile(), "Compile()")
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, d.IsContinuation(tt.message), "IsContinuation(%q)", tt.message)
+		})
+	}
+}
+
+type reassemblyRecorder struct {
+	done []Reassembled
+}
+
+func newTestReassembler(now *time.Time) (*Reassembler, *reassemblyRecorder) {
+	d := DefaultDialect()
+	rec := &reassemblyRecorder{}
+	r := NewReassembler(5*time.Second, d.IsIncomplete, func(m Reassembled) {
+		rec.done = append(rec.done, m)
+	})
+	r.SetClock(func() time.Time { return *now })
+	return r, rec
+}
+
+func TestReassemblerJoinsManyParts(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	r, rec := newTestReassembler(&now)
+
+	r.Start(Fragment{Sender: "bossbot", Channel: "chan", Kind: "heist", Text: "Results from the Heist: a (1), testuser"})
+	assert.False(t, r.Continue(Fragment{Sender: "viewer", Text: "(5)"}), "other senders do not continue the message")
+	require.True(t, r.Continue(Fragment{Sender: "bossbot", Text: "(2 000), b (3), c"}), "second part")
+	assert.Empty(t, rec.done, "still waiting for the last part")
+	require.True(t, r.Continue(Fragment{Sender: "BossBot", Text: "(4)"}), "last part")
+
+	require.Len(t, rec.done, 1, "one message completed")
+	m := rec.done[0]
+	assert.True(t, m.Complete, "Complete")
+	assert.Equal(t, 3, m.Parts, "Parts")
+	assert.Equal(t, "heist", m.Kind, "Kind")
+	assert.Equal(t, "chan", m.Channel, "Channel")
+	assert.Equal(t, "Results from the Heist: a (1), testuser (2 000), b (3), c (4)", m.Text, "joined text")
+	assert.Zero(t, r.Pending(), "nothing left open")
+}
+
+func TestReassemblerExpiresIncompleteMessages(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	r, rec := newTestReassembler(&now)
+
+	r.Start(Fragment{Sender: "bossbot", Kind: "ffa", Text: "The dust finally settled, winners: testuser"})
+	now = now.Add(4 * time.Second)
+	r.Expire()
+	assert.Empty(t, rec.done, "not expired yet")
+
+	now = now.Add(2 * time.Second)
+	r.Expire()
+	require.Len(t, rec.done, 1, "expired message flushed")
+	assert.False(t, rec.done[0].Complete, "flushed incomplete")
+	assert.False(t, r.Continue(Fragment{Sender: "bossbot", Text: "(100)"}), "late fragment has nothing to continue")
+}
+
+func TestReassemblerRestartFlushesSameKind(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	r, rec := newTestReassembler(&now)
+
+	r.Start(Fragment{Sender: "bossbot", Kind: "heist", Text: "Results from the Heist: a"})
+	r.Start(Fragment{Sender: "bossbot", Kind: "ffa", Text: "The dust finally settled, winners: b"})
+	assert.Empty(t, rec.done, "different kinds stay open side by side")
+	assert.Equal(t, 2, r.Pending(), "two open messages")
+
+	r.Start(Fragment{Sender: "bossbot", Kind: "heist", Text: "Results from the Heist: c"})
+	require.Len(t, rec.done, 1, "old heist flushed")
+	assert.Equal(t, "Results from the Heist: a", rec.done[0].Text, "flushed text")
+	assert.False(t, rec.done[0].Complete, "flushed incomplete")
+
+	assert.False(t, r.Continue(Fragment{Sender: "bossbot", Text: "(7)"}), "fragment of unknown kind is ambiguous")
+	require.True(t, r.Continue(Fragment{Sender: "bossbot", Kind: "ffa", Text: "(7)"}), "continues the message of its kind")
+	require.Len(t, rec.done, 2, "ffa completed")
+	assert.Equal(t, "The dust finally settled, winners: b (7)", rec.done[1].Text, "ffa joined with its fragment")
+	assert.Equal(t, 1, r.Pending(), "heist still open")
+}
@@ -0,0 +1,125 @@
+package parsing
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Payout is one participant of a game result and the amount the boss bot
+// paid them.
+type Payout struct {
+	User   string
+	Amount int
+}
+
+// GameResult lists everyone the boss bot paid out after a heist, arena or
+// boss fight, in the order it announced them. Participants missing from it
+// lost.
+type GameResult struct {
+	Payouts []Payout
+}
+
+// Survivors is the number of participants that got paid.
+func (r GameResult) Survivors() int {
+	return len(r.Payouts)
+}
+
+func (r GameResult) Total() int {
+	total := 0
+	for _, p := range r.Payouts {
+		total += p.Amount
+	}
+	return total
+}
+
+// PayoutOf returns what username was paid, and false when they lost or did
+// not take part.
+func (r GameResult) PayoutOf(username string) (int, bool) {
+	if i := r.PlacementOf(username); i > 0 {
+		return r.Payouts[i-1].Amount, true
+	}
+	return 0, false
+}
+
+// PlacementOf returns the 1-based position of username among the paid
+// participants, or 0 when they are not listed.
+func (r GameResult) PlacementOf(username string) int {
+	for i, p := range r.Payouts {
+		if strings.EqualFold(p.User, username) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// MentionsUser reports whether a game result names username as a whole word,
+// whether or not ParsePayouts can read an amount for them.
+func (d *Dialect) MentionsUser(message, username string) bool {
+	if username == "" {
+		return false
+	}
+	for i := 0; i+len(username) <= len(message); i++ {
+		end := i + len(username)
+		if strings.EqualFold(message[i:end], username) && !isWordByte(message, i-1) && !isWordByte(message, end) {
+			return true
+		}
+	}
+	return false
+}
+
+// isWordByte reports whether s[i] is a regexp \w character; out of range is
+// a word boundary.
+func isWordByte(s string, i int) bool {
+	if i < 0 || i >= len(s) {
+		return false
+	}
+	c := s[i]
+	return c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// ParseHeistResult returns every participant of a heist result message.
+func (d *Dialect) ParseHeistResult(message string) (GameResult, bool) {
+	if !d.IsHeistResult(message) {
+		return GameResult{}, false
+	}
+	return GameResult{Payouts: d.ParsePayouts(message)}, true
+}
+
+// ParseArenaResult returns the winners of an arena (ffa) result message.
+func (d *Dialect) ParseArenaResult(message string) (GameResult, bool) {
+	if !d.IsArenaResult(message) {
+		return GameResult{}, false
+	}
+	return GameResult{Payouts: d.ParsePayouts(message)}, true
+}
+
+// ParseBossResult returns the rewarded participants of a boss fight result
+// message.
+func (d *Dialect) ParseBossResult(message string) (GameResult, bool) {
+	if !d.IsBossResult(message) {
+		return GameResult{}, false
+	}
+	return GameResult{Payouts: d.ParsePayouts(message)}, true
+}
+
+// ParsePayouts returns every "user (amount)" participant of a game result
+// message, in order.
+func (d *Dialect) ParsePayouts(message string) []Payout {
+	re := d.payoutRe
+	if re == nil || len(message) > maxMessageLen {
+		return nil
+	}
+
+	var payouts []Payout
+	for _, m := range re.FindAllStringSubmatch(message, -1) {
+		if len(m) < 3 || len(m[2]) > maxPointsStringLen {
+			continue
+		}
+		amount, err := strconv.Atoi(strings.ReplaceAll(strings.TrimSpace(m[2]), " ", ""))
+		if err != nil {
+			continue
+		}
+		payouts = append(payouts, Payout{User: m[1], Amount: amount})
+	}
+	return payouts
+}
@@ -0,0 +1,139 @@
+package parsing
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHeistResult(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		message string
+		want    []Payout
+		wantOK  bool
+	}{
+		{
+			"every participant",
+			"Results from the Heist: testuser (3 000), otheruser (100), third_user ( 42 )",
+			[]Payout{{"testuser", 3000}, {"otheruser", 100}, {"third_user", 42}},
+			true,
+		},
+		{"single survivor", "Results from the Heist: otheruser (500)", []Payout{{"otheruser", 500}}, true},
+		{"everyone caught", "Results from the Heist: nobody survived", nil, true},
+		{"not a heist", "The dust finally settled, testuser (100)", nil, false},
+	}
+
+	d := DefaultDialect()
+	require.NoError(t, d.Compile(), "Compile()")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := d.ParseHeistResult(tt.message)
+			assert.Equal(t, tt.wantOK, ok, "ParseHeistResult() ok")
+			assert.Equal(t, tt.want, got.Payouts, "ParseHeistResult() payouts")
+		})
+	}
+}
+
+func TestParseArenaAndBossResult(t *testing.T) {
+	t.Parallel()
+
+	d := DefaultDialect()
+	require.NoError(t, d.Compile(), "Compile()")
+
+	arena, ok := d.ParseArenaResult("The dust finally settled, winners: otheruser (900), testuser (4 500)")
+	require.True(t, ok, "ParseArenaResult() ok")
+	assert.Equal(t, []Payout{{"otheruser", 900}, {"testuser", 4500}}, arena.Payouts, "arena winners")
+	assert.Equal(t, 2, arena.PlacementOf("testuser"), "arena placement")
+
+	_, ok = d.ParseArenaResult("Results from the Heist: testuser (100)")
+	assert.False(t, ok, "heist is not an arena result")
+
+	d.BossResult = []string{"The boss has been defeated", "The boss fight is over"}
+	require.NoError(t, d.Compile(), "Compile() with boss result markers")
+	boss, ok := d.ParseBossResult("The boss has been defeated! Rewards: testuser (2 000), otheruser (2 000)")
+	require.True(t, ok, "ParseBossResult() ok")
+	assert.Equal(t, 4000, boss.Total(), "boss total payout")
+
+	lost, ok := d.ParseBossResult("The boss fight is over, the boss wiped out everyone")
+	require.True(t, ok, "lost boss fight is a result")
+	assert.Zero(t, lost.Survivors(), "nobody paid")
+}
+
+// TestDefaultDialectOnCapturedLines reads boss bot lines as recorded in
+// internal/replay/testdata/session.jsonl.
+func TestDefaultDialectOnCapturedLines(t *testing.T) {
+	t.Parallel()
+
+	d := DefaultDialect()
+	require.NoError(t, d.Compile(), "Compile()")
+
+	heist, ok := d.ParseHeistResult("Results from the Heist: otheruser (1 500), testuser (2 000)")
+	require.True(t, ok, "heist result")
+	assert.Equal(t, []Payout{{"otheruser", 1500}, {"testuser", 2000}}, heist.Payouts, "heist payouts")
+
+	arena := "The dust finally settled, winners: otheruser (900), testuser"
+	assert.True(t, d.IsArenaResult(arena), "arena result")
+	assert.True(t, d.IsIncomplete(arena), "arena result split before our payout")
+
+	for _, line := range []string{
+		"testuser bombs: 20000",
+		"The cops have given up! If you want to get a team together type !heist",
+		"Results from the Heist: otheruser (1 500), testuser (2 000)",
+		"testuser pulls the lever and waits for the roll... testuser hit the jackpot!",
+		"Type !ffa to start!",
+		arena,
+		"(4 500)",
+		"testuser, the heist is on cooldown",
+	} {
+		assert.False(t, d.IsBossResult(line), "not a boss result: %q", line)
+	}
+}
+
+func TestGameResultAccessors(t *testing.T) {
+	t.Parallel()
+
+	r := GameResult{Payouts: []Payout{{"TestUser", 3000}, {"otheruser", 100}}}
+
+	assert.Equal(t, 2, r.Survivors(), "Survivors()")
+	assert.Equal(t, 3100, r.Total(), "Total()")
+
+	payout, ok := r.PayoutOf("testuser")
+	assert.True(t, ok, "PayoutOf() matches case-insensitively")
+	assert.Equal(t, 3000, payout, "PayoutOf() amount")
+
+	_, ok = r.PayoutOf("caught")
+	assert.False(t, ok, "PayoutOf() for a caught participant")
+
+	assert.Equal(t, 2, r.PlacementOf("otheruser"), "PlacementOf()")
+	assert.Zero(t, r.PlacementOf("caught"), "PlacementOf() for a caught participant")
+}
+
+func TestMentionsUser(t *testing.T) {
+	t.Parallel()
+
+	d := DefaultDialect()
+
+	assert.True(t, d.MentionsUser("Results from the Heist: TestUser (lots)", "testuser"), "named without a readable amount")
+	assert.False(t, d.MentionsUser("Results from the Heist: testuser2 (100)", "testuser"), "other user with the name as prefix")
+	assert.False(t, d.MentionsUser("Results from the Heist: my_testuser (100)", "testuser"), "other user with the name as suffix")
+	assert.True(t, d.MentionsUser("testuser", "testuser"), "whole message")
+	assert.False(t, d.MentionsUser("Results from the Heist:", ""), "no username")
+}
+
+func TestParsePayoutsMaxLength(t *testing.T) {
+	t.Parallel()
+
+	d := DefaultDialect()
+	require.NoError(t, d.Compile(), "Compile()")
+
+	long := "Results from the Heist: testuser (100)" + strings.Repeat(" ", 1000)
+	assert.Empty(t, d.ParsePayouts(long), "ParsePayouts should reject messages over 1000 chars")
+}
@@ -1,6 +1,12 @@
 package parsing
 
-import "strings"
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
 
 type SlotsOutcome string
 
@@ -12,35 +18,108 @@ const (
 	OutcomeSuperJackpot SlotsOutcome = "super_jackpot"
 )
 
+// SlotsResult describes a slots roll. Delta is the amount returned to the
+// wallet; Observed reports whether it was read from the message or inferred
+// from the payout table.
 type SlotsResult struct {
-	Delta   int
-	Outcome SlotsOutcome
+	Delta    int
+	Outcome  SlotsOutcome
+	Observed bool
 }
 
-func ParseSlotsDelta(message, username string) (SlotsResult, bool) {
-	lower := strings.ToLower(message)
-	if !strings.Contains(lower, strings.ToLower(username)) {
-		return SlotsResult{}, false
+// PayoutTable maps an outcome to the multiple of the slots cost it returns.
+type PayoutTable map[SlotsOutcome]float64
+
+type SlotsPricing struct {
+	Cost    int
+	Payouts PayoutTable
+}
+
+func DefaultPayoutTable() PayoutTable {
+	return PayoutTable{
+		OutcomeLost:         0,
+		OutcomeRefund:       1,
+		OutcomeSmallWin:     2,
+		OutcomeJackpot:      7.5,
+		OutcomeSuperJackpot: 30,
 	}
+}
 
-	switch {
-	case strings.Contains(lower, "you lost"):
-		return SlotsResult{Delta: 0, Outcome: OutcomeLost}, true
+func DefaultSlotsPricing(cost int) SlotsPricing {
+	return SlotsPricing{Cost: cost, Payouts: DefaultPayoutTable()}
+}
 
-	case strings.Contains(lower, "super jackpot"):
-		return SlotsResult{Delta: 60000, Outcome: OutcomeSuperJackpot}, true
+// ParsePayoutTable parses "outcome=multiplier" pairs separated by commas.
+// Outcomes not listed keep their default multiplier.
+func ParsePayoutTable(spec string) (PayoutTable, error) {
+	table := DefaultPayoutTable()
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return table, nil
+	}
 
-	case strings.Contains(lower, "jackpot"):
-		return SlotsResult{Delta: 15000, Outcome: OutcomeJackpot}, true
+	for _, pair := range strings.Split(spec, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid payout %q: expected outcome=multiplier", pair)
+		}
+		outcome := SlotsOutcome(strings.ToLower(strings.TrimSpace(key)))
+		if !outcome.Valid() {
+			return nil, fmt.Errorf("invalid payout %q: unknown outcome %q", pair, key)
+		}
+		mult, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || mult < 0 || math.IsInf(mult, 0) || math.IsNaN(mult) {
+			return nil, fmt.Errorf("invalid payout %q: multiplier must be a non-negative number", pair)
+		}
+		table[outcome] = mult
+	}
+	return table, nil
+}
 
-	case strings.Contains(lower, "even a small win is a win"):
-		return SlotsResult{Delta: 4000, Outcome: OutcomeSmallWin}, true
+func (t PayoutTable) Payout(outcome SlotsOutcome, cost int) int {
+	mult, ok := t[outcome]
+	if !ok {
+		mult = DefaultPayoutTable()[outcome]
+	}
+	return int(math.Round(float64(cost) * mult))
+}
 
-	case strings.Contains(lower, "you at least got your points back"):
-		return SlotsResult{Delta: 2000, Outcome: OutcomeRefund}, true
-	case strings.Contains(lower, "he command is still on user cooldown for"):
-		return SlotsResult{Delta: 2000, Outcome: OutcomeRefund}, true
-	default:
+func ParseSlotsDelta(message, username string, pricing SlotsPricing) (SlotsResult, bool) {
+	return defaultDialect.ParseSlots(message, username, pricing)
+}
+
+func (d *Dialect) ParseSlots(message, username string, pricing SlotsPricing) (SlotsResult, bool) {
+	outcome, ok := d.SlotsOutcomeOf(message, username)
+	if !ok {
 		return SlotsResult{}, false
 	}
+
+	if amount, ok := d.slotsAmount(message); ok {
+		return SlotsResult{Delta: amount, Outcome: outcome, Observed: true}, true
+	}
+
+	payouts := pricing.Payouts
+	if payouts == nil {
+		payouts = DefaultPayoutTable()
+	}
+	return SlotsResult{Delta: payouts.Payout(outcome, pricing.Cost), Outcome: outcome}, true
+}
+
+func (d *Dialect) slotsAmount(message string) (int, bool) {
+	if d.slotsAmountRe == nil || len(message) > maxMessageLen {
+		return 0, false
+	}
+	return firstNumber(d.slotsAmountRe, message)
+}
+
+func firstNumber(re *regexp.Regexp, message string) (int, bool) {
+	m := re.FindStringSubmatch(message)
+	if len(m) < 2 || len(m[1]) > maxPointsStringLen {
+		return 0, false
+	}
+	n, err := strconv.Atoi(strings.ReplaceAll(strings.TrimSpace(m[1]), " ", ""))
+	if err != nil {
+		return 0, false
+	}
+	return n, true
 }
@@ -0,0 +1,125 @@
+package permissions
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Role is something a chatter is, either from their Twitch badges or from the
+// bot's own configuration.
+type Role string
+
+const (
+	RoleEveryone    Role = "everyone"
+	RoleSubscriber  Role = "subscriber"
+	RoleVIP         Role = "vip"
+	RoleModerator   Role = "moderator"
+	RoleBroadcaster Role = "broadcaster"
+	RoleTrusted     Role = "trusted"
+	RoleOwner       Role = "owner"
+)
+
+var knownRoles = []Role{RoleEveryone, RoleSubscriber, RoleVIP, RoleModerator, RoleBroadcaster, RoleTrusted, RoleOwner}
+
+var ErrInvalidPolicy = errors.New("invalid permission policy")
+
+// badgeRoles maps Twitch badge names to roles.
+var badgeRoles = map[string]Role{
+	"broadcaster": RoleBroadcaster,
+	"moderator":   RoleModerator,
+	"vip":         RoleVIP,
+	"subscriber":  RoleSubscriber,
+	"founder":     RoleSubscriber,
+}
+
+// Roles is the set of roles a chatter holds.
+type Roles []Role
+
+// RolesFromBadges returns the roles granted by a chatter's Twitch badges.
+// Everyone holds RoleEveryone.
+func RolesFromBadges(badges map[string]int) Roles {
+	roles := Roles{RoleEveryone}
+	for badge := range badges {
+		if role, ok := badgeRoles[badge]; ok && !roles.Has(role) {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+func (r Roles) Has(role Role) bool {
+	return slices.Contains(r, role)
+}
+
+// Policy overrides the roles commands declare they need. Commands holds rules
+// for single commands, by any of their names. Default, when set, replaces the
+// declared roles of every command that is not owner-only. The owner may always
+// run everything.
+type Policy struct {
+	Default  Roles
+	Commands map[string]Roles
+}
+
+// ParsePolicy parses "command=role|role,command=role" overrides. The command
+// "*" sets the default rule.
+func ParsePolicy(spec string) (Policy, error) {
+	p := Policy{Commands: make(map[string]Roles)}
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		cmd, roleList, ok := strings.Cut(part, "=")
+		cmd = strings.ToLower(strings.TrimSpace(cmd))
+		if !ok || cmd == "" {
+			return Policy{}, fmt.Errorf("%w: %q is not command=roles", ErrInvalidPolicy, part)
+		}
+
+		var roles Roles
+		for _, name := range strings.Split(roleList, "|") {
+			role := Role(strings.ToLower(strings.TrimSpace(name)))
+			if !slices.Contains(knownRoles, role) {
+				return Policy{}, fmt.Errorf("%w: unknown role %q for %s", ErrInvalidPolicy, name, cmd)
+			}
+			roles = append(roles, role)
+		}
+
+		if cmd == "*" {
+			p.Default = roles
+		} else {
+			p.Commands[cmd] = roles
+		}
+	}
+	return p, nil
+}
+
+// Allows reports whether a chatter holding roles may run the command known by
+// names that declares it needs one of declared.
+func (p Policy) Allows(roles, declared Roles, names ...string) bool {
+	if roles.Has(RoleOwner) {
+		return true
+	}
+	for _, role := range p.Required(declared, names...) {
+		if roles.Has(role) {
+			return true
+		}
+	}
+	return false
+}
+
+// Required returns the roles that may run the command known by names that
+// declares it needs one of declared.
+func (p Policy) Required(declared Roles, names ...string) Roles {
+	for _, name := range names {
+		if roles, ok := p.Commands[strings.ToLower(name)]; ok {
+			return roles
+		}
+	}
+	if p.Default != nil && !declared.Has(RoleOwner) {
+		return p.Default
+	}
+	return declared
+}
@@ -0,0 +1,105 @@
+package permissions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRolesFromBadges(t *testing.T) {
+	t.Parallel()
+
+	roles := RolesFromBadges(map[string]int{"moderator": 1, "founder": 0, "subscriber": 12, "premium": 1})
+
+	assert.True(t, roles.Has(RoleEveryone), "everyone")
+	assert.True(t, roles.Has(RoleModerator), "moderator badge")
+	assert.True(t, roles.Has(RoleSubscriber), "subscriber badge")
+	assert.False(t, roles.Has(RoleVIP), "no vip badge")
+	assert.Len(t, roles, 3, "founder and subscriber give one role, unknown badges none")
+}
+
+func TestParsePolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		spec    string
+		wantErr bool
+	}{
+		{"empty keeps defaults", "", false},
+		{"single override", "autoslots=moderator|trusted", false},
+		{"default override", "*=everyone,trust=owner", false},
+		{"unknown role", "autoslots=admin", true},
+		{"missing roles", "autoslots", true},
+		{"missing command", "=moderator", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := ParsePolicy(tt.spec)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidPolicy, "ParsePolicy(%q)", tt.spec)
+				return
+			}
+			require.NoError(t, err, "ParsePolicy(%q)", tt.spec)
+		})
+	}
+}
+
+func TestPolicyAllows(t *testing.T) {
+	t.Parallel()
+
+	p, err := ParsePolicy("AutoSlots=moderator|trusted,status=everyone,zaufaj=vip")
+	require.NoError(t, err, "ParsePolicy()")
+
+	viewer := Roles{RoleEveryone}
+	mod := Roles{RoleEveryone, RoleModerator}
+	vip := Roles{RoleEveryone, RoleVIP}
+	trusted := Roles{RoleEveryone, RoleTrusted}
+	owner := Roles{RoleEveryone, RoleOwner}
+	trustedOnly := Roles{RoleTrusted}
+	ownerOnly := Roles{RoleOwner}
+
+	tests := []struct {
+		name     string
+		names    []string
+		declared Roles
+		roles    Roles
+		want     bool
+	}{
+		{"moderator runs autoslots", []string{"autoslots"}, trustedOnly, mod, true},
+		{"trusted runs autoslots", []string{"autoslots"}, trustedOnly, trusted, true},
+		{"viewer cannot run autoslots", []string{"autoslots"}, trustedOnly, viewer, false},
+		{"viewer runs status", []string{"status"}, trustedOnly, viewer, true},
+		{"moderator falls back to declared", []string{"ustaw"}, trustedOnly, mod, false},
+		{"trusted uses declared", []string{"ustaw"}, trustedOnly, trusted, true},
+		{"only owner trusts", []string{"trust"}, ownerOnly, trusted, false},
+		{"rule by alias", []string{"trust", "zaufaj"}, ownerOnly, vip, true},
+		{"owner runs everything", []string{"trust"}, ownerOnly, owner, true},
+		{"owner runs undeclared", []string{"secret"}, nil, owner, true},
+		{"nobody else runs undeclared", []string{"secret"}, nil, trusted, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, p.Allows(tt.roles, tt.declared, tt.names...), "Allows(%v)", tt.names)
+		})
+	}
+}
+
+func TestPolicyDefaultSparesOwnerOnlyCommands(t *testing.T) {
+	t.Parallel()
+
+	p, err := ParsePolicy("*=everyone")
+	require.NoError(t, err, "ParsePolicy()")
+
+	viewer := Roles{RoleEveryone}
+	assert.True(t, p.Allows(viewer, Roles{RoleTrusted}, "status"), "default replaces declared roles")
+	assert.False(t, p.Allows(viewer, Roles{RoleOwner}, "trust"), "owner-only commands keep their rule")
+	assert.Equal(t, Roles{RoleOwner}, p.Required(Roles{RoleOwner}, "trust"))
+}
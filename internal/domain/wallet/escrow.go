@@ -0,0 +1,274 @@
+package wallet
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// ExpiredBetRetention is how long an expired bet is remembered after its
+// deadline, so that a late result can still settle it.
+const ExpiredBetRetention = 30 * time.Minute
+
+// SyncReplyTimeout is how long a balance request waits for its reply before
+// it is forgotten.
+const SyncReplyTimeout = time.Minute
+
+// Bet is a stake reserved from the wallet that the boss bot has not yet
+// confirmed or rejected.
+type Bet struct {
+	ID       uint64
+	Game     Game
+	Amount   int
+	Message  string
+	PlacedAt time.Time
+	Deadline time.Time
+	// Late is set on a bet settled after it expired and was refunded.
+	Late bool
+
+	// synced bets are covered by a balance synced after they were placed,
+	// so they are not refunded.
+	synced bool
+}
+
+// Escrow deducts stakes from the wallet when a bet is placed and refunds them
+// when the bet is rolled back or expires unanswered. Expired bets are kept for
+// ExpiredBetRetention; a result arriving for one takes its refund back.
+type Escrow struct {
+	mu      sync.Mutex
+	wallet  *Wallet
+	pending []Bet
+	expired []Bet
+	nextID  uint64
+	now     func() time.Time
+	// requests are the balance requests not answered yet, oldest first.
+	requests []syncRequest
+}
+
+// syncRequest is a balance request sent after the bets up to lastID.
+type syncRequest struct {
+	lastID uint64
+	sentAt time.Time
+}
+
+func NewEscrow(w *Wallet) *Escrow {
+	return &Escrow{
+		wallet: w,
+		now:    time.Now,
+	}
+}
+
+func (e *Escrow) SetClock(now func() time.Time) {
+	e.mu.Lock()
+	e.now = now
+	e.mu.Unlock()
+}
+
+// Reserve deducts the stake and records a pending bet. A zero amount records a
+// free entry without touching the wallet.
+func (e *Escrow) Reserve(game Game, amount int, message string, timeout time.Duration) (Bet, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if amount > 0 && !e.wallet.SpendFor(amount, Reason{Game: game, Message: message}) {
+		return Bet{}, false
+	}
+
+	e.nextID++
+	now := e.now()
+	bet := Bet{
+		ID:       e.nextID,
+		Game:     game,
+		Amount:   amount,
+		Message:  message,
+		PlacedAt: now,
+		Deadline: now.Add(timeout),
+	}
+	e.pending = append(e.pending, bet)
+	return bet, true
+}
+
+// Commit confirms the oldest pending bet for the game. Without one, it settles
+// the oldest expired bet for the game and debits the refund it was given.
+func (e *Escrow) Commit(game Game) (Bet, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for i, bet := range e.pending {
+		if bet.Game == game {
+			e.removeAt(i)
+			return bet, true
+		}
+	}
+
+	e.pruneExpired()
+	for i, bet := range e.expired {
+		if bet.Game == game {
+			e.expired = append(e.expired[:i], e.expired[i+1:]...)
+			if bet.Amount > 0 {
+				e.wallet.AddBalanceFor(-bet.Amount, Reason{Game: bet.Game, Message: "late result: " + bet.Message})
+			}
+			bet.Late = true
+			return bet, true
+		}
+	}
+	return Bet{}, false
+}
+
+// Rollback refunds the pending bet with the given ID. message is recorded as
+// the reason for the refund.
+func (e *Escrow) Rollback(id uint64, message string) (Bet, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for i, bet := range e.pending {
+		if bet.ID == id {
+			e.removeAt(i)
+			e.refund(bet, message)
+			return bet, true
+		}
+	}
+	return Bet{}, false
+}
+
+// RollbackLatest refunds the most recently placed pending bet of one of games,
+// or of any game when none are given.
+func (e *Escrow) RollbackLatest(message string, games ...Game) (Bet, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for i := len(e.pending) - 1; i >= 0; i-- {
+		bet := e.pending[i]
+		if len(games) > 0 && !slices.Contains(games, bet.Game) {
+			continue
+		}
+		e.removeAt(i)
+		e.refund(bet, message)
+		return bet, true
+	}
+	return Bet{}, false
+}
+
+// SyncRequested records that a balance request was sent after the bets
+// placed so far.
+func (e *Escrow) SyncRequested() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	e.requests = slices.DeleteFunc(e.requests, func(req syncRequest) bool {
+		return now.After(req.sentAt.Add(SyncReplyTimeout))
+	})
+	e.requests = append(e.requests, syncRequest{lastID: e.nextID, sentAt: now})
+}
+
+// Sync sets the wallet to the balance the boss bot reported and returns the
+// drift from the tracked balance. The boss bot answers commands in order, so
+// the reply to the oldest open balance request accounts for the bets placed
+// before it: they are no longer refunded, and expired ones no longer take
+// their refund back. Bets placed after the request are still deducted from
+// the reported balance. A reply without a request covers every bet.
+func (e *Escrow) Sync(actual int, r Reason) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	covers := func(Bet) bool { return true }
+	if req, ok := e.takeRequest(); ok {
+		covers = func(bet Bet) bool { return bet.ID <= req.lastID }
+	}
+
+	uncovered := 0
+	for i, bet := range e.pending {
+		if covers(bet) {
+			e.pending[i].synced = true
+		} else {
+			uncovered += bet.Amount
+		}
+	}
+	e.expired = slices.DeleteFunc(e.expired, covers)
+
+	tracked := actual - uncovered
+	return tracked - e.wallet.ReplaceBalanceFor(tracked, r)
+}
+
+// takeRequest removes the oldest balance request still waiting for a reply.
+func (e *Escrow) takeRequest() (syncRequest, bool) {
+	now := e.now()
+	for len(e.requests) > 0 {
+		req := e.requests[0]
+		e.requests = e.requests[1:]
+		if !now.After(req.sentAt.Add(SyncReplyTimeout)) {
+			return req, true
+		}
+	}
+	return syncRequest{}, false
+}
+
+// Expire refunds every pending bet whose deadline has passed and returns the
+// refunded bets. Bets covered by a balance sync are dropped without a refund.
+func (e *Escrow) Expire() []Bet {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	var expired []Bet
+	kept := e.pending[:0]
+	for _, bet := range e.pending {
+		switch {
+		case !now.After(bet.Deadline):
+			kept = append(kept, bet)
+		case !bet.synced:
+			expired = append(expired, bet)
+		}
+	}
+	e.pending = kept
+
+	for _, bet := range expired {
+		e.refund(bet, "expired: "+bet.Message)
+	}
+	e.expired = append(e.expired, expired...)
+	e.pruneExpired()
+	return expired
+}
+
+func (e *Escrow) Pending() []Bet {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	bets := make([]Bet, len(e.pending))
+	copy(bets, e.pending)
+	return bets
+}
+
+func (e *Escrow) Reserved() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	total := 0
+	for _, bet := range e.pending {
+		total += bet.Amount
+	}
+	return total
+}
+
+func (e *Escrow) pruneExpired() {
+	now := e.now()
+	kept := e.expired[:0]
+	for _, bet := range e.expired {
+		if !now.After(bet.Deadline.Add(ExpiredBetRetention)) {
+			kept = append(kept, bet)
+		}
+	}
+	e.expired = kept
+}
+
+func (e *Escrow) removeAt(i int) {
+	e.pending = append(e.pending[:i], e.pending[i+1:]...)
+}
+
+func (e *Escrow) refund(bet Bet, message string) {
+	if bet.Amount == 0 || bet.synced {
+		return
+	}
+	e.wallet.AddBalanceFor(bet.Amount, Reason{Game: bet.Game, Message: message})
+}
@@ -0,0 +1,214 @@
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEscrow(balance int) (*Escrow, *time.Time) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	e := NewEscrow(New(balance))
+	e.now = func() time.Time { return now }
+	return e, &now
+}
+
+func TestEscrow_Reserve(t *testing.T) {
+	t.Parallel()
+
+	e, _ := newTestEscrow(3000)
+
+	bet, ok := e.Reserve(GameSlots, 2000, "!slots", time.Minute)
+	require.True(t, ok, "Reserve() should succeed")
+	assert.Equal(t, 1000, e.wallet.GetBalance(), "stake deducted")
+	assert.Equal(t, 2000, e.Reserved(), "reserved total")
+	assert.Equal(t, GameSlots, bet.Game, "bet game")
+
+	_, ok = e.Reserve(GameSlots, 2000, "!slots", time.Minute)
+	assert.False(t, ok, "Reserve() should fail without funds")
+	assert.Len(t, e.Pending(), 1, "failed reserve adds no bet")
+}
+
+func TestEscrow_FreeEntry(t *testing.T) {
+	t.Parallel()
+
+	e, _ := newTestEscrow(0)
+	var entries []Entry
+	e.wallet.SetRecorder(func(entry Entry) { entries = append(entries, entry) })
+
+	_, ok := e.Reserve(GameBoss, 0, "!boss", time.Minute)
+	require.True(t, ok, "Reserve() of a free entry should succeed without funds")
+	assert.Len(t, e.Pending(), 1, "free entry is pending")
+
+	_, ok = e.RollbackLatest("expired")
+	require.True(t, ok, "RollbackLatest() of a free entry")
+	assert.Zero(t, e.wallet.GetBalance(), "free entry refunds nothing")
+	assert.Empty(t, entries, "free entry leaves no ledger entries")
+}
+
+func TestEscrow_Commit(t *testing.T) {
+	t.Parallel()
+
+	e, _ := newTestEscrow(5000)
+	first, _ := e.Reserve(GameHeist, 1000, "!heist 1000", time.Minute)
+	e.Reserve(GameSlots, 2000, "!slots", time.Minute)
+	e.Reserve(GameHeist, 500, "!heist 500", time.Minute)
+
+	bet, ok := e.Commit(GameHeist)
+	require.True(t, ok, "Commit() should find heist bet")
+	assert.Equal(t, first.ID, bet.ID, "oldest bet of the game is committed")
+	assert.Equal(t, 1500, e.wallet.GetBalance(), "commit does not refund")
+	assert.Len(t, e.Pending(), 2, "remaining bets")
+
+	_, ok = e.Commit(GameFFA)
+	assert.False(t, ok, "Commit() without bet for game")
+}
+
+func TestEscrow_Rollback(t *testing.T) {
+	t.Parallel()
+
+	e, _ := newTestEscrow(5000)
+	first, _ := e.Reserve(GameFFA, 1000, "!ffa", time.Minute)
+	e.Reserve(GameSlots, 2000, "!slots", time.Minute)
+
+	bet, ok := e.RollbackLatest("testuser doesn't have enough bombs")
+	require.True(t, ok, "RollbackLatest() should refund")
+	assert.Equal(t, GameSlots, bet.Game, "latest bet refunded")
+	assert.Equal(t, 4000, e.wallet.GetBalance(), "stake refunded")
+
+	_, ok = e.Rollback(first.ID, "send failed")
+	require.True(t, ok, "Rollback() by ID")
+	assert.Equal(t, 5000, e.wallet.GetBalance(), "all stakes refunded")
+
+	_, ok = e.Rollback(first.ID, "again")
+	assert.False(t, ok, "Rollback() twice")
+	_, ok = e.RollbackLatest("nothing pending")
+	assert.False(t, ok, "RollbackLatest() with no bets")
+}
+
+func TestEscrow_Expire(t *testing.T) {
+	t.Parallel()
+
+	e, now := newTestEscrow(5000)
+	e.Reserve(GameSlots, 2000, "!slots", time.Minute)
+	e.Reserve(GameHeist, 1000, "!heist 1000", 10*time.Minute)
+
+	assert.Empty(t, e.Expire(), "nothing expired yet")
+
+	*now = now.Add(2 * time.Minute)
+	expired := e.Expire()
+	require.Len(t, expired, 1, "slots bet expired")
+	assert.Equal(t, GameSlots, expired[0].Game, "expired game")
+	assert.Equal(t, 4000, e.wallet.GetBalance(), "expired stake refunded")
+	assert.Len(t, e.Pending(), 1, "heist bet still pending")
+}
+
+func TestEscrow_LateResultTakesRefundBack(t *testing.T) {
+	t.Parallel()
+
+	e, now := newTestEscrow(5000)
+	e.Reserve(GameHeist, 1000, "!heist 1000", 10*time.Minute)
+
+	*now = now.Add(11 * time.Minute)
+	require.Len(t, e.Expire(), 1, "heist bet expired")
+	assert.Equal(t, 5000, e.wallet.GetBalance(), "expired stake refunded")
+
+	bet, ok := e.Commit(GameHeist)
+	require.True(t, ok, "late result finds the expired bet")
+	assert.True(t, bet.Late, "bet marked late")
+	assert.Equal(t, 4000, e.wallet.GetBalance(), "refund taken back")
+
+	_, ok = e.Commit(GameHeist)
+	assert.False(t, ok, "expired bet settled once")
+
+	e.Reserve(GameFFA, 500, "!ffa", time.Minute)
+	*now = now.Add(2 * time.Minute)
+	e.Expire()
+	*now = now.Add(ExpiredBetRetention + time.Minute)
+	_, ok = e.Commit(GameFFA)
+	assert.False(t, ok, "expired bets are forgotten after the retention")
+	assert.Equal(t, 4000, e.wallet.GetBalance(), "refund of a forgotten bet kept")
+}
+
+func TestEscrow_RollbackLatestOfGame(t *testing.T) {
+	t.Parallel()
+
+	e, _ := newTestEscrow(5000)
+	heist, _ := e.Reserve(GameHeist, 1000, "!heist 1000", time.Minute)
+	e.Reserve(GameSlots, 2000, "!slots", time.Minute)
+
+	bet, ok := e.RollbackLatest("the heist is on cooldown", GameHeist)
+	require.True(t, ok, "RollbackLatest() of the heist")
+	assert.Equal(t, heist.ID, bet.ID, "heist refunded, not the newer slots bet")
+	assert.Equal(t, 3000, e.wallet.GetBalance(), "heist stake refunded")
+
+	_, ok = e.RollbackLatest("arena on cooldown", GameFFA)
+	assert.False(t, ok, "no pending arena bet")
+	assert.Len(t, e.Pending(), 1, "slots bet still pending")
+}
+
+func TestEscrow_SyncCoversPendingBets(t *testing.T) {
+	t.Parallel()
+
+	e, now := newTestEscrow(10000)
+	e.Reserve(GameHeist, 1000, "!heist 1000", time.Minute)
+	*now = now.Add(2 * time.Minute)
+	e.Expire()
+	e.Reserve(GameSlots, 2000, "!slots", 5*time.Minute)
+
+	drift := e.Sync(7500, Reason{Game: GameSync})
+
+	assert.Equal(t, 7500, e.wallet.GetBalance(), "reported balance")
+	assert.Equal(t, -500, drift, "drift against the tracked balance")
+	_, ok := e.Commit(GameHeist)
+	assert.False(t, ok, "expired bet is covered by the sync")
+
+	*now = now.Add(10 * time.Minute)
+	assert.Empty(t, e.Expire(), "bets covered by a sync expire without a refund")
+	assert.Empty(t, e.Pending(), "expired bets dropped")
+	assert.Equal(t, 7500, e.wallet.GetBalance(), "no refund on top of the synced balance")
+}
+
+func TestEscrow_SyncCoversOnlyBetsBeforeTheRequest(t *testing.T) {
+	t.Parallel()
+
+	e, now := newTestEscrow(10000)
+	e.Reserve(GameHeist, 1000, "!heist 1000", time.Minute)
+	e.SyncRequested()
+	e.Reserve(GameSlots, 2000, "!slots", time.Minute)
+
+	drift := e.Sync(9000, Reason{Game: GameSync})
+
+	assert.Equal(t, 7000, e.wallet.GetBalance(), "slots placed after the request still deducted")
+	assert.Zero(t, drift, "no drift")
+
+	*now = now.Add(2 * time.Minute)
+	expired := e.Expire()
+	require.Len(t, expired, 1, "only the uncovered bet is refunded")
+	assert.Equal(t, GameSlots, expired[0].Game)
+	assert.Equal(t, 9000, e.wallet.GetBalance(), "slots stake back")
+
+	e.SyncRequested()
+	*now = now.Add(2 * SyncReplyTimeout)
+	e.Sync(9000, Reason{Game: GameSync})
+	_, ok := e.Commit(GameSlots)
+	assert.False(t, ok, "a reply after the request timed out covers every bet")
+}
+
+func TestEscrow_RefundRecorded(t *testing.T) {
+	t.Parallel()
+
+	e, _ := newTestEscrow(1000)
+	var entries []Entry
+	e.wallet.SetRecorder(func(en Entry) { entries = append(entries, en) })
+
+	e.Reserve(GameSlots, 1000, "!slots", time.Minute)
+	e.RollbackLatest("testuser is on cooldown")
+
+	require.Len(t, entries, 2, "reserve and refund recorded")
+	assert.Equal(t, -1000, entries[0].Amount, "reserve entry")
+	assert.Equal(t, 1000, entries[1].Amount, "refund entry")
+	assert.Equal(t, "testuser is on cooldown", entries[1].Message, "refund reason")
+}
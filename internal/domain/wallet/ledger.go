@@ -0,0 +1,44 @@
+package wallet
+
+import "time"
+
+type Game string
+
+const (
+	GameSlots  Game = "slots"
+	GameHeist  Game = "heist"
+	GameFFA    Game = "ffa"
+	GameBoss   Game = "boss"
+	GameSync   Game = "sync"
+	GamePoints Game = "points"
+)
+
+// Reason tags a balance mutation with the game and the raw chat message that
+// caused it.
+type Reason struct {
+	Game    Game
+	Message string
+}
+
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Game    Game      `json:"game,omitempty"`
+	Amount  int       `json:"amount"`
+	Before  int       `json:"before"`
+	After   int       `json:"after"`
+	Message string    `json:"message,omitempty"`
+}
+
+// Recorder receives every balance mutation made through the wallet.
+type Recorder func(Entry)
+
+func newEntry(r Reason, before, after int) Entry {
+	return Entry{
+		Time:    time.Now(),
+		Game:    r.Game,
+		Amount:  after - before,
+		Before:  before,
+		After:   after,
+		Message: r.Message,
+	}
+}
@@ -3,14 +3,21 @@ package wallet
 import "sync"
 
 type Wallet struct {
-	mu      sync.Mutex
-	balance int
+	mu       sync.Mutex
+	balance  int
+	recorder Recorder
 }
 
 func New(initial int) *Wallet {
 	return &Wallet{balance: initial}
 }
 
+func (w *Wallet) SetRecorder(r Recorder) {
+	w.mu.Lock()
+	w.recorder = r
+	w.mu.Unlock()
+}
+
 func (w *Wallet) GetBalance() int {
 	w.mu.Lock()
 	defer w.mu.Unlock()
@@ -18,24 +25,57 @@ func (w *Wallet) GetBalance() int {
 }
 
 func (w *Wallet) SetBalance(amount int) {
+	w.SetBalanceFor(amount, Reason{})
+}
+
+func (w *Wallet) SetBalanceFor(amount int, r Reason) {
+	w.ReplaceBalanceFor(amount, r)
+}
+
+// ReplaceBalanceFor sets the balance and returns the balance it replaced.
+func (w *Wallet) ReplaceBalanceFor(amount int, r Reason) int {
 	w.mu.Lock()
+	before := w.balance
 	w.balance = amount
+	rec := w.recorder
 	w.mu.Unlock()
+
+	w.record(rec, r, before, amount)
+	return before
 }
 
 func (w *Wallet) AddBalance(delta int) {
+	w.AddBalanceFor(delta, Reason{})
+}
+
+func (w *Wallet) AddBalanceFor(delta int, r Reason) {
 	w.mu.Lock()
+	before := w.balance
 	w.balance += delta
+	after := w.balance
+	rec := w.recorder
 	w.mu.Unlock()
+
+	w.record(rec, r, before, after)
 }
 
 func (w *Wallet) Spend(amount int) bool {
+	return w.SpendFor(amount, Reason{})
+}
+
+func (w *Wallet) SpendFor(amount int, r Reason) bool {
 	w.mu.Lock()
-	defer w.mu.Unlock()
 	if w.balance < amount {
+		w.mu.Unlock()
 		return false
 	}
+	before := w.balance
 	w.balance -= amount
+	after := w.balance
+	rec := w.recorder
+	w.mu.Unlock()
+
+	w.record(rec, r, before, after)
 	return true
 }
 
@@ -44,3 +84,9 @@ func (w *Wallet) CanAfford(amount int) bool {
 	defer w.mu.Unlock()
 	return w.balance >= amount
 }
+
+func (w *Wallet) record(rec Recorder, r Reason, before, after int) {
+	if rec != nil {
+		rec(newEntry(r, before, after))
+	}
+}
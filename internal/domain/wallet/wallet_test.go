@@ -176,3 +176,47 @@ func TestWallet_Concurrent(t *testing.T) {
 		})
 	}
 }
+
+func TestWallet_Recorder(t *testing.T) {
+	t.Parallel()
+
+	w := New(1000)
+	var entries []Entry
+	w.SetRecorder(func(e Entry) {
+		entries = append(entries, e)
+	})
+
+	w.SpendFor(300, Reason{Game: GameSlots, Message: "!slots"})
+	w.AddBalanceFor(600, Reason{Game: GameSlots, Message: "testuser jackpot"})
+	w.SetBalanceFor(1500, Reason{Game: GameSync, Message: "testuser bombs: 1500"})
+	w.SpendFor(5000, Reason{Game: GameHeist, Message: "!heist 5000"})
+
+	assert.Len(t, entries, 3, "failed spend should not be recorded")
+
+	assert.Equal(t, GameSlots, entries[0].Game, "spend game")
+	assert.Equal(t, -300, entries[0].Amount, "spend amount")
+	assert.Equal(t, 1000, entries[0].Before, "spend before")
+	assert.Equal(t, 700, entries[0].After, "spend after")
+	assert.Equal(t, "!slots", entries[0].Message, "spend message")
+
+	assert.Equal(t, 600, entries[1].Amount, "add amount")
+	assert.Equal(t, 1300, entries[1].After, "add after")
+
+	assert.Equal(t, GameSync, entries[2].Game, "sync game")
+	assert.Equal(t, 200, entries[2].Amount, "sync amount")
+	assert.False(t, entries[2].Time.IsZero(), "entry timestamp")
+}
+
+func TestWallet_UntaggedMutationsRecorded(t *testing.T) {
+	t.Parallel()
+
+	w := New(0)
+	count := 0
+	w.SetRecorder(func(Entry) { count++ })
+
+	w.AddBalance(10)
+	w.SetBalance(20)
+	w.Spend(5)
+
+	assert.Equal(t, 3, count, "untagged mutations should be recorded")
+}
@@ -111,6 +111,101 @@ func (_c *MockConfigStore_GetOAuth_Call) RunAndReturn(run func() string) *MockCo
 	return _c
 }
 
+// Set provides a mock function with given fields: key, value
+func (_m *MockConfigStore) Set(key ports.Setting, value string) error {
+	ret := _m.Called(key, value)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Set")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(ports.Setting, string) error); ok {
+		r0 = rf(key, value)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockConfigStore_Set_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Set'
+type MockConfigStore_Set_Call struct {
+	*mock.Call
+}
+
+// Set is a helper method to define mock.On call
+//   - key ports.Setting
+//   - value string
+func (_e *MockConfigStore_Expecter) Set(key interface{}, value interface{}) *MockConfigStore_Set_Call {
+	return &MockConfigStore_Set_Call{Call: _e.mock.On("Set", key, value)}
+}
+
+func (_c *MockConfigStore_Set_Call) Run(run func(key ports.Setting, value string)) *MockConfigStore_Set_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(ports.Setting), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockConfigStore_Set_Call) Return(_a0 error) *MockConfigStore_Set_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockConfigStore_Set_Call) RunAndReturn(run func(ports.Setting, string) error) *MockConfigStore_Set_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Subscribe provides a mock function with given fields: handler
+func (_m *MockConfigStore) Subscribe(handler func(ports.BotConfig, ports.BotConfig)) func() {
+	ret := _m.Called(handler)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Subscribe")
+	}
+
+	var r0 func()
+	if rf, ok := ret.Get(0).(func(func(ports.BotConfig, ports.BotConfig)) func()); ok {
+		r0 = rf(handler)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(func())
+		}
+	}
+
+	return r0
+}
+
+// MockConfigStore_Subscribe_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Subscribe'
+type MockConfigStore_Subscribe_Call struct {
+	*mock.Call
+}
+
+// Subscribe is a helper method to define mock.On call
+//   - handler func(ports.BotConfig , ports.BotConfig)
+func (_e *MockConfigStore_Expecter) Subscribe(handler interface{}) *MockConfigStore_Subscribe_Call {
+	return &MockConfigStore_Subscribe_Call{Call: _e.mock.On("Subscribe", handler)}
+}
+
+func (_c *MockConfigStore_Subscribe_Call) Run(run func(handler func(ports.BotConfig, ports.BotConfig))) *MockConfigStore_Subscribe_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(func(ports.BotConfig, ports.BotConfig)))
+	})
+	return _c
+}
+
+func (_c *MockConfigStore_Subscribe_Call) Return(unsubscribe func()) *MockConfigStore_Subscribe_Call {
+	_c.Call.Return(unsubscribe)
+	return _c
+}
+
+func (_c *MockConfigStore_Subscribe_Call) RunAndReturn(run func(func(ports.BotConfig, ports.BotConfig)) func()) *MockConfigStore_Subscribe_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // UpdateHeist provides a mock function with given fields: amount
 func (_m *MockConfigStore) UpdateHeist(amount int) error {
 	ret := _m.Called(amount)
@@ -2,7 +2,11 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	ports "streamgogambler/internal/ports"
+
+	mock "github.com/stretchr/testify/mock"
+)
 
 // MockConfigWriter is an autogenerated mock type for the ConfigWriter type
 type MockConfigWriter struct {
@@ -17,6 +21,53 @@ func (_m *MockConfigWriter) EXPECT() *MockConfigWriter_Expecter {
 	return &MockConfigWriter_Expecter{mock: &_m.Mock}
 }
 
+// Set provides a mock function with given fields: key, value
+func (_m *MockConfigWriter) Set(key ports.Setting, value string) error {
+	ret := _m.Called(key, value)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Set")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(ports.Setting, string) error); ok {
+		r0 = rf(key, value)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockConfigWriter_Set_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Set'
+type MockConfigWriter_Set_Call struct {
+	*mock.Call
+}
+
+// Set is a helper method to define mock.On call
+//   - key ports.Setting
+//   - value string
+func (_e *MockConfigWriter_Expecter) Set(key interface{}, value interface{}) *MockConfigWriter_Set_Call {
+	return &MockConfigWriter_Set_Call{Call: _e.mock.On("Set", key, value)}
+}
+
+func (_c *MockConfigWriter_Set_Call) Run(run func(key ports.Setting, value string)) *MockConfigWriter_Set_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(ports.Setting), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockConfigWriter_Set_Call) Return(_a0 error) *MockConfigWriter_Set_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockConfigWriter_Set_Call) RunAndReturn(run func(ports.Setting, string) error) *MockConfigWriter_Set_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // UpdateHeist provides a mock function with given fields: amount
 func (_m *MockConfigWriter) UpdateHeist(amount int) error {
 	ret := _m.Called(amount)
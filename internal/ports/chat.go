@@ -1,13 +1,26 @@
 package ports
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+// ErrTokenInvalid is returned by ChatClient.Connect when Twitch rejects the
+// OAuth token; retrying with the same token cannot succeed.
+var ErrTokenInvalid = errors.New("token invalid")
 
 type ChatMessage struct {
-	ID       string
-	UserName string
-	UserID   string
-	Channel  string
-	Text     string
+	ID          string
+	UserName    string
+	UserID      string
+	DisplayName string
+	Channel     string
+	Text        string
+	// Badges maps Twitch badge names (broadcaster, moderator, vip,
+	// subscriber, ...) to their version.
+	Badges map[string]int
+	// Tags holds the raw IRCv3 tags of the message.
+	Tags map[string]string
 }
 
 type BanEvent struct {
@@ -1,8 +1,19 @@
 package ports
 
+import (
+	"errors"
+	"strconv"
+)
+
 type BotConfig struct {
 	Username string
-	Channel  string
+	// AuthURL is the base URL of the Twitch OAuth server the token is
+	// validated against.
+	AuthURL string
+	Channel string
+	// Channels lists every joined channel; Channel is the one this
+	// configuration applies to.
+	Channels []string
 
 	Prefix        string
 	StatusCommand string
@@ -12,24 +23,45 @@ type BotConfig struct {
 	BandOnPerma      bool
 	GreetOnReconnect bool
 
-	BossBotName   string
-	AutoResponses map[string]string
+	BossBotName    string
+	BossBotID      string
+	BossBotDialect string
+
+	// Language selects the built-in catalog of chat replies; MessagesFile
+	// overrides single replies.
+	Language     string
+	MessagesFile string
 
 	DefaultHeist int
 	SlotsCost    int
 	ArenaCost    int
+	BossCost     int
+
+	SlotsPayouts map[string]float64
+
+	BettingStrategy string
+
+	CommandPermissions string
+
+	ReserveFloor int
+	StopLoss     int
+	TakeProfit   int
 
 	AutoSlotsEnabled  bool
 	AutoSlotsInterval int
 
 	PointsAsDelta bool
 
+	BalanceSyncInterval int
+
 	SayBucketSize int
 	SayRefillMs   int
 
 	LogLevel   string
 	HealthPort int
 
+	TranscriptFile string
+
 	GUIEnabled   bool
 	MaxLogsLines int
 }
@@ -40,11 +72,127 @@ type ConfigReader interface {
 	GetOAuth() string
 }
 
+// Setting names a BotConfig field that can be changed while the bot runs,
+// spelled like its environment variable.
+type Setting string
+
+const (
+	SettingHeistAmount         Setting = "HEIST_AMOUNT"
+	SettingSlotsCost           Setting = "SLOTS_COST"
+	SettingArenaCost           Setting = "ARENA_COST"
+	SettingBossCost            Setting = "BOSS_COST"
+	SettingReserveFloor        Setting = "RESERVE_FLOOR"
+	SettingStopLoss            Setting = "STOP_LOSS"
+	SettingTakeProfit          Setting = "TAKE_PROFIT"
+	SettingAutoSlotsEnabled    Setting = "AUTO_SLOTS_ENABLED"
+	SettingAutoSlotsInterval   Setting = "AUTO_SLOTS_INTERVAL"
+	SettingBalanceSyncInterval Setting = "BALANCE_SYNC_INTERVAL"
+	SettingConnectMessage      Setting = "CONNECT_MESSAGE"
+	SettingGreetOnReconnect    Setting = "GREET_ON_RECONNECT"
+	SettingBandMessage         Setting = "BAND_MESSAGE"
+	SettingBandOnPerma         Setting = "BAND_ON_PERMA"
+	SettingPointsAsDelta       Setting = "POINTS_AS_DELTA"
+)
+
+// MutableSettings lists the settings ConfigWriter.Set accepts.
+var MutableSettings = []Setting{
+	SettingHeistAmount,
+	SettingSlotsCost,
+	SettingArenaCost,
+	SettingBossCost,
+	SettingReserveFloor,
+	SettingStopLoss,
+	SettingTakeProfit,
+	SettingAutoSlotsEnabled,
+	SettingAutoSlotsInterval,
+	SettingBalanceSyncInterval,
+	SettingConnectMessage,
+	SettingGreetOnReconnect,
+	SettingBandMessage,
+	SettingBandOnPerma,
+	SettingPointsAsDelta,
+}
+
+var (
+	ErrUnknownSetting = errors.New("unknown setting")
+	ErrInvalidSetting = errors.New("invalid value")
+)
+
+// SettingKind is the type of a setting's value.
+type SettingKind int
+
+const (
+	KindInt SettingKind = iota
+	KindBool
+	KindText
+)
+
+func (s Setting) Kind() SettingKind {
+	switch s {
+	case SettingAutoSlotsEnabled, SettingGreetOnReconnect, SettingBandOnPerma, SettingPointsAsDelta:
+		return KindBool
+	case SettingConnectMessage, SettingBandMessage:
+		return KindText
+	}
+	return KindInt
+}
+
+// Get returns the value of a mutable setting as it is written in .env.
+func (c BotConfig) Get(key Setting) (string, bool) {
+	switch key {
+	case SettingHeistAmount:
+		return strconv.Itoa(c.DefaultHeist), true
+	case SettingSlotsCost:
+		return strconv.Itoa(c.SlotsCost), true
+	case SettingArenaCost:
+		return strconv.Itoa(c.ArenaCost), true
+	case SettingBossCost:
+		return strconv.Itoa(c.BossCost), true
+	case SettingReserveFloor:
+		return strconv.Itoa(c.ReserveFloor), true
+	case SettingStopLoss:
+		return strconv.Itoa(c.StopLoss), true
+	case SettingTakeProfit:
+		return strconv.Itoa(c.TakeProfit), true
+	case SettingAutoSlotsEnabled:
+		return strconv.FormatBool(c.AutoSlotsEnabled), true
+	case SettingAutoSlotsInterval:
+		return strconv.Itoa(c.AutoSlotsInterval), true
+	case SettingBalanceSyncInterval:
+		return strconv.Itoa(c.BalanceSyncInterval), true
+	case SettingConnectMessage:
+		return c.ConnectMessage, true
+	case SettingGreetOnReconnect:
+		return strconv.FormatBool(c.GreetOnReconnect), true
+	case SettingBandMessage:
+		return c.BandMessage, true
+	case SettingBandOnPerma:
+		return strconv.FormatBool(c.BandOnPerma), true
+	case SettingPointsAsDelta:
+		return strconv.FormatBool(c.PointsAsDelta), true
+	}
+	return "", false
+}
+
 type ConfigWriter interface {
 	UpdateHeist(amount int) error
+
+	// Set validates value for one of MutableSettings, saves it and applies
+	// it. Unknown settings return ErrUnknownSetting, values that do not
+	// validate ErrInvalidSetting.
+	Set(key Setting, value string) error
+}
+
+// ConfigWatcher notifies about configuration changes applied while the bot
+// runs.
+type ConfigWatcher interface {
+	// Subscribe calls handler with the previous and the new configuration
+	// after every change until the returned function is called.
+	Subscribe(handler func(old, updated BotConfig)) (unsubscribe func())
 }
 
 type ConfigStore interface {
 	ConfigReader
 	ConfigWriter
+	ConfigWatcher
 }
@@ -1,5 +1,16 @@
 package ports
 
+// StatusTokenInvalid is the status of an account stopped because Twitch
+// rejected its OAuth token.
+const StatusTokenInvalid = "token_invalid"
+
+// Token states reported in TokenStatus.
+const (
+	TokenUnknown = "unknown"
+	TokenValid   = "valid"
+	TokenInvalid = "invalid"
+)
+
 type BotStats struct {
 	Status         string  `json:"status"`
 	Uptime         string  `json:"uptime"`
@@ -10,6 +21,76 @@ type BotStats struct {
 	ReconnectCount int     `json:"reconnect_count"`
 	Channel        string  `json:"channel"`
 	Username       string  `json:"username"`
+
+	LastSync         string `json:"last_sync,omitempty"`
+	LastDrift        int    `json:"last_drift"`
+	DriftCorrections int    `json:"drift_corrections"`
+	Strategy         string `json:"strategy,omitempty"`
+
+	Guardrail     string `json:"guardrail"`
+	SessionProfit int    `json:"session_profit"`
+	ReserveFloor  int    `json:"reserve_floor,omitempty"`
+
+	Games map[string]GameStats `json:"games,omitempty"`
+
+	Token *TokenStatus `json:"token,omitempty"`
+
+	// Counters and Say are only exported as Prometheus metrics.
+	Counters Counters  `json:"-"`
+	Say      *SayStats `json:"-"`
+
+	Channels []BotStats `json:"channels,omitempty"`
+	Accounts []BotStats `json:"accounts,omitempty"`
+}
+
+type GameStats struct {
+	Seen     int     `json:"seen"`
+	Joined   int     `json:"joined"`
+	Survived int     `json:"survived"`
+	Staked   int     `json:"staked"`
+	Returned int     `json:"returned"`
+	WinRate  float64 `json:"win_rate"`
+	AvgCrew  float64 `json:"avg_crew"`
+
+	Unresolved   int     `json:"unresolved,omitempty"`
+	AvgPlacement float64 `json:"avg_placement,omitempty"`
+}
+
+// TokenStatus is the result of the last validation of an account's OAuth
+// token.
+type TokenStatus struct {
+	State       string   `json:"state"`
+	Login       string   `json:"login,omitempty"`
+	Scopes      []string `json:"scopes,omitempty"`
+	ExpiresAt   string   `json:"expires_at,omitempty"`
+	ValidatedAt string   `json:"validated_at,omitempty"`
+	Error       string   `json:"error,omitempty"`
+}
+
+// Counters are running totals since the bot started, keyed by game, slots
+// outcome or the kind of message that could not be parsed.
+type Counters struct {
+	Spent         map[string]int
+	Won           map[string]int
+	SlotsOutcomes map[string]int
+	ParseFailures map[string]int
+	// Reconnects counts every reconnect since start, unlike
+	// BotStats.ReconnectCount, which restarts after a quiet period.
+	Reconnects int
+}
+
+// SayStats describes how long messages waited for a rate-limit token.
+type SayStats struct {
+	// Buckets count the messages that waited at most UpperBound seconds.
+	Buckets  []SayBucket
+	WaitSum  float64
+	Sent     int
+	Timeouts int
+}
+
+type SayBucket struct {
+	UpperBound float64
+	Count      int
 }
 
 type StatsProvider interface {
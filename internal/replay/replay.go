@@ -0,0 +1,176 @@
+package replay
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"streamgogambler/internal/adapters/config"
+	"streamgogambler/internal/adapters/logging"
+	"streamgogambler/internal/adapters/storage"
+	"streamgogambler/internal/application"
+	"streamgogambler/internal/domain/autoresponse"
+	"streamgogambler/internal/domain/i18n"
+	"streamgogambler/internal/domain/parsing"
+	"streamgogambler/internal/domain/wallet"
+	"streamgogambler/internal/ports"
+)
+
+const (
+	KindReceived = "recv"
+	KindSent     = "send"
+	KindWallet   = "wallet"
+
+	timeFormat = "2006-01-02 15:04:05"
+)
+
+// Event is one line of a replay: a message fed to the bot, a message the bot
+// would have sent or a change of its wallet.
+type Event struct {
+	Time    time.Time
+	Kind    string
+	User    string
+	Text    string
+	Game    wallet.Game
+	Amount  int
+	Balance int
+}
+
+type Options struct {
+	Bot           ports.BotConfig
+	Dialect       *parsing.Dialect
+	Messages      *i18n.Catalog
+	AutoResponses []autoresponse.Rule
+	StartBalance  int
+}
+
+type Result struct {
+	Events   []Event
+	Final    int
+	Received int
+	Sent     int
+}
+
+// Run feeds a transcript through the bot's MessageHandler with a fake chat
+// client and a clock that follows the transcript timestamps.
+func Run(ctx context.Context, entries []storage.TranscriptEntry, opts Options) Result {
+	var (
+		now    time.Time
+		result Result
+	)
+	if len(entries) > 0 {
+		now = entries[0].Time
+	}
+
+	chat := &recordingChat{
+		onSay: func(_, message string) {
+			result.Sent++
+			result.Events = append(result.Events, Event{Time: now, Kind: KindSent, Text: message})
+		},
+	}
+
+	logger := logging.New(logging.LevelError)
+	logger.SetCallback(func(string) {})
+
+	options := []application.BotOption{
+		application.WithClock(func() time.Time { return now }),
+		application.WithAutoResponses(opts.AutoResponses, nil),
+		application.WithOfflineReplay(),
+	}
+	if opts.Dialect != nil {
+		options = append(options, application.WithDialect(opts.Dialect))
+	}
+	if opts.Messages != nil {
+		options = append(options, application.WithMessages(opts.Messages))
+	}
+	bot := application.NewBotService(config.NewStaticStore(opts.Bot), chat, logger, nil, options...)
+
+	bot.Wallet().SetBalance(opts.StartBalance)
+	bot.Wallet().SetRecorder(func(e wallet.Entry) {
+		result.Events = append(result.Events, Event{
+			Time:    now,
+			Kind:    KindWallet,
+			Game:    e.Game,
+			Amount:  e.Amount,
+			Balance: e.After,
+			Text:    e.Message,
+		})
+	})
+
+	bot.Attach(ctx)
+	defer bot.Stop()
+
+	for _, entry := range entries {
+		now = entry.Time
+		bot.Escrow().Expire()
+
+		result.Received++
+		result.Events = append(result.Events, Event{Time: now, Kind: KindReceived, User: entry.User, Text: entry.Text})
+		chat.deliver(entry.Message())
+	}
+
+	result.Final = bot.Wallet().GetBalance()
+	return result
+}
+
+// Write prints the replay as a timeline followed by a summary.
+func (r Result) Write(w io.Writer) error {
+	var b strings.Builder
+	for _, e := range r.Events {
+		at := e.Time.UTC().Format(timeFormat)
+		switch e.Kind {
+		case KindReceived:
+			fmt.Fprintf(&b, "%s  recv    %s: %s\n", at, e.User, e.Text)
+		case KindSent:
+			fmt.Fprintf(&b, "%s  send    %s\n", at, e.Text)
+		case KindWallet:
+			game := string(e.Game)
+			if game == "" {
+				game = "-"
+			}
+			fmt.Fprintf(&b, "%s  wallet  %-6s %+d = %d\n", at, game, e.Amount, e.Balance)
+		}
+	}
+	fmt.Fprintf(&b, "\nReceived %d messages, sent %d, final balance %d\n", r.Received, r.Sent, r.Final)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// recordingChat is a ports.ChatClient that never connects; it hands
+// messages to the bot and captures what the bot says.
+type recordingChat struct {
+	onSay     func(channel, message string)
+	onMessage func(ports.ChatMessage)
+}
+
+func (c *recordingChat) deliver(msg ports.ChatMessage) {
+	if c.onMessage != nil {
+		c.onMessage(msg)
+	}
+}
+
+func (c *recordingChat) Say(_ context.Context, channel, message string) error {
+	c.onSay(channel, message)
+	return nil
+}
+
+func (c *recordingChat) Connect(_ context.Context) error { return nil }
+
+func (c *recordingChat) Disconnect() error { return nil }
+
+func (c *recordingChat) Join(_ string) {}
+
+func (c *recordingChat) OnMessage(handler func(ports.ChatMessage)) {
+	c.onMessage = handler
+}
+
+func (c *recordingChat) OnConnect(_ func()) {}
+
+func (c *recordingChat) OnBan(_ func(ports.BanEvent)) {}
+
+func (c *recordingChat) OnReconnect(_ func() bool) {}
+
+func (c *recordingChat) OnNotice(_ func(channel, message string)) {}
@@ -0,0 +1,89 @@
+package replay
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"streamgogambler/internal/adapters/storage"
+	"streamgogambler/internal/domain/autoresponse"
+	"streamgogambler/internal/ports"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+func testBotConfig() ports.BotConfig {
+	return ports.BotConfig{
+		Username:      "testuser",
+		Channel:       "chan",
+		Prefix:        "!",
+		StatusCommand: "status",
+		BossBotName:   "bossbot",
+		DefaultHeist:  1000,
+		SlotsCost:     2000,
+		ArenaCost:     1000,
+		PointsAsDelta: true,
+	}
+}
+
+var testAutoResponses = []autoresponse.Rule{
+	{Trigger: "Type !ffa to start!", Response: "!ffa"},
+	{Trigger: "The cops have given up! If you want to get a team together type !heist", Response: "!heist"},
+}
+
+func TestReplayGolden(t *testing.T) {
+	t.Parallel()
+
+	transcripts, err := filepath.Glob(filepath.Join("testdata", "*.jsonl"))
+	require.NoError(t, err, "listing transcripts")
+	require.NotEmpty(t, transcripts, "golden transcripts")
+
+	for _, path := range transcripts {
+		name := strings.TrimSuffix(filepath.Base(path), ".jsonl")
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			entries, err := storage.NewTranscriptStore(path).Load()
+			require.NoError(t, err, "loading %s", path)
+
+			result := Run(context.Background(), entries, Options{Bot: testBotConfig(), AutoResponses: testAutoResponses})
+
+			var out strings.Builder
+			require.NoError(t, result.Write(&out), "Write()")
+
+			golden := filepath.Join("testdata", name+".golden")
+			if *update {
+				require.NoError(t, os.WriteFile(golden, []byte(out.String()), 0600), "updating %s", golden)
+			}
+			want, err := os.ReadFile(golden)
+			require.NoError(t, err, "reading %s (run with -update to create it)", golden)
+			assert.Equal(t, string(want), out.String(), "replay of %s", path)
+		})
+	}
+}
+
+func TestReplayTracksSentMessagesAndBalance(t *testing.T) {
+	t.Parallel()
+
+	entries, err := storage.NewTranscriptStore(filepath.Join("testdata", "session.jsonl")).Load()
+	require.NoError(t, err, "loading transcript")
+
+	result := Run(context.Background(), entries, Options{Bot: testBotConfig(), AutoResponses: testAutoResponses})
+
+	assert.Equal(t, len(entries), result.Received, "every message replayed")
+	assert.Equal(t, 43400, result.Final, "final balance follows the last bombs reply")
+
+	var sent []string
+	for _, e := range result.Events {
+		if e.Kind == KindSent {
+			sent = append(sent, e.Text)
+		}
+	}
+	assert.Equal(t, []string{"!heist 1000", "!ffa", "!heist 1000", "!ffa"}, sent, "messages the bot would have sent")
+}
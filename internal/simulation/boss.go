@@ -0,0 +1,328 @@
+package simulation
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+
+	"streamgogambler/internal/domain/parsing"
+	"streamgogambler/internal/domain/wallet"
+	"streamgogambler/internal/ports"
+)
+
+const (
+	// ReplyDelay is how long the simulated boss bot takes to answer a command.
+	ReplyDelay = 1 * time.Second
+	// GameDuration is how long heist and arena sign-ups stay open.
+	GameDuration = 2 * time.Minute
+
+	otherPlayer = "someone"
+)
+
+// GameStats are the stakes the boss bot accepted for a game and what it paid back.
+type GameStats struct {
+	Played   int
+	Staked   int
+	Returned int
+}
+
+func (g GameStats) Net() int {
+	return g.Returned - g.Staked
+}
+
+// Boss is an in-memory ports.ChatClient that plays the boss bot on a virtual
+// clock. It keeps the authoritative balance, answers the commands the bot
+// sends and announces heists and arenas using the default dialect phrases.
+type Boss struct {
+	name      string
+	username  string
+	channel   string
+	slotsCost int
+	arenaCost int
+	odds      Odds
+	rng       *rand.Rand
+
+	now    time.Time
+	events eventQueue
+	seq    int
+
+	balance    int
+	minBalance int
+	games      map[wallet.Game]*GameStats
+
+	heistOpen  bool
+	heistStake int
+	arenaOpen  bool
+	arenaStake int
+
+	onMessage func(ports.ChatMessage)
+}
+
+func NewBoss(cfg ports.BotConfig, odds Odds, balance int, rng *rand.Rand, start time.Time) *Boss {
+	return &Boss{
+		name:       cfg.BossBotName,
+		username:   cfg.Username,
+		channel:    cfg.Channel,
+		slotsCost:  cfg.SlotsCost,
+		arenaCost:  cfg.ArenaCost,
+		odds:       odds,
+		rng:        rng,
+		now:        start,
+		balance:    balance,
+		minBalance: balance,
+		games: map[wallet.Game]*GameStats{
+			wallet.GameSlots: {},
+			wallet.GameHeist: {},
+			wallet.GameFFA:   {},
+		},
+	}
+}
+
+func (b *Boss) Now() time.Time {
+	return b.now
+}
+
+func (b *Boss) Balance() int {
+	return b.balance
+}
+
+func (b *Boss) MinBalance() int {
+	return b.minBalance
+}
+
+func (b *Boss) Games() map[wallet.Game]GameStats {
+	out := make(map[wallet.Game]GameStats, len(b.games))
+	for game, stats := range b.games {
+		out[game] = *stats
+	}
+	return out
+}
+
+// After schedules fn on the virtual clock.
+func (b *Boss) After(d time.Duration, fn func()) {
+	b.seq++
+	heap.Push(&b.events, event{at: b.now.Add(d), seq: b.seq, fn: fn})
+}
+
+// Every schedules fn every interval, starting one interval from now.
+func (b *Boss) Every(interval time.Duration, fn func()) {
+	if interval <= 0 {
+		return
+	}
+	var tick func()
+	tick = func() {
+		fn()
+		b.After(interval, tick)
+	}
+	b.After(interval, tick)
+}
+
+// Run processes scheduled events in order until d of virtual time has passed.
+func (b *Boss) Run(d time.Duration) {
+	end := b.now.Add(d)
+	for b.events.Len() > 0 {
+		next := b.events[0]
+		if next.at.After(end) {
+			break
+		}
+		heap.Pop(&b.events)
+		b.now = next.at
+		next.fn()
+	}
+	b.now = end
+}
+
+// AnnounceHeist opens heist sign-ups and resolves the heist after GameDuration.
+func (b *Boss) AnnounceHeist() {
+	if b.heistOpen {
+		return
+	}
+	b.heistOpen = true
+	b.post("The cops have given up! If you want to get a team together type !heist")
+	b.After(GameDuration, b.resolveHeist)
+}
+
+// AnnounceArena opens a free-for-all and resolves it after GameDuration.
+func (b *Boss) AnnounceArena() {
+	if b.arenaOpen {
+		return
+	}
+	b.arenaOpen = true
+	b.post("Type !ffa to start!")
+	b.After(GameDuration, b.resolveArena)
+}
+
+func (b *Boss) Say(_ context.Context, _ string, message string) error {
+	parts := strings.Fields(strings.ToLower(message))
+	if len(parts) == 0 {
+		return nil
+	}
+
+	switch parts[0] {
+	case "!bombs":
+		b.reply(fmt.Sprintf("%s bombs: %d", b.username, b.balance))
+	case "!slots":
+		b.playSlots()
+	case "!heist":
+		if !b.heistOpen || b.heistStake > 0 || len(parts) < 2 {
+			return nil
+		}
+		amount, err := strconv.Atoi(parts[1])
+		if err != nil || amount <= 0 {
+			return nil
+		}
+		if b.take(wallet.GameHeist, amount) {
+			b.heistStake = amount
+		}
+	case "!ffa":
+		if !b.arenaOpen || b.arenaStake > 0 {
+			return nil
+		}
+		if b.take(wallet.GameFFA, b.arenaCost) {
+			b.arenaStake = b.arenaCost
+		}
+	}
+	return nil
+}
+
+func (b *Boss) Connect(_ context.Context) error { return nil }
+
+func (b *Boss) Disconnect() error { return nil }
+
+func (b *Boss) Join(_ string) {}
+
+func (b *Boss) OnMessage(handler func(ports.ChatMessage)) {
+	b.onMessage = handler
+}
+
+func (b *Boss) OnConnect(_ func()) {}
+
+func (b *Boss) OnBan(_ func(ports.BanEvent)) {}
+
+func (b *Boss) OnReconnect(_ func() bool) {}
+
+func (b *Boss) OnNotice(_ func(channel, message string)) {}
+
+func (b *Boss) playSlots() {
+	if !b.take(wallet.GameSlots, b.slotsCost) {
+		return
+	}
+
+	outcome := b.odds.drawSlots(b.rng.Float64())
+	payout := b.odds.SlotsPayouts.Payout(outcome, b.slotsCost)
+	text := fmt.Sprintf("%s pulls the lever and waits for the roll... %s %s", b.username, b.username, slotsPhrase(outcome))
+
+	b.After(ReplyDelay, func() {
+		b.pay(wallet.GameSlots, payout)
+		b.post(text)
+	})
+}
+
+func (b *Boss) resolveHeist() {
+	stake := b.heistStake
+	b.heistOpen, b.heistStake = false, 0
+
+	if stake > 0 && b.rng.Float64() < b.odds.HeistWinChance {
+		payout := int(math.Floor(float64(stake) * b.odds.HeistMultiplier))
+		b.pay(wallet.GameHeist, payout)
+		b.post(fmt.Sprintf("Results from the Heist: %s (%d), %s (%d)", b.username, payout, otherPlayer, 1000))
+		return
+	}
+	b.post(fmt.Sprintf("Results from the Heist: %s (%d)", otherPlayer, 1000))
+}
+
+func (b *Boss) resolveArena() {
+	stake := b.arenaStake
+	b.arenaOpen, b.arenaStake = false, 0
+
+	if stake > 0 && b.rng.Float64() < b.odds.ArenaWinChance {
+		payout := int(math.Floor(float64(stake) * b.odds.ArenaMultiplier))
+		b.pay(wallet.GameFFA, payout)
+		b.post(fmt.Sprintf("The dust finally settled, %s (%d)", b.username, payout))
+		return
+	}
+	b.post(fmt.Sprintf("The dust finally settled, %s (%d)", otherPlayer, 1000))
+}
+
+// take charges a stake, or replies that the balance is too low.
+func (b *Boss) take(game wallet.Game, amount int) bool {
+	if amount > b.balance {
+		b.reply(fmt.Sprintf("%s doesn't have enough bombs", b.username))
+		return false
+	}
+	b.balance -= amount
+	b.minBalance = min(b.minBalance, b.balance)
+	stats := b.games[game]
+	stats.Played++
+	stats.Staked += amount
+	return true
+}
+
+func (b *Boss) pay(game wallet.Game, amount int) {
+	b.balance += amount
+	b.games[game].Returned += amount
+}
+
+func (b *Boss) reply(text string) {
+	b.After(ReplyDelay, func() { b.post(text) })
+}
+
+func (b *Boss) post(text string) {
+	if b.onMessage == nil {
+		return
+	}
+	b.onMessage(ports.ChatMessage{UserName: b.name, Channel: b.channel, Text: text})
+}
+
+func slotsPhrase(outcome parsing.SlotsOutcome) string {
+	switch outcome {
+	case parsing.OutcomeSuperJackpot:
+		return "hit the super jackpot!"
+	case parsing.OutcomeJackpot:
+		return "hit the jackpot!"
+	case parsing.OutcomeSmallWin:
+		return "even a small win is a win.."
+	case parsing.OutcomeRefund:
+		return "you at least got your points back"
+	default:
+		return "you lost"
+	}
+}
+
+type event struct {
+	at  time.Time
+	seq int
+	fn  func()
+}
+
+type eventQueue []event
+
+func (q eventQueue) Len() int { return len(q) }
+
+func (q eventQueue) Less(i, j int) bool {
+	if q[i].at.Equal(q[j].at) {
+		return q[i].seq < q[j].seq
+	}
+	return q[i].at.Before(q[j].at)
+}
+
+func (q eventQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *eventQueue) Push(x any) {
+	if e, ok := x.(event); ok {
+		*q = append(*q, e)
+	}
+}
+
+func (q *eventQueue) Pop() any {
+	old := *q
+	n := len(old)
+	e := old[n-1]
+	*q = old[:n-1]
+	return e
+}
@@ -0,0 +1,182 @@
+package simulation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"streamgogambler/internal/domain/parsing"
+)
+
+var ErrInvalidOdds = errors.New("invalid odds")
+
+// Odds is the probability table the simulated boss bot draws outcomes from.
+type Odds struct {
+	// Slots maps each outcome to its probability; they must sum to 1.
+	Slots map[parsing.SlotsOutcome]float64
+	// SlotsPayouts are the multipliers of the slots cost the boss bot pays.
+	SlotsPayouts parsing.PayoutTable
+
+	HeistWinChance  float64
+	HeistMultiplier float64
+	HeistEvery      time.Duration
+
+	ArenaWinChance  float64
+	ArenaMultiplier float64
+	ArenaEvery      time.Duration
+}
+
+func DefaultOdds() Odds {
+	return Odds{
+		Slots: map[parsing.SlotsOutcome]float64{
+			parsing.OutcomeLost:         0.6,
+			parsing.OutcomeRefund:       0.2,
+			parsing.OutcomeSmallWin:     0.15,
+			parsing.OutcomeJackpot:      0.045,
+			parsing.OutcomeSuperJackpot: 0.005,
+		},
+		SlotsPayouts:    parsing.DefaultPayoutTable(),
+		HeistWinChance:  0.45,
+		HeistMultiplier: 2,
+		HeistEvery:      30 * time.Minute,
+		ArenaWinChance:  0.2,
+		ArenaMultiplier: 4.5,
+		ArenaEvery:      time.Hour,
+	}
+}
+
+// LoadOdds reads a YAML or JSON file on top of DefaultOdds, so the file only
+// needs the values it changes. Durations are written as "30m", "1h".
+func LoadOdds(path string) (Odds, error) {
+	// #nosec G304 -- the odds file is chosen by the user on the command line
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Odds{}, fmt.Errorf("reading odds %s: %w", path, err)
+	}
+
+	var file oddsFile
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &file)
+	default:
+		err = yaml.Unmarshal(data, &file)
+	}
+	if err != nil {
+		return Odds{}, fmt.Errorf("parsing odds %s: %w", path, err)
+	}
+
+	odds, err := file.apply(DefaultOdds())
+	if err != nil {
+		return Odds{}, err
+	}
+	return odds, odds.Validate()
+}
+
+func (o Odds) Validate() error {
+	var problems []string
+
+	total := 0.0
+	for outcome, p := range o.Slots {
+		if !outcome.Valid() {
+			problems = append(problems, fmt.Sprintf("slots: unknown outcome %q", outcome))
+		}
+		if p < 0 {
+			problems = append(problems, fmt.Sprintf("slots: negative probability for %s", outcome))
+		}
+		total += p
+	}
+	if math.Abs(total-1) > 1e-6 {
+		problems = append(problems, fmt.Sprintf("slots probabilities sum to %g, want 1", total))
+	}
+	if o.HeistWinChance < 0 || o.HeistWinChance > 1 {
+		problems = append(problems, "heist_win_chance must be between 0 and 1")
+	}
+	if o.ArenaWinChance < 0 || o.ArenaWinChance > 1 {
+		problems = append(problems, "arena_win_chance must be between 0 and 1")
+	}
+	if o.HeistMultiplier < 0 || o.ArenaMultiplier < 0 {
+		problems = append(problems, "multipliers must not be negative")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidOdds, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// drawSlots picks a slots outcome for a uniform value u in [0, 1).
+func (o Odds) drawSlots(u float64) parsing.SlotsOutcome {
+	outcomes := make([]parsing.SlotsOutcome, 0, len(o.Slots))
+	for outcome := range o.Slots {
+		outcomes = append(outcomes, outcome)
+	}
+	sort.Slice(outcomes, func(i, j int) bool { return outcomes[i] < outcomes[j] })
+
+	for _, outcome := range outcomes {
+		u -= o.Slots[outcome]
+		if u < 0 {
+			return outcome
+		}
+	}
+	return parsing.OutcomeLost
+}
+
+type oddsFile struct {
+	Slots           map[parsing.SlotsOutcome]float64 `json:"slots" yaml:"slots"`
+	SlotsPayouts    map[parsing.SlotsOutcome]float64 `json:"slots_payouts" yaml:"slots_payouts"`
+	HeistWinChance  *float64                         `json:"heist_win_chance" yaml:"heist_win_chance"`
+	HeistMultiplier *float64                         `json:"heist_multiplier" yaml:"heist_multiplier"`
+	HeistEvery      string                           `json:"heist_every" yaml:"heist_every"`
+	ArenaWinChance  *float64                         `json:"arena_win_chance" yaml:"arena_win_chance"`
+	ArenaMultiplier *float64                         `json:"arena_multiplier" yaml:"arena_multiplier"`
+	ArenaEvery      string                           `json:"arena_every" yaml:"arena_every"`
+}
+
+func (f oddsFile) apply(o Odds) (Odds, error) {
+	if len(f.Slots) > 0 {
+		o.Slots = f.Slots
+	}
+	for outcome, mult := range f.SlotsPayouts {
+		o.SlotsPayouts[outcome] = mult
+	}
+	if f.HeistWinChance != nil {
+		o.HeistWinChance = *f.HeistWinChance
+	}
+	if f.HeistMultiplier != nil {
+		o.HeistMultiplier = *f.HeistMultiplier
+	}
+	if f.ArenaWinChance != nil {
+		o.ArenaWinChance = *f.ArenaWinChance
+	}
+	if f.ArenaMultiplier != nil {
+		o.ArenaMultiplier = *f.ArenaMultiplier
+	}
+
+	var err error
+	if o.HeistEvery, err = parseEvery("heist_every", f.HeistEvery, o.HeistEvery); err != nil {
+		return o, err
+	}
+	if o.ArenaEvery, err = parseEvery("arena_every", f.ArenaEvery, o.ArenaEvery); err != nil {
+		return o, err
+	}
+	return o, nil
+}
+
+func parseEvery(field, value string, def time.Duration) (time.Duration, error) {
+	if value == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("%w: %s must be a duration like 30m, got %q", ErrInvalidOdds, field, value)
+	}
+	return d, nil
+}
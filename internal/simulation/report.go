@@ -0,0 +1,170 @@
+package simulation
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"streamgogambler/internal/domain/gambling"
+	"streamgogambler/internal/domain/wallet"
+)
+
+// Distribution summarizes final balances across runs.
+type Distribution struct {
+	Mean   float64
+	Min    int
+	P5     int
+	P25    int
+	Median int
+	P75    int
+	P95    int
+	Max    int
+}
+
+type Report struct {
+	Runs         int
+	Duration     time.Duration
+	StartBalance int
+
+	Final Distribution
+
+	// RuinThreshold is the cheapest stake; a run whose final balance is below
+	// it can no longer play.
+	RuinThreshold   int
+	RuinProbability float64
+
+	// Games holds the totals across all runs.
+	Games map[wallet.Game]GameStats
+
+	GuardrailTrips map[string]int
+	DriftRuns      int
+}
+
+func NewReport(cfg Config, results []RunResult) Report {
+	r := Report{
+		Runs:           len(results),
+		Duration:       cfg.Duration,
+		StartBalance:   cfg.StartBalance,
+		RuinThreshold:  ruinThreshold(cfg),
+		Games:          make(map[wallet.Game]GameStats),
+		GuardrailTrips: make(map[string]int),
+	}
+	if len(results) == 0 {
+		return r
+	}
+
+	finals := make([]int, len(results))
+	ruined := 0
+	sum := 0
+	for i, res := range results {
+		finals[i] = res.Final
+		sum += res.Final
+		if res.Final < r.RuinThreshold {
+			ruined++
+		}
+		if res.Drift != 0 {
+			r.DriftRuns++
+		}
+		if res.Guardrail != "" && res.Guardrail != gambling.GuardrailOK {
+			r.GuardrailTrips[res.Guardrail]++
+		}
+		for game, stats := range res.Games {
+			total := r.Games[game]
+			total.Played += stats.Played
+			total.Staked += stats.Staked
+			total.Returned += stats.Returned
+			r.Games[game] = total
+		}
+	}
+
+	sort.Ints(finals)
+	r.Final = Distribution{
+		Mean:   float64(sum) / float64(len(finals)),
+		Min:    finals[0],
+		P5:     percentile(finals, 5),
+		P25:    percentile(finals, 25),
+		Median: percentile(finals, 50),
+		P75:    percentile(finals, 75),
+		P95:    percentile(finals, 95),
+		Max:    finals[len(finals)-1],
+	}
+	r.RuinProbability = float64(ruined) / float64(len(results))
+	return r
+}
+
+func (r Report) Write(w io.Writer) error {
+	var table strings.Builder
+	fmt.Fprintln(&table, "Final balance\tmean\tmin\tp5\tp25\tmedian\tp75\tp95\tmax\t")
+	d := r.Final
+	fmt.Fprintf(&table, "\t%.0f\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t\n\n", d.Mean, d.Min, d.P5, d.P25, d.Median, d.P75, d.P95, d.Max)
+
+	fmt.Fprintln(&table, "Game\tplayed/run\tstaked/run\treturned/run\tP&L/run\treturn %\t")
+	for _, game := range []wallet.Game{wallet.GameSlots, wallet.GameHeist, wallet.GameFFA} {
+		g := r.Games[game]
+		rtp := 0.0
+		if g.Staked > 0 {
+			rtp = 100 * float64(g.Returned) / float64(g.Staked)
+		}
+		fmt.Fprintf(&table, "%s\t%.1f\t%.0f\t%.0f\t%+.0f\t%.1f\t\n",
+			game, r.perRun(g.Played), r.perRun(g.Staked), r.perRun(g.Returned), r.perRun(g.Net()), rtp)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Simulated %d runs of %s starting at %d bombs\n\n", r.Runs, r.Duration, r.StartBalance)
+
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', tabwriter.AlignRight)
+	if _, err := io.WriteString(tw, table.String()); err != nil {
+		return err
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(&b, "\nRuin probability: %.2f%% (final balance below %d)\n", 100*r.RuinProbability, r.RuinThreshold)
+	for _, state := range []string{gambling.GuardrailStopLoss, gambling.GuardrailTakeProfit} {
+		if n := r.GuardrailTrips[state]; n > 0 {
+			fmt.Fprintf(&b, "Guardrail %s tripped in %d runs\n", state, n)
+		}
+	}
+	if r.DriftRuns > 0 {
+		fmt.Fprintf(&b, "WARNING: tracked balance drifted from the boss bot in %d runs\n", r.DriftRuns)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (r Report) perRun(total int) float64 {
+	if r.Runs == 0 {
+		return 0
+	}
+	return float64(total) / float64(r.Runs)
+}
+
+// percentile uses the nearest-rank method on sorted values.
+func percentile(sorted []int, p float64) int {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	rank = max(rank, 1)
+	rank = min(rank, len(sorted))
+	return sorted[rank-1]
+}
+
+func ruinThreshold(cfg Config) int {
+	threshold := math.MaxInt
+	for _, cost := range []int{cfg.Bot.SlotsCost, cfg.Bot.ArenaCost} {
+		if cost > 0 && cost < threshold {
+			threshold = cost
+		}
+	}
+	if threshold == math.MaxInt {
+		return 1
+	}
+	return threshold
+}
@@ -0,0 +1,118 @@
+package simulation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"streamgogambler/internal/adapters/config"
+	"streamgogambler/internal/adapters/logging"
+	"streamgogambler/internal/application"
+	"streamgogambler/internal/domain/autoresponse"
+	"streamgogambler/internal/domain/parsing"
+	"streamgogambler/internal/domain/wallet"
+	"streamgogambler/internal/ports"
+)
+
+const (
+	DefaultRuns         = 1000
+	DefaultDuration     = 24 * time.Hour
+	DefaultStartBalance = 50000
+
+	// slotsTickSlack keeps auto slots ticks just past the slots interval, as
+	// the real loop's reconnect delay does.
+	slotsTickSlack = 5 * time.Second
+	expireEvery    = time.Minute
+)
+
+var simulationStart = time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Config describes a Monte Carlo simulation. Bot is used as the bot's
+// configuration in every run.
+type Config struct {
+	Bot           ports.BotConfig
+	AutoResponses []autoresponse.Rule
+	Odds          Odds
+	Runs          int
+	Duration      time.Duration
+	StartBalance  int
+	Seed          uint64
+}
+
+// RunResult is the outcome of a single simulated session.
+type RunResult struct {
+	Final      int
+	Lowest     int
+	Drift      int
+	Guardrail  string
+	Games      map[wallet.Game]GameStats
+	BotBalance int
+}
+
+// Run plays cfg.Runs independent sessions of cfg.Duration against the
+// simulated boss bot. Each session drives a real BotService through the chat
+// port, so the message handler, parsers, escrow, strategy and guardrails are
+// the ones used live.
+func Run(ctx context.Context, cfg Config) (Report, error) {
+	if cfg.Runs <= 0 {
+		return Report{}, errors.New("runs must be positive")
+	}
+	if cfg.Duration <= 0 {
+		return Report{}, errors.New("duration must be positive")
+	}
+	if err := cfg.Odds.Validate(); err != nil {
+		return Report{}, err
+	}
+
+	results := make([]RunResult, 0, cfg.Runs)
+	for i := 0; i < cfg.Runs; i++ {
+		if err := ctx.Err(); err != nil {
+			return Report{}, fmt.Errorf("simulation interrupted after %d runs: %w", i, err)
+		}
+		results = append(results, RunOnce(ctx, cfg, cfg.Seed+uint64(i)))
+	}
+	return NewReport(cfg, results), nil
+}
+
+// RunOnce plays a single session with its own random seed.
+func RunOnce(ctx context.Context, cfg Config, seed uint64) RunResult {
+	rng := rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))
+	boss := NewBoss(cfg.Bot, cfg.Odds, cfg.StartBalance, rng, simulationStart)
+
+	logger := logging.New(logging.LevelError)
+	logger.SetCallback(func(string) {})
+
+	bot := application.NewBotService(config.NewStaticStore(cfg.Bot), boss, logger, nil,
+		application.WithDialect(parsing.DefaultDialect()),
+		application.WithClock(boss.Now),
+		application.WithAutoResponses(cfg.AutoResponses, nil),
+		application.WithOfflineReplay(),
+	)
+	bot.Attach(ctx)
+	defer bot.Stop()
+
+	balanceCommand := bot.Dialect().Balance.Command
+	boss.After(0, func() { bot.ExecuteCommand(balanceCommand) })
+	boss.Every(time.Duration(cfg.Bot.BalanceSyncInterval)*time.Minute, func() { bot.ExecuteCommand(balanceCommand) })
+
+	if cfg.Bot.AutoSlotsInterval > 0 {
+		boss.Every(time.Duration(cfg.Bot.AutoSlotsInterval)*time.Minute+slotsTickSlack, bot.PlayAutoSlots)
+	}
+	boss.Every(cfg.Odds.HeistEvery, boss.AnnounceHeist)
+	boss.Every(cfg.Odds.ArenaEvery, boss.AnnounceArena)
+	boss.Every(expireEvery, func() { bot.Escrow().Expire() })
+
+	boss.Run(cfg.Duration)
+
+	botBalance := bot.Wallet().GetBalance()
+	return RunResult{
+		Final:      boss.Balance(),
+		Lowest:     boss.MinBalance(),
+		Drift:      botBalance - boss.Balance(),
+		Guardrail:  bot.GuardrailState(),
+		Games:      boss.Games(),
+		BotBalance: botBalance,
+	}
+}
@@ -0,0 +1,159 @@
+package simulation
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"streamgogambler/internal/domain/autoresponse"
+	"streamgogambler/internal/domain/parsing"
+	"streamgogambler/internal/domain/wallet"
+	"streamgogambler/internal/ports"
+)
+
+func testBotConfig() ports.BotConfig {
+	return ports.BotConfig{
+		Username:          "simbot",
+		Channel:           "simulation",
+		Prefix:            "!",
+		StatusCommand:     "status",
+		BossBotName:       "bossbot",
+		DefaultHeist:      1000,
+		SlotsCost:         2000,
+		ArenaCost:         1000,
+		AutoSlotsEnabled:  true,
+		AutoSlotsInterval: 15,
+		PointsAsDelta:     true,
+
+		BalanceSyncInterval: 30,
+	}
+}
+
+func testConfig(runs int) Config {
+	return Config{
+		Bot: testBotConfig(),
+		AutoResponses: []autoresponse.Rule{
+			{Trigger: "Type !ffa to start!", Response: "!ffa"},
+			{Trigger: "The cops have given up! If you want to get a team together type !heist", Response: "!heist"},
+		},
+		Odds:         DefaultOdds(),
+		Runs:         runs,
+		Duration:     12 * time.Hour,
+		StartBalance: DefaultStartBalance,
+		Seed:         42,
+	}
+}
+
+func TestRunOncePlaysEveryGameWithoutDrift(t *testing.T) {
+	t.Parallel()
+
+	cfg := testConfig(1)
+	cfg.StartBalance = 10_000_000
+
+	for seed := uint64(1); seed <= 20; seed++ {
+		res := RunOnce(context.Background(), cfg, seed)
+
+		assert.Zero(t, res.Drift, "seed %d: bot balance %d, boss balance %d", seed, res.BotBalance, res.Final)
+		assert.Equal(t, 47, res.Games[wallet.GameSlots].Played, "seed %d: slots every 15 minutes and 5 seconds", seed)
+		assert.Equal(t, 24, res.Games[wallet.GameHeist].Played, "seed %d: heist every 30 minutes", seed)
+		assert.Equal(t, 12, res.Games[wallet.GameFFA].Played, "seed %d: ffa every hour", seed)
+	}
+}
+
+func TestRunIsDeterministic(t *testing.T) {
+	t.Parallel()
+
+	cfg := testConfig(50)
+
+	first, err := Run(context.Background(), cfg)
+	require.NoError(t, err, "Run()")
+	second, err := Run(context.Background(), cfg)
+	require.NoError(t, err, "Run()")
+
+	assert.Equal(t, first, second, "same seed gives the same report")
+	assert.Equal(t, 50, first.Runs, "runs")
+	assert.LessOrEqual(t, first.Final.Min, first.Final.Median, "min <= median")
+	assert.LessOrEqual(t, first.Final.Median, first.Final.Max, "median <= max")
+	assert.Zero(t, first.DriftRuns, "no drift")
+}
+
+func TestRunRuinsWithLosingOdds(t *testing.T) {
+	t.Parallel()
+
+	cfg := testConfig(10)
+	cfg.StartBalance = 20000
+	cfg.Odds.Slots = map[parsing.SlotsOutcome]float64{parsing.OutcomeLost: 1}
+	cfg.Odds.HeistWinChance = 0
+	cfg.Odds.ArenaWinChance = 0
+
+	report, err := Run(context.Background(), cfg)
+	require.NoError(t, err, "Run()")
+
+	assert.InDelta(t, 1.0, report.RuinProbability, 1e-9, "every run is ruined")
+	assert.Zero(t, report.Games[wallet.GameSlots].Returned, "nothing returned")
+	assert.Zero(t, report.DriftRuns, "refused bets are refunded")
+
+	var out strings.Builder
+	require.NoError(t, report.Write(&out), "Write()")
+	assert.Contains(t, out.String(), "Ruin probability: 100.00%", "report output")
+}
+
+func TestRunRejectsInvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	cfg := testConfig(0)
+	_, err := Run(context.Background(), cfg)
+	require.Error(t, err, "zero runs")
+
+	cfg = testConfig(1)
+	cfg.Odds.Slots = map[parsing.SlotsOutcome]float64{parsing.OutcomeLost: 0.5}
+	_, err = Run(context.Background(), cfg)
+	require.ErrorIs(t, err, ErrInvalidOdds, "probabilities not summing to 1")
+}
+
+func TestLoadOdds(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "odds.yaml")
+	content := `slots:
+  lost: 0.5
+  refund: 0.5
+slots_payouts:
+  refund: 1.5
+heist_win_chance: 0.3
+heist_every: 10m
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600), "writing odds")
+
+	odds, err := LoadOdds(path)
+	require.NoError(t, err, "LoadOdds()")
+
+	assert.InDelta(t, 0.5, odds.Slots[parsing.OutcomeRefund], 1e-9, "slots probability")
+	assert.InDelta(t, 1.5, odds.SlotsPayouts[parsing.OutcomeRefund], 1e-9, "payout override")
+	assert.InDelta(t, 30.0, odds.SlotsPayouts[parsing.OutcomeSuperJackpot], 1e-9, "payout default kept")
+	assert.InDelta(t, 0.3, odds.HeistWinChance, 1e-9, "heist chance")
+	assert.Equal(t, 10*time.Minute, odds.HeistEvery, "heist interval")
+	assert.Equal(t, time.Hour, odds.ArenaEvery, "arena interval default kept")
+
+	bad := filepath.Join(t.TempDir(), "bad.yaml")
+	require.NoError(t, os.WriteFile(bad, []byte("heist_every: soon\n"), 0600), "writing odds")
+	_, err = LoadOdds(bad)
+	require.ErrorIs(t, err, ErrInvalidOdds, "bad duration")
+}
+
+func TestPercentile(t *testing.T) {
+	t.Parallel()
+
+	values := []int{10, 20, 30, 40, 50, 60, 70, 80, 90, 100}
+
+	assert.Equal(t, 10, percentile(values, 5), "p5")
+	assert.Equal(t, 50, percentile(values, 50), "median")
+	assert.Equal(t, 100, percentile(values, 95), "p95")
+	assert.Equal(t, 0, percentile(nil, 50), "empty")
+}